@@ -8,6 +8,7 @@ import (
 
 	"github.com/honmaple/snow/internal/core"
 	"github.com/honmaple/snow/internal/site"
+	"github.com/honmaple/snow/internal/site/content/parser"
 	"github.com/honmaple/snow/internal/writer"
 	"github.com/urfave/cli/v2"
 )
@@ -62,6 +63,36 @@ var (
 				Usage: "build site with content marked as draft",
 				Value: false,
 			},
+			&cli.BoolFlag{
+				Name:  "include-future",
+				Usage: "build site with content whose publish_date is in the future",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "include-expired",
+				Usage: "build site with content whose expiry_date has passed",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "no-cache",
+				Usage: "parse all content without the build cache",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "strict",
+				Usage: "fail the build if any error occurs",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "strict-warnings",
+				Usage: "treat warnings as errors in strict mode",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "clear-cache",
+				Usage: "clear the build cache before building",
+				Value: false,
+			},
 		},
 		Action: buildAction,
 	}
@@ -76,6 +107,12 @@ func buildAction(clx *cli.Context) error {
 		if out := clx.String("output-dir"); out != "" {
 			conf.Set("output_dir", out)
 		}
+		if clx.Bool("strict-warnings") {
+			conf.Set("strict", true)
+			conf.Set("strict_warnings", true)
+		} else if clx.Bool("strict") {
+			conf.Set("strict", true)
+		}
 
 		ctx, err := core.NewContext(conf)
 		if err != nil {
@@ -99,7 +136,20 @@ func buildAction(clx *cli.Context) error {
 			}
 		}
 
-		s, err := site.New(ctx, site.IncludeDrafts(clx.Bool("include-drafts")))
+		if dir := conf.GetString("cache_dir"); dir != "" && clx.Bool("clear-cache") {
+			ctx.Logger.Infoln("Removing the build cache", dir)
+
+			if err := parser.ClearCache(dir); err != nil {
+				return err
+			}
+		}
+
+		s, err := site.New(ctx,
+			site.IncludeDrafts(clx.Bool("include-drafts")),
+			site.IncludeFuture(clx.Bool("include-future")),
+			site.IncludeExpired(clx.Bool("include-expired")),
+			site.UseCache(!clx.Bool("no-cache")),
+		)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site"
+	"github.com/honmaple/snow/internal/writer"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	checkFlags = []cli.Flag{
+		&cli.PathFlag{
+			Name:    "config",
+			Aliases: []string{"c"},
+			Value:   "",
+			Usage:   "load configuration from `FILE`",
+		},
+		&cli.BoolFlag{
+			Name:    "debug",
+			Aliases: []string{"D"},
+			Value:   false,
+			Usage:   "enable debug mode",
+		},
+		&cli.StringFlag{
+			Name:    "root-dir",
+			Aliases: []string{"r"},
+			Value:   ".",
+			Usage:   "directory to use as root of project",
+		},
+		&cli.StringFlag{
+			Name:    "mode",
+			Aliases: []string{"m"},
+			Value:   "",
+			Usage:   "check site with special mode",
+		},
+		&cli.BoolFlag{
+			Name:  "include-drafts",
+			Usage: "check content marked as draft",
+			Value: false,
+		},
+		&cli.BoolFlag{
+			Name:  "include-future",
+			Usage: "check content whose publish_date is in the future",
+			Value: false,
+		},
+		&cli.BoolFlag{
+			Name:  "include-expired",
+			Usage: "check content whose expiry_date has passed",
+			Value: false,
+		},
+		&cli.BoolFlag{
+			Name:  "strict-warnings",
+			Usage: "treat warnings as errors",
+			Value: false,
+		},
+	}
+	checkCommand = &cli.Command{
+		Name:   "check",
+		Usage:  "Check content without rendering",
+		Flags:  checkFlags,
+		Action: checkAction,
+		Subcommands: []*cli.Command{
+			{
+				Name:   "links",
+				Usage:  "Build site in memory and check internal links and anchors",
+				Flags:  checkFlags,
+				Action: checkLinksAction,
+			},
+			{
+				Name:  "external",
+				Usage: "Build site in memory and check external links",
+				Flags: append([]cli.Flag{
+					&cli.StringFlag{
+						Name:    "format",
+						Aliases: []string{"f"},
+						Value:   "text",
+						Usage:   "report format, text or json",
+					},
+					&cli.PathFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Value:   "",
+						Usage:   "write report to `FILE`",
+					},
+				}, checkFlags...),
+				Action: checkExternalAction,
+			},
+		},
+	}
+)
+
+func newCheckSite(clx *cli.Context, strict bool) (*site.Site, *core.Context, error) {
+	conf, err := commonAction(clx)
+	if err != nil {
+		return nil, nil, err
+	}
+	conf.Set("strict", strict)
+	if clx.Bool("strict-warnings") {
+		conf.Set("strict_warnings", true)
+	}
+
+	ctx, err := core.NewContext(conf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s, err := site.New(ctx,
+		site.IncludeDrafts(clx.Bool("include-drafts")),
+		site.IncludeFuture(clx.Bool("include-future")),
+		site.IncludeExpired(clx.Bool("include-expired")),
+		site.UseCache(false),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, ctx, nil
+}
+
+func checkAction(clx *cli.Context) error {
+	return runInRootDir(clx.String("root-dir"), func() error {
+		s, ctx, err := newCheckSite(clx, true)
+		if err != nil {
+			return err
+		}
+		if err := s.CheckContent(); err != nil {
+			return err
+		}
+		ctx.Logger.Infoln("No problems found")
+		return nil
+	})
+}
+
+func checkLinksAction(clx *cli.Context) error {
+	return runInRootDir(clx.String("root-dir"), func() error {
+		s, ctx, err := newCheckSite(clx, true)
+		if err != nil {
+			return err
+		}
+		if err := s.Build(context.TODO(), writer.NewNullWriter()); err != nil {
+			return err
+		}
+		ctx.Logger.Infoln("No broken links found")
+		return nil
+	})
+}
+
+func checkExternalAction(clx *cli.Context) error {
+	return runInRootDir(clx.String("root-dir"), func() error {
+		s, ctx, err := newCheckSite(clx, false)
+		if err != nil {
+			return err
+		}
+		report, err := s.CheckExternal(context.TODO())
+		if err != nil {
+			return err
+		}
+
+		out := os.Stdout
+		if file := clx.String("output"); file != "" {
+			f, err := os.Create(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		switch format := clx.String("format"); format {
+		case "json":
+			err = report.WriteJSON(out)
+		case "text", "":
+			err = report.WriteText(out)
+		default:
+			err = fmt.Errorf("unknown report format: %s", format)
+		}
+		if err != nil {
+			return err
+		}
+
+		ctx.Logger.Infof("Checked %d external links (%d cached)", report.Total, report.Cached)
+		if len(report.Dead) > 0 {
+			return fmt.Errorf("found %d dead external links", len(report.Dead))
+		}
+		return nil
+	})
+}
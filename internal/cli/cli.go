@@ -7,7 +7,9 @@ import (
 	"github.com/honmaple/snow/internal/core"
 	"github.com/urfave/cli/v2"
 
+	_ "github.com/honmaple/snow/internal/site/content/parser/asciidoc"
 	_ "github.com/honmaple/snow/internal/site/content/parser/html"
+	_ "github.com/honmaple/snow/internal/site/content/parser/jupyter"
 	_ "github.com/honmaple/snow/internal/site/content/parser/markdown"
 	_ "github.com/honmaple/snow/internal/site/content/parser/niklasfasching"
 	_ "github.com/honmaple/snow/internal/site/content/parser/orgmode"
@@ -24,7 +26,9 @@ import (
 	_ "github.com/honmaple/snow/internal/site/hook/mount"
 	_ "github.com/honmaple/snow/internal/site/hook/pelican"
 	_ "github.com/honmaple/snow/internal/site/hook/rewrite"
+	_ "github.com/honmaple/snow/internal/site/hook/search"
 	_ "github.com/honmaple/snow/internal/site/hook/shortcode"
+	_ "github.com/honmaple/snow/internal/site/hook/sitemap"
 	_ "github.com/honmaple/snow/internal/site/hook/snakecase"
 )
 
@@ -56,11 +60,15 @@ func Execute() {
 		Commands: []*cli.Command{
 			initCommand,
 			buildCommand,
+			checkCommand,
+			graphCommand,
+			genCommand,
 			serverCommand,
 			hookCommand,
 		},
 	}
 	if err := app.Run(os.Args); err != nil {
 		fmt.Println(err.Error())
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/honmaple/snow/internal/site/content/parser"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	genCommand = &cli.Command{
+		Name:  "gen",
+		Usage: "Generate auxiliary files",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "chroma-css",
+				Usage: "Generate stylesheet for highlight_mode: classes",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "style",
+						Aliases: []string{"s"},
+						Value:   "monokai",
+						Usage:   "chroma style",
+					},
+					&cli.StringFlag{
+						Name:  "dark-style",
+						Value: "",
+						Usage: "chroma style used with prefers-color-scheme: dark",
+					},
+					&cli.PathFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Value:   "",
+						Usage:   "write stylesheet to `FILE`",
+					},
+				},
+				Action: genChromaCSSAction,
+			},
+		},
+	}
+)
+
+func genChromaCSSAction(clx *cli.Context) error {
+	css, err := parser.ChromaCSS(clx.String("style"), clx.String("dark-style"))
+	if err != nil {
+		return err
+	}
+
+	output := clx.String("output")
+	if output == "" {
+		fmt.Print(css)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(output, []byte(css), 0644)
+}
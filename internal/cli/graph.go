@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	graphCommand = &cli.Command{
+		Name:  "graph",
+		Usage: "Export the content link graph",
+		Flags: []cli.Flag{
+			&cli.PathFlag{
+				Name:    "config",
+				Aliases: []string{"c"},
+				Value:   "",
+				Usage:   "load configuration from `FILE`",
+			},
+			&cli.BoolFlag{
+				Name:    "debug",
+				Aliases: []string{"D"},
+				Value:   false,
+				Usage:   "enable debug mode",
+			},
+			&cli.StringFlag{
+				Name:    "root-dir",
+				Aliases: []string{"r"},
+				Value:   ".",
+				Usage:   "directory to use as root of project",
+			},
+			&cli.StringFlag{
+				Name:    "mode",
+				Aliases: []string{"m"},
+				Value:   "",
+				Usage:   "export graph with special mode",
+			},
+			&cli.BoolFlag{
+				Name:  "include-drafts",
+				Usage: "include content marked as draft",
+				Value: false,
+			},
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Value:   "json",
+				Usage:   "graph format, json or dot",
+			},
+			&cli.PathFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Value:   "",
+				Usage:   "write graph to `FILE`",
+			},
+		},
+		Action: graphAction,
+	}
+)
+
+func graphAction(clx *cli.Context) error {
+	return runInRootDir(clx.String("root-dir"), func() error {
+		conf, err := commonAction(clx)
+		if err != nil {
+			return err
+		}
+
+		ctx, err := core.NewContext(conf)
+		if err != nil {
+			return err
+		}
+
+		s, err := site.New(ctx,
+			site.IncludeDrafts(clx.Bool("include-drafts")),
+			site.UseCache(false),
+		)
+		if err != nil {
+			return err
+		}
+
+		g, err := s.Graph()
+		if err != nil {
+			return err
+		}
+
+		out := os.Stdout
+		if file := clx.String("output"); file != "" {
+			f, err := os.Create(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		switch format := clx.String("format"); format {
+		case "dot":
+			return g.WriteDOT(out)
+		case "json", "":
+			return g.WriteJSON(out)
+		default:
+			return fmt.Errorf("unknown graph format: %s", format)
+		}
+	})
+}
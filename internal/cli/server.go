@@ -2,6 +2,7 @@ package cli
 
 import (
 	"github.com/honmaple/snow/internal/server"
+	"github.com/honmaple/snow/internal/site"
 	"github.com/urfave/cli/v2"
 )
 
@@ -50,6 +51,21 @@ var (
 				Usage: "include content marked as draft",
 				Value: false,
 			},
+			&cli.BoolFlag{
+				Name:  "include-future",
+				Usage: "include content whose publish_date is in the future",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "include-expired",
+				Usage: "include content whose expiry_date has passed",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "no-cache",
+				Usage: "parse all content without the build cache",
+				Value: false,
+			},
 		},
 		Action: serverAction,
 	}
@@ -61,6 +77,11 @@ func serverAction(clx *cli.Context) error {
 		if err != nil {
 			return err
 		}
-		return server.Serve(conf, clx.String("listen"), clx.Bool("autoload"), clx.Bool("include-drafts"))
+		return server.Serve(conf, clx.String("listen"), clx.Bool("autoload"),
+			site.IncludeDrafts(clx.Bool("include-drafts")),
+			site.IncludeFuture(clx.Bool("include-future")),
+			site.IncludeExpired(clx.Bool("include-expired")),
+			site.UseCache(!clx.Bool("no-cache")),
+		)
 	})
 }
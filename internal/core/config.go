@@ -198,9 +198,19 @@ var (
 		"description":               "snow is a static site generator.",
 		"author":                    "honmaple",
 		"language":                  "en",
+		"translation_fallback":      "omit",
 		"output_dir":                "output",
+		"cache_dir":                 ".snow-cache",
+		"strict":                    false,
+		"strict_warnings":           false,
+		"output_collision":          "error",
+		"external_links.workers":    8,
+		"external_links.timeout":    "10s",
+		"external_links.cache_ttl":  "24h",
 		"content_truncate_len":      49,
 		"content_truncate_ellipsis": "...",
+		"front_matter.publish_date": []string{"publish_date"},
+		"front_matter.expiry_date":  []string{"expiry_date"},
 		"formats.rss.template":      "partials/rss.xml",
 		"formats.atom.template":     "partials/atom.xml",
 	}
@@ -244,6 +254,8 @@ var (
 		"hooks.shortcode.weight": 60,
 		"hooks.minify.weight":    70,
 		"hooks.alias.weight":     80,
+		"hooks.sitemap.weight":   90,
+		"hooks.search.weight":    90,
 	}
 )
 
@@ -16,6 +16,7 @@ type (
 	Context struct {
 		*LocaleContext
 		Logger         Logger
+		Reporter       *Reporter
 		FS             VirtualFS
 		OtherLanguages map[string]*LocaleContext
 	}
@@ -96,6 +97,7 @@ func NewContext(conf *Config, opts ...ContextOption) (*Context, error) {
 		LocaleContext: &LocaleContext{
 			Config: conf,
 		},
+		Reporter:       &Reporter{},
 		OtherLanguages: make(map[string]*LocaleContext),
 	}
 	for _, opt := range opts {
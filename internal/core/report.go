@@ -0,0 +1,149 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type (
+	// Reporter 收集构建过程中出现的错误和警告, 严格模式下用于汇总并使构建失败
+	Reporter struct {
+		mu       sync.Mutex
+		errors   []*Error
+		warnings []*Error
+		// 非严格模式下也会使构建失败的错误
+		fatals []*Error
+	}
+	// BuildError 严格模式下汇总的构建错误
+	BuildError struct {
+		Errors []*Error
+	}
+)
+
+func asError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return &Error{Op: "build", Err: err}
+}
+
+// 同一个错误可能会被多次记录, 比如多个页面使用了同一个有问题的模版
+func appendError(errs []*Error, err *Error) []*Error {
+	for _, e := range errs {
+		if e.Op == err.Op && e.Path == err.Path && e.Err.Error() == err.Err.Error() {
+			return errs
+		}
+	}
+	return append(errs, err)
+}
+
+func (r *Reporter) Error(err error) {
+	if r == nil || err == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var buildErr *BuildError
+	if errors.As(err, &buildErr) {
+		for _, e := range buildErr.Errors {
+			r.errors = appendError(r.errors, e)
+		}
+		return
+	}
+	r.errors = appendError(r.errors, asError(err))
+}
+
+// Fatal 记录错误, 并且不管是否开启严格模式都会使构建失败
+func (r *Reporter) Fatal(err error) {
+	if r == nil || err == nil {
+		return
+	}
+	r.Error(err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fatals = appendError(r.fatals, asError(err))
+}
+
+func (r *Reporter) Warn(err error) {
+	if r == nil || err == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.warnings = appendError(r.warnings, asError(err))
+}
+
+func (r *Reporter) Errors() []*Error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.errors)
+}
+
+func (r *Reporter) Fatals() []*Error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.fatals)
+}
+
+func (r *Reporter) Warnings() []*Error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.warnings)
+}
+
+func (r *Reporter) Reset() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = nil
+	r.warnings = nil
+	r.fatals = nil
+}
+
+// Error 按照Op和Path分组输出所有错误
+func (e *BuildError) Error() string {
+	groups := make(map[string][]*Error)
+	for _, err := range e.Errors {
+		groups[err.Op] = append(groups[err.Op], err)
+	}
+	ops := make([]string, 0, len(groups))
+	for op := range groups {
+		ops = append(ops, op)
+	}
+	slices.Sort(ops)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "build failed with %d errors", len(e.Errors))
+	for _, op := range ops {
+		errs := groups[op]
+		slices.SortStableFunc(errs, func(a, b *Error) int {
+			return strings.Compare(a.Path, b.Path)
+		})
+		fmt.Fprintf(&b, "\n  %s (%d):", op, len(errs))
+		for _, err := range errs {
+			path := err.Path
+			if path == "" {
+				path = "-"
+			}
+			fmt.Fprintf(&b, "\n    %s: %s", path, err.Err.Error())
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReporterGroupsErrors(t *testing.T) {
+	r := &Reporter{}
+	r.Error(&Error{Op: "execute tpl", Err: errors.New("bad"), Path: "page.html"})
+	r.Error(&Error{Op: "execute tpl", Err: errors.New("bad"), Path: "page.html"})
+	r.Error(&Error{Op: "parse content", Err: errors.New("invalid front matter"), Path: "posts/b.md"})
+	r.Error(&Error{Op: "parse content", Err: errors.New("invalid front matter"), Path: "posts/a.md"})
+	r.Error(errors.New("unknown"))
+	r.Warn(&Error{Op: "render alias", Err: errors.New("invalid alias"), Path: "posts/a.md"})
+	r.Fatal(&Error{Op: "execute tpl", Err: errors.New("bad"), Path: "page.html"})
+
+	assert.Len(t, r.Errors(), 4)
+	assert.Len(t, r.Warnings(), 1)
+	assert.Len(t, r.Fatals(), 1)
+
+	err := &BuildError{Errors: r.Errors()}
+	assert.Equal(t, `build failed with 4 errors
+  build (1):
+    -: unknown
+  execute tpl (1):
+    page.html: bad
+  parse content (2):
+    posts/a.md: invalid front matter
+    posts/b.md: invalid front matter`, err.Error())
+
+	r.Reset()
+	assert.Empty(t, r.Errors())
+	assert.Empty(t, r.Warnings())
+	assert.Empty(t, r.Fatals())
+
+	var nilReporter *Reporter
+	nilReporter.Error(errors.New("ignored"))
+	assert.Empty(t, nilReporter.Errors())
+}
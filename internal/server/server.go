@@ -141,7 +141,7 @@ func (s *Server) Start(listen string) error {
 	return http.ListenAndServe(u.Host, mux)
 }
 
-func Serve(conf *core.Config, listen string, autoload bool, includeDrafts bool) error {
+func Serve(conf *core.Config, listen string, autoload bool, opts ...site.SiteOption) error {
 	ctx, err := core.NewContext(conf)
 	if err != nil {
 		return err
@@ -152,7 +152,7 @@ func Serve(conf *core.Config, listen string, autoload bool, includeDrafts bool)
 		ctx: ctx,
 	}
 
-	site, err := site.New(ctx, site.IncludeDrafts(includeDrafts))
+	site, err := site.New(ctx, opts...)
 	if err != nil {
 		return err
 	}
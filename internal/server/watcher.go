@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"io/fs"
 	"log"
 	"os"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site"
 )
 
 func (s *Server) watchDir(watcher *fsnotify.Watcher, path string) error {
@@ -44,6 +46,28 @@ func (s *Server) watch(paths []string, fn func(string, fs.FileInfo)) error {
 		}
 	}
 
+	// 内容目录之外被引用的文件只监听所在的目录, 重新构建后可能会有新的文件
+	watched := make(map[string]bool)
+	watchDependencies := func() {
+		for _, file := range s.site.ExternalDependencies() {
+			dir := filepath.Dir(filepath.FromSlash(strings.TrimPrefix(file, "/")))
+			if watched[dir] {
+				continue
+			}
+			watched[dir] = true
+			if err := watcher.Add(dir); err != nil {
+				s.ctx.Logger.Errorf("Error watching %s: %v", dir, err)
+			}
+		}
+	}
+	watchDependencies()
+
+	handle := fn
+	fn = func(file string, info fs.FileInfo) {
+		handle(file, info)
+		watchDependencies()
+	}
+
 	for {
 		select {
 		case event, ok := <-watcher.Events:
@@ -52,6 +76,12 @@ func (s *Server) watch(paths []string, fn func(string, fs.FileInfo)) error {
 			}
 			info, err := os.Stat(event.Name)
 			if err != nil {
+				// 文件已被删除或重命名
+				if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+					s.ctx.Logger.Infoln("The", event.Name, "has been removed. Rebuilding...")
+
+					fn(event.Name, nil)
+				}
 				continue
 			}
 			if info.IsDir() {
@@ -95,6 +125,12 @@ func (s *Server) watchFiles() {
 				return
 			}
 		}
+		// 内容目录之外被引用的文件, 例如include shortcode中/开头的路径, 文件可能同时位于static或者templates目录中
+		if srcPath := "/" + filepath.ToSlash(file); s.site.IsContentDependency(srcPath) {
+			if err := s.rebuildContent(srcPath); err != nil {
+				s.ctx.Logger.Errorf("Reload content err: %s", err.Error())
+			}
+		}
 		for _, staticDir := range staticDirs {
 			if strings.HasPrefix(file, staticDir+"/") {
 				if err := s.reloadStatic(staticDir, file, info); err != nil {
@@ -105,7 +141,7 @@ func (s *Server) watchFiles() {
 		}
 		for _, templateDir := range templatesDirs {
 			if strings.HasPrefix(file, templateDir+"/") {
-				if err := s.reloadTemplate(file, info); err != nil {
+				if err := s.reloadTemplate(templateDir, file, info); err != nil {
 					s.ctx.Logger.Errorf("Reload templates err: %s", err.Error())
 				}
 				return
@@ -124,23 +160,41 @@ func (s *Server) resloveWatchDirs(name string) []string {
 	return results
 }
 
+func (s *Server) notify(paths []string) {
+	if s.livereload == nil {
+		return
+	}
+	for _, path := range paths {
+		s.livereload.Notify(path)
+	}
+}
+
 func (s *Server) reloadContent(baseDir string, file string, info fs.FileInfo) error {
 	srcPath, err := filepath.Rel(baseDir, file)
 	if err != nil {
 		return err
 	}
-	if s.site.IsIgnoredContent(filepath.ToSlash(srcPath), info.IsDir()) {
+	srcPath = filepath.ToSlash(srcPath)
+	if s.site.IsIgnoredContent(srcPath, info != nil && info.IsDir()) && !s.site.IsContentDependency(srcPath) {
 		return nil
 	}
+	return s.rebuildContent(srcPath)
+}
 
-	s.fs.Reset()
+func (s *Server) rebuildContent(srcPath string) error {
+	paths, err := s.site.RebuildContent(context.TODO(), s.fs, srcPath)
+	if errors.Is(err, site.ErrRebuildAll) {
+		s.fs.Reset()
 
-	if err := s.site.BuildContent(context.TODO(), s.fs); err != nil {
+		if err := s.site.BuildContent(context.TODO(), s.fs); err != nil {
+			return err
+		}
+		paths = []string{"*.html"}
+	} else if err != nil {
 		return err
 	}
-	if s.livereload != nil {
-		s.livereload.Notify("*.html")
-	}
+	s.ctx.Logger.Infof("Rebuilt %d files", len(paths))
+	s.notify(paths)
 	return nil
 }
 
@@ -149,7 +203,15 @@ func (s *Server) reloadStatic(baseDir string, file string, info fs.FileInfo) err
 	if err != nil {
 		return err
 	}
-	if s.site.IsIgnoredStatic(filepath.ToSlash(srcPath), info.IsDir()) {
+	if s.site.IsIgnoredStatic(filepath.ToSlash(srcPath), info != nil && info.IsDir()) {
+		return nil
+	}
+	if info == nil {
+		dstPath := "/" + filepath.ToSlash(srcPath)
+		if err := s.fs.Remove(dstPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		s.notify([]string{dstPath})
 		return nil
 	}
 
@@ -177,14 +239,24 @@ func (s *Server) reloadStatic(baseDir string, file string, info fs.FileInfo) err
 	return nil
 }
 
-func (s *Server) reloadTemplate(file string, info fs.FileInfo) error {
-	s.fs.Reset()
-
-	if err := s.site.Build(context.TODO(), s.fs); err != nil {
+func (s *Server) reloadTemplate(baseDir string, file string, info fs.FileInfo) error {
+	name, err := filepath.Rel(baseDir, file)
+	if err != nil {
 		return err
 	}
-	if s.livereload != nil {
-		s.livereload.Notify("*.html")
+
+	paths, err := s.site.RebuildTemplate(context.TODO(), s.fs, filepath.ToSlash(name))
+	if errors.Is(err, site.ErrRebuildAll) {
+		s.fs.Reset()
+
+		if err := s.site.Build(context.TODO(), s.fs); err != nil {
+			return err
+		}
+		paths = []string{"*.html"}
+	} else if err != nil {
+		return err
 	}
+	s.ctx.Logger.Infof("Rebuilt %d files", len(paths))
+	s.notify(paths)
 	return nil
 }
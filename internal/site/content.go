@@ -2,18 +2,31 @@ package site
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	stdpath "path"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
 	"github.com/honmaple/snow/internal/core"
 	"github.com/honmaple/snow/internal/site/content"
+	"github.com/honmaple/snow/internal/site/content/parser"
 	"github.com/honmaple/snow/internal/utils/taskutil"
 )
 
+// IsContentDependency 返回被忽略的文件是否被其它内容引用, 例如org-mode中#+INCLUDE的文件
+func (site *Site) IsContentDependency(path string) bool {
+	return site.deps.DependsOn(path)
+}
+
+// ExternalDependencies 返回内容引用的内容目录之外的文件, 路径相对于项目根目录并以/开头
+func (site *Site) ExternalDependencies() []string {
+	return site.deps.ExternalFiles()
+}
+
 func (site *Site) IsIgnoredContent(path string, isDir bool) bool {
 	// 忽略以.或者_开头的文件或目录，不要忽略_index.md
 	if basename := stdpath.Base(path); !strings.HasPrefix(basename, "_index.") && (strings.HasPrefix(basename, "_") || strings.HasPrefix(basename, ".")) {
@@ -28,6 +41,7 @@ func (site *Site) IsIgnoredContent(path string, isDir bool) bool {
 		matched, err := doublestar.Match(pattern, matchPath)
 		if err != nil {
 			site.ctx.Logger.Warnf("The pattern %s match %s err: %s", pattern, path, err)
+			site.ctx.Reporter.Warn(&core.Error{Op: "match ignored_content", Err: err, Path: pattern})
 			continue
 		}
 		if matched {
@@ -40,6 +54,8 @@ func (site *Site) IsIgnoredContent(path string, isDir bool) bool {
 func (site *Site) loadContent(processor *content.Processor) (*ContentStore, error) {
 	store := NewContentStore()
 
+	var skippedDrafts, skippedFuture, skippedExpired atomic.Int64
+
 	insertPageByFile := func(file string, isBundle bool) error {
 		page, err := processor.ParsePage(file, isBundle)
 		if err != nil {
@@ -47,6 +63,15 @@ func (site *Site) loadContent(processor *content.Processor) (*ContentStore, erro
 		}
 
 		if page.Draft && !site.includeDrafts {
+			skippedDrafts.Add(1)
+			return nil
+		}
+		if page.IsFuture() && !site.includeFuture {
+			skippedFuture.Add(1)
+			return nil
+		}
+		if page.IsExpired() && !site.includeExpired {
+			skippedExpired.Add(1)
 			return nil
 		}
 		if !page.FrontMatter.GetBool("render", true) {
@@ -86,7 +111,17 @@ func (site *Site) loadContent(processor *content.Processor) (*ContentStore, erro
 	}
 
 	tasks := taskutil.NewPool[node](100, func(arg node) (err error) {
-		return insertPageByFile(arg.File, arg.IsBundle)
+		if err := insertPageByFile(arg.File, arg.IsBundle); err != nil {
+			site.ctx.Logger.Error(err.Error())
+
+			var includeErr *parser.IncludeError
+			if errors.As(err, &includeErr) {
+				site.ctx.Reporter.Fatal(err)
+			} else {
+				site.ctx.Reporter.Error(err)
+			}
+		}
+		return nil
 	})
 
 	walkDir := func(path string, info fs.DirEntry, err error) error {
@@ -153,6 +188,11 @@ func (site *Site) loadContent(processor *content.Processor) (*ContentStore, erro
 	}
 	tasks.StopAndWait()
 
+	if drafts, future, expired := skippedDrafts.Load(), skippedFuture.Load(), skippedExpired.Load(); drafts+future+expired > 0 {
+		site.ctx.Logger.Infof("Skipped: %d draft, %d future and %d expired pages", drafts, future, expired)
+	}
+	site.linkTranslations(store)
+
 	for _, lang := range site.ctx.GetAllLanguages() {
 		sections := store.Sections(lang)
 		if len(sections) > 0 {
@@ -185,67 +225,180 @@ func (site *Site) loadContent(processor *content.Processor) (*ContentStore, erro
 	return store, nil
 }
 
-func (site *Site) BuildContent(ctx context.Context, writer core.Writer) error {
+func (site *Site) newProcessor() (*content.Processor, *parser.Cache, *resultParser, error) {
 	contentFS, err := site.ctx.GetFS(core.MountContent, false, false)
 	if err != nil {
-		return err
+		return nil, nil, nil, err
 	}
-	processor := content.NewProcessor(site.ctx, contentFS)
 
-	now := time.Now()
+	var (
+		cache *parser.Cache
+		p     parser.Parser = parser.New(site.ctx)
+	)
+	if site.useCache {
+		cache = parser.NewCache(site.ctx, p, site.ctx.Config.GetString("cache_dir"))
+		p = cache
+	}
+	results := &resultParser{Parser: p, results: site.results}
+	return content.NewProcessor(site.ctx, contentFS, content.WithParser(results)), cache, results, nil
+}
+
+func (site *Site) parseContent() (*content.Processor, *ContentStore, error) {
+	processor, cache, results, err := site.newProcessor()
+	if err != nil {
+		return nil, nil, err
+	}
+	site.deps.ResetIncludes()
 
 	store, err := site.loadContent(processor)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	if cache != nil {
+		hits, misses := cache.Stats()
+		site.ctx.Logger.Infof("Parsed content: %d cached, %d parsed", hits, misses)
+		// 使用了上一次的解析结果时缓存中没有记录所有使用的文件
+		if results.Reused() == 0 {
+			if err := cache.Prune(); err != nil {
+				site.ctx.Logger.Warnf("Prune cache err: %s", err.Error())
+			}
+		}
 	}
 	for _, lang := range site.ctx.GetAllLanguages() {
 		site.hook.HandleContent(store, lang)
 	}
+	return processor, store, nil
+}
 
-	for _, lang := range site.ctx.GetAllLanguages() {
-		site.ctx.Logger.Infof("Building %s site...", lang)
+// trackDependencies 重新记录内容的依赖, 返回之前记录的输出
+func (site *Site) trackDependencies(arg any, outputs map[string][]string) {
+	files := dependencyFiles(arg)
+	switch v := arg.(type) {
+	case *content.Page:
+		files = append(files, site.deps.Includes(v.File.Path)...)
+	case *content.Section:
+		files = append(files, site.deps.Includes(v.File.Path)...)
+	}
+	key := dependencyKey(arg)
+	outputs[key] = site.deps.Track(key, files)
 
-		tplset := &ContentTemplateSet{
-			lang:        lang,
-			store:       store,
-			TemplateSet: site.tplset,
+	if term, ok := arg.(*content.TaxonomyTerm); ok {
+		for _, child := range term.Children {
+			site.trackDependencies(child, outputs)
 		}
+	}
+}
 
-		tasks := taskutil.NewPool[any](100, func(arg any) (err error) {
-			switch v := arg.(type) {
-			case *content.Section:
-				err = processor.RenderSection(v, tplset, writer)
-			case *content.Page:
-				err = processor.RenderPage(v, tplset, writer)
-			case *content.Taxonomy:
-				err = processor.RenderTaxonomy(v, tplset, writer)
-			}
-			if err != nil {
-				site.ctx.Logger.Error(err.Error())
-			}
-			return nil
-		})
+func (site *Site) renderContent(processor *content.Processor, store *ContentStore, lang string, writer core.Writer, filter func(any) bool) {
+	tplset := &ContentTemplateSet{
+		lang:        lang,
+		deps:        site.deps,
+		store:       store,
+		TemplateSet: site.tplset,
+	}
 
-		for _, section := range store.Sections(lang) {
-			tasks.Invoke(section)
-		}
+	tasks := taskutil.NewPool[any](100, func(arg any) (err error) {
+		outputs := make(map[string][]string)
+		site.trackDependencies(arg, outputs)
 
-		for _, page := range store.Pages(lang) {
-			tasks.Invoke(page)
+		switch v := arg.(type) {
+		case *content.Section:
+			err = processor.RenderSection(v, tplset, writer)
+		case *content.Page:
+			err = processor.RenderPage(v, tplset, writer)
+		case *content.Taxonomy:
+			err = processor.RenderTaxonomyList(v, tplset, writer)
+		case *content.TaxonomyTerm:
+			err = processor.RenderTaxonomyTerm(v, tplset, writer)
 		}
+		if err != nil {
+			site.ctx.Logger.Error(err.Error())
+			site.ctx.Reporter.Error(err)
+			return nil
+		}
+		// 删除不再输出的文件, 例如减少的分页
+		if rw, ok := writer.(removableWriter); ok {
+			for key, old := range outputs {
+				for _, output := range site.deps.StaleOutputs(key, old) {
+					if err := rw.Remove(output); err != nil {
+						site.ctx.Logger.Warnf("remove %s err: %s", output, err.Error())
+					}
+				}
+			}
+		}
+		return nil
+	})
+	invoke := func(arg any) {
+		if filter == nil || filter(arg) {
+			tasks.Invoke(arg)
+		}
+	}
+
+	for _, section := range store.Sections(lang) {
+		invoke(section)
+	}
+
+	for _, page := range store.Pages(lang) {
+		invoke(page)
+	}
 
-		for _, page := range store.HiddenPages(lang) {
-			tasks.Invoke(page)
+	for _, page := range store.HiddenPages(lang) {
+		invoke(page)
+	}
+
+	for _, taxonomy := range store.Taxonomies(lang) {
+		invoke(taxonomy)
+
+		var walk func(content.TaxonomyTerms)
+		walk = func(terms content.TaxonomyTerms) {
+			for _, term := range terms {
+				// RenderTaxonomyTerm会同时渲染子term
+				if filter == nil || filter(term) {
+					tasks.Invoke(term)
+					continue
+				}
+				walk(term.Children)
+			}
 		}
+		walk(taxonomy.Terms)
+	}
+	tasks.StopAndWait()
+}
+
+func (site *Site) BuildContent(ctx context.Context, writer core.Writer) error {
+	now := time.Now()
+
+	site.results.Reset()
+	processor, store, err := site.parseContent()
+	if err != nil {
+		return err
+	}
+	outputs, err := site.collectOutputs(processor, store)
+	if err != nil {
+		return err
+	}
+	if err := site.checkOutputs(outputs); err != nil {
+		return err
+	}
+	if errs := site.ctx.Reporter.Fatals(); len(errs) > 0 {
+		return &core.BuildError{Errors: errs}
+	}
+	site.deps.Reset()
+	site.store = store
+	site.outputs = outputs
+	site.processor = processor
+
+	for _, lang := range site.ctx.GetAllLanguages() {
+		site.ctx.Logger.Infof("Building %s site...", lang)
+
+		site.renderContent(processor, store, lang, writer, nil)
 
 		ts := make([]string, 0)
 		for _, taxonomy := range store.Taxonomies(lang) {
 			if count := len(taxonomy.Terms); count > 0 {
 				ts = append(ts, fmt.Sprintf("%d %s", count, taxonomy.Name))
 			}
-			tasks.Invoke(taxonomy)
 		}
-		tasks.StopAndWait()
 
 		tstat := ""
 		if len(ts) > 0 {
@@ -1,10 +1,13 @@
 package content
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/fs"
+	"maps"
 	stdpath "path"
+	"slices"
 	"strings"
 
 	"github.com/bmatcuk/doublestar/v4"
@@ -16,6 +19,8 @@ type (
 		File      *File
 		Path      string
 		Permalink string
+		// 解析时生成的附件内容, 不存在于内容目录中
+		Data []byte
 	}
 	Assets []*Asset
 )
@@ -88,8 +93,58 @@ func (d *Processor) resolveAssetPath(basePath string, assetPath string) string {
 	return stdpath.Join(basePath, assetPath)
 }
 
+// parseGeneratedAssets 返回解析内容时生成的附件, 与内容输出到同一目录
+// 内容和摘要中引用附件的src替换为permalink, 摘要在列表页中显示时不会使用错误的相对路径
+func (d *Processor) parseGeneratedAssets(node *Node, basePath string) (Assets, error) {
+	if len(node.assets) == 0 {
+		return nil, nil
+	}
+	names := slices.Sorted(maps.Keys(node.assets))
+
+	lctx := d.ctx.For(node.Lang)
+
+	assets := make(Assets, 0, len(names))
+	replaces := make([]string, 0, len(names)*2)
+	for _, name := range names {
+		if err := d.validateAssetPath(name); err != nil {
+			return nil, err
+		}
+		file, err := d.parseFile(stdpath.Join(node.File.Dir, name))
+		if err != nil {
+			return nil, err
+		}
+		asset := &Asset{
+			File: file,
+			Data: node.assets[name],
+		}
+		asset.Path = d.resolveAssetPath(basePath, name)
+		asset.Permalink = lctx.GetURL(asset.Path)
+		assets = append(assets, asset)
+
+		replaces = append(replaces, `src="`+name+`"`, `src="`+asset.Permalink+`"`)
+	}
+	replacer := strings.NewReplacer(replaces...)
+	node.Content = replacer.Replace(node.Content)
+	node.Summary = replacer.Replace(node.Summary)
+	return assets, nil
+}
+
 func (d *Processor) RenderAsset(asset *Asset, writer core.Writer) error {
-	if asset == nil || asset.File == nil || asset.File.Path == "" || asset.Path == "" {
+	if asset == nil || asset.Path == "" {
+		return nil
+	}
+	if asset.Data != nil {
+		d.ctx.Logger.Debugf("write generated asset -> %s", asset.Path)
+		if err := writer.WriteFile(context.TODO(), asset.Path, bytes.NewReader(asset.Data)); err != nil {
+			return &core.Error{
+				Op:   "write asset",
+				Err:  err,
+				Path: asset.Path,
+			}
+		}
+		return nil
+	}
+	if asset.File == nil || asset.File.Path == "" {
 		return nil
 	}
 
@@ -5,6 +5,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/flosch/pongo2/v7"
@@ -317,3 +318,64 @@ func TestSectionAssetsRejectUncleanRelativePath(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderPageGeneratedAssetsWritesData(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "content", "posts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "content", "posts", "hello.md"), []byte("# Hello"), 0644))
+
+	processor := newAssetTestProcessor(t, root, &parser.Result{
+		Content: `<p><img src="plot.png"></p>`,
+		Assets: map[string][]byte{
+			"plot.png": []byte("png"),
+		},
+	})
+	page, err := processor.ParsePage("posts/hello.md", false)
+	require.NoError(t, err)
+
+	require.Len(t, page.Assets, 1)
+	assert.Equal(t, "/posts/hello/plot.png", page.Assets[0].Path)
+	assert.Equal(t, "plot.png", page.Assets[0].File.Name)
+
+	w := writer.NewMemoryWriter()
+	require.NoError(t, processor.RenderPage(page, assetTestTemplateSet{}, w))
+	assert.Equal(t, "png", readMemoryFile(t, w, "/posts/hello/plot.png"))
+}
+
+func TestPageGeneratedAssetsUsePermalink(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "content", "posts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "content", "posts", "hello.md"), []byte("# Hello"), 0644))
+
+	processor := newAssetTestProcessor(t, root, &parser.Result{
+		Content: `<p><img src="plot.png"></p><p><img src="cover.png"></p>`,
+		Summary: `<p><img src="plot.png"></p>`,
+		Assets: map[string][]byte{
+			"plot.png": []byte("png"),
+		},
+	})
+	page, err := processor.ParsePage("posts/hello.md", false)
+	require.NoError(t, err)
+
+	require.Len(t, page.Assets, 1)
+	permalink := page.Assets[0].Permalink
+	assert.True(t, strings.HasSuffix(permalink, "/posts/hello/plot.png"))
+
+	// 摘要在列表页中显示, 生成的附件需要使用permalink
+	assert.Equal(t, `<p><img src="`+permalink+`"></p><p><img src="cover.png"></p>`, page.Content)
+	assert.Equal(t, `<p><img src="`+permalink+`"></p>`, page.Summary)
+}
+
+func TestPageGeneratedAssetsRejectUncleanRelativePath(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "content", "posts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "content", "posts", "hello.md"), []byte("# Hello"), 0644))
+
+	processor := newAssetTestProcessor(t, root, &parser.Result{
+		Assets: map[string][]byte{
+			"../plot.png": []byte("png"),
+		},
+	})
+	_, err := processor.ParsePage("posts/hello.md", false)
+	require.Error(t, err)
+}
@@ -0,0 +1,42 @@
+package content
+
+type (
+	// Backlink 链接到当前页面或者栏目的内容
+	Backlink struct {
+		*Node
+
+		// page或者section
+		Kind      string
+		Path      string
+		Permalink string
+	}
+	Backlinks []*Backlink
+)
+
+func NewPageBacklink(page *Page) *Backlink {
+	return &Backlink{
+		Node:      page.Node,
+		Kind:      "page",
+		Path:      page.Path,
+		Permalink: page.Permalink,
+	}
+}
+
+func NewSectionBacklink(section *Section) *Backlink {
+	return &Backlink{
+		Node:      section.Node,
+		Kind:      "section",
+		Path:      section.Path,
+		Permalink: section.Permalink,
+	}
+}
+
+// Add 添加链接来源, 同一个文件只会添加一次
+func (links Backlinks) Add(link *Backlink) Backlinks {
+	for _, l := range links {
+		if l.File.Path == link.File.Path {
+			return links
+		}
+	}
+	return append(links, link)
+}
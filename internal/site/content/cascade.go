@@ -0,0 +1,165 @@
+package content
+
+import (
+	stdpath "path"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+type (
+	// Cascade section中cascade配置, 会合并到下级的page或section
+	//
+	//	cascade:
+	//	  - template: post.html
+	//	    _target:
+	//	      path: "posts/2024/**"
+	//	      lang: "en"
+	//	      kind: "page"
+	Cascade struct {
+		values *viper.Viper
+		path   string
+		lang   string
+		kind   string
+	}
+	Cascades []*Cascade
+)
+
+func (c *Cascade) Match(file *File, lang string, kind string) bool {
+	if c.path != "" {
+		if ok, _ := doublestar.Match(c.path, file.Path); !ok {
+			return false
+		}
+	}
+	if c.lang != "" {
+		if ok, _ := doublestar.Match(c.lang, lang); !ok {
+			return false
+		}
+	}
+	if ok, _ := doublestar.Match(c.kind, kind); !ok {
+		return false
+	}
+	return true
+}
+
+func newCascade(m map[string]any) *Cascade {
+	c := &Cascade{
+		values: viper.New(),
+		kind:   "page",
+	}
+	for k, v := range m {
+		if k == "_target" {
+			target := cast.ToStringMapString(v)
+			c.path = target["path"]
+			c.lang = target["lang"]
+			if kind := target["kind"]; kind != "" {
+				c.kind = kind
+			}
+			continue
+		}
+		c.values.Set(k, v)
+	}
+	return c
+}
+
+func parseCascades(fm *FrontMatter) Cascades {
+	cascades := make(Cascades, 0)
+	switch v := fm.Get("cascade").(type) {
+	case []any:
+		for _, item := range v {
+			if m, err := cast.ToStringMapE(item); err == nil {
+				cascades = append(cascades, newCascade(m))
+			}
+		}
+	case nil:
+	default:
+		if m, err := cast.ToStringMapE(v); err == nil {
+			cascades = append(cascades, newCascade(m))
+		}
+	}
+	return cascades
+}
+
+func cascadeKey(dir string, lang string) string {
+	return dir + ":" + lang
+}
+
+// findCascades 从近到远返回上级section的cascade配置, 只有section没有对应语言的_index时才使用默认语言
+func (d *Processor) findCascades(dir string, lang string) Cascades {
+	cascades := make(Cascades, 0)
+	for {
+		if dir == "." {
+			dir = ""
+		}
+		v, ok := d.cascades.Load(cascadeKey(dir, lang))
+		if !ok {
+			v, ok = d.cascades.Load(cascadeKey(dir, d.ctx.GetDefaultLanguage()))
+		}
+		if ok {
+			cascades = append(cascades, v.(Cascades)...)
+		}
+		if dir == "" {
+			break
+		}
+		dir = stdpath.Dir(dir)
+	}
+	return cascades
+}
+
+// matchCascades 返回匹配当前文件的cascade配置, 最近的优先
+func (d *Processor) matchCascades(file *File, lang string, kind string) Cascades {
+	dir := file.Dir
+	if kind == "section" {
+		if dir == "" {
+			return nil
+		}
+		dir = stdpath.Dir(dir)
+	}
+
+	cascades := make(Cascades, 0)
+	for _, cascade := range d.findCascades(dir, lang) {
+		if cascade.Match(file, lang, kind) {
+			cascades = append(cascades, cascade)
+		}
+	}
+	return cascades
+}
+
+// cascadeDefaults 返回解析前使用的cascade配置, 例如markdown的解析配置
+func (d *Processor) cascadeDefaults(file *File, lang string, kind string) map[string]any {
+	cascades := d.matchCascades(file, lang, kind)
+	if len(cascades) == 0 {
+		return nil
+	}
+
+	values := viper.New()
+	for _, cascade := range cascades {
+		for _, k := range cascade.values.AllKeys() {
+			if !values.IsSet(k) {
+				values.Set(k, cascade.values.Get(k))
+			}
+		}
+	}
+	return values.AllSettings()
+}
+
+// applyCascades 合并上级section的cascade配置, 优先级: 页面front matter > 最近的cascade > 全局配置
+func (d *Processor) applyCascades(fm *FrontMatter, raw map[string]any, file *File, lang string, kind string) {
+	cascades := d.matchCascades(file, lang, kind)
+	if len(cascades) == 0 {
+		return
+	}
+
+	rawFM := NewFrontMatter(raw)
+	applied := make(map[string]bool)
+	for _, cascade := range cascades {
+		for _, k := range cascade.values.AllKeys() {
+			if applied[k] || rawFM.IsSet(k) {
+				continue
+			}
+			fm.Set(k, cascade.values.Get(k))
+			applied[k] = true
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package content
+
+import (
+	"fmt"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/content/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cascadeTestParser map[string]map[string]any
+
+func (p cascadeTestParser) Parse(_ fs.FS, file string) (*parser.Result, error) {
+	fm, ok := p[file]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", file)
+	}
+	return &parser.Result{FrontMatter: fm}, nil
+}
+
+func (p cascadeTestParser) SupportedExtensions() []string {
+	return []string{".md"}
+}
+
+func TestCascadeFrontMatter(t *testing.T) {
+	p := cascadeTestParser{
+		"_index.md": {
+			"cascade": map[string]any{
+				"authors":  []string{"snow"},
+				"template": "home.html",
+			},
+		},
+		"posts/_index.md": {
+			"cascade": []any{
+				map[string]any{"template": "fr.html", "_target": map[string]any{"lang": "fr"}},
+				map[string]any{"template": "section.html", "_target": map[string]any{"kind": "section"}},
+				map[string]any{"template": "post.html", "formats": map[string]any{"rss": map[string]any{"path": "rss.xml"}}},
+				map[string]any{"comment": true, "_target": map[string]any{"path": "posts/2024/**"}},
+			},
+		},
+		"posts/2024/_index.md": {},
+		"posts/a.md":           {"template": "custom.html"},
+		"posts/b.md":           {},
+		"posts/b.fr.md":        {},
+		"posts/2024/c.md":      {},
+		"about.md":             {},
+	}
+	fsys := fstest.MapFS{}
+	for file := range p {
+		fsys[file] = &fstest.MapFile{}
+	}
+
+	conf := core.DefaultConfig()
+	conf.Set("languages.fr", map[string]any{})
+	conf.Set("pages.posts.template", "config.html")
+	conf.Set("pages.posts.license", "MIT")
+	ctx, err := core.NewContext(conf)
+	require.NoError(t, err)
+
+	processor := NewProcessor(ctx, fsys, WithParser(p))
+
+	_, err = processor.ParseHomeSections(".")
+	require.NoError(t, err)
+	_, err = processor.ParseSection("posts/_index.md")
+	require.NoError(t, err)
+	sub, err := processor.ParseSection("posts/2024/_index.md")
+	require.NoError(t, err)
+	assert.Equal(t, "section.html", sub.FrontMatter.GetString("template"))
+
+	parse := func(file string) *Page {
+		page, err := processor.ParsePage(file, false)
+		require.NoError(t, err)
+		return page
+	}
+
+	// 页面自身的配置优先
+	a := parse("posts/a.md")
+	assert.Equal(t, "custom.html", a.FrontMatter.GetString("template"))
+	assert.Equal(t, []string{"snow"}, a.FrontMatter.GetStringSlice("authors"))
+	assert.Equal(t, "MIT", a.FrontMatter.GetString("license"))
+	assert.False(t, a.FrontMatter.GetBool("comment"))
+
+	// 最近的cascade优先于全局配置和上级cascade
+	b := parse("posts/b.md")
+	assert.Equal(t, "post.html", b.FrontMatter.GetString("template"))
+	assert.Equal(t, "rss.xml", b.FrontMatter.GetString("formats.rss.path"))
+
+	assert.Equal(t, "fr.html", parse("posts/b.fr.md").FrontMatter.GetString("template"))
+
+	c := parse("posts/2024/c.md")
+	assert.True(t, c.FrontMatter.GetBool("comment"))
+	assert.Equal(t, "post.html", c.FrontMatter.GetString("template"))
+
+	assert.Equal(t, "home.html", parse("about.md").FrontMatter.GetString("template"))
+}
+
+type cascadeDefaultsParser struct {
+	cascadeTestParser
+	defaults map[string]map[string]any
+}
+
+func (p *cascadeDefaultsParser) ParseDefaults(fsys fs.FS, file string, defaults map[string]any) (*parser.Result, error) {
+	p.defaults[file] = defaults
+	return p.Parse(fsys, file)
+}
+
+func TestCascadeBeforeParse(t *testing.T) {
+	p := &cascadeDefaultsParser{
+		cascadeTestParser: cascadeTestParser{
+			"_index.md": {},
+			"posts/_index.md": {
+				"cascade": map[string]any{
+					"template": "post.html",
+					"markdown": map[string]any{"hard_wraps": true},
+				},
+			},
+			"posts/_index.fr.md": {},
+			"posts/a.md":         {},
+			"posts/a.fr.md":      {},
+		},
+		defaults: make(map[string]map[string]any),
+	}
+	fsys := fstest.MapFS{}
+	for file := range p.cascadeTestParser {
+		fsys[file] = &fstest.MapFile{}
+	}
+
+	conf := core.DefaultConfig()
+	conf.Set("languages.fr", map[string]any{})
+	ctx, err := core.NewContext(conf)
+	require.NoError(t, err)
+
+	processor := NewProcessor(ctx, fsys, WithParser(p))
+
+	_, err = processor.ParseHomeSections(".")
+	require.NoError(t, err)
+	_, err = processor.ParseSection("posts/_index.md")
+	require.NoError(t, err)
+	_, err = processor.ParseSection("posts/_index.fr.md")
+	require.NoError(t, err)
+
+	// 解析配置在解析前传给解析器
+	a, err := processor.ParsePage("posts/a.md", false)
+	require.NoError(t, err)
+	assert.Equal(t, "post.html", a.FrontMatter.GetString("template"))
+	assert.Equal(t, map[string]any{"hard_wraps": true}, p.defaults["posts/a.md"]["markdown"])
+
+	// 存在翻译的_index时不使用默认语言的cascade
+	fr, err := processor.ParsePage("posts/a.fr.md", false)
+	require.NoError(t, err)
+	assert.Equal(t, "", fr.FrontMatter.GetString("template"))
+	assert.Empty(t, p.defaults["posts/a.fr.md"])
+}
@@ -6,6 +6,7 @@ import (
 	stdpath "path"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/honmaple/snow/internal/core"
 	"github.com/honmaple/snow/internal/site/content/parser"
@@ -22,6 +23,9 @@ type (
 		contentFS  fs.FS
 		parser     parser.Parser
 		parserExts map[string]bool
+		// 已经解析的section的cascade配置
+		cascades sync.Map
+		schemas  sync.Map
 	}
 	ProcessorOption func(*Processor)
 )
@@ -1,11 +1,13 @@
 package content
 
 import (
+	"errors"
 	"golang.org/x/net/html"
 	stdpath "path"
 	"strings"
 	"unicode"
 
+	"github.com/honmaple/snow/internal/core"
 	"github.com/honmaple/snow/internal/site/content/parser"
 )
 
@@ -26,6 +28,16 @@ type (
 		Weight      int64
 		WordCount   int64
 		ReadingTime int64
+
+		// 相同TranslationKey不同语言的内容互为翻译, 默认为去掉语言后缀的文件路径
+		TranslationKey string
+		// org-roam的:ID:属性和对应的标题锚点, 用于解析id:链接
+		IDs map[string]string
+		// 解析时读取的其它文件, 例如org-mode的#+INCLUDE
+		Includes []string
+
+		// 解析时生成的附件
+		assets map[string][]byte
 	}
 	Heading = parser.Heading
 )
@@ -97,10 +109,34 @@ func (d *Processor) parseNode(fullpath string) (*Node, error) {
 		return nil, err
 	}
 
-	// TODO: 增加缓存
-	result, err := d.parser.Parse(d.contentFS, fullpath)
+	kind := "page"
+	if strings.HasPrefix(file.Name, "_index.") {
+		kind = "section"
+	}
+
+	// cascade中的解析配置需要在解析前传给解析器, 此时只能使用文件名中的语言
+	var result *parser.Result
+	if p, ok := d.parser.(parser.DefaultsParser); ok {
+		lang := strings.TrimPrefix(stdpath.Ext(file.BaseName), ".")
+		if !d.ctx.VerifyLanguage(lang) {
+			lang = d.ctx.GetDefaultLanguage()
+		}
+		result, err = p.ParseDefaults(d.contentFS, fullpath, d.cascadeDefaults(file, lang, kind))
+	} else {
+		result, err = d.parser.Parse(d.contentFS, fullpath)
+	}
 	if err != nil {
-		return nil, err
+		return nil, &core.Error{
+			Op:   "parse content",
+			Err:  err,
+			Path: fullpath,
+		}
+	}
+	// 公式转换失败等错误不影响输出, 只记录到Reporter, 严格模式下构建失败
+	for _, msg := range result.Errors {
+		e := &core.Error{Op: "parse content", Err: errors.New(msg), Path: fullpath}
+		d.ctx.Logger.Error(e.Error())
+		d.ctx.Reporter.Error(e)
 	}
 
 	fm := NewFrontMatter(result.FrontMatter)
@@ -127,6 +163,8 @@ func (d *Processor) parseNode(fullpath string) (*Node, error) {
 		file.LanguageName = lang
 	}
 
+	d.applyCascades(fm, result.FrontMatter, file, lang, kind)
+
 	node := &Node{
 		FrontMatter: fm,
 		File:        file,
@@ -139,9 +177,18 @@ func (d *Processor) parseNode(fullpath string) (*Node, error) {
 		Content:     result.Content,
 		RawContent:  result.RawContent,
 		Summary:     result.Summary,
+		IDs:         result.IDs,
+		Includes:    result.Includes,
+		assets:      result.Assets,
+	}
+	node.TranslationKey = fm.GetString("translation_key")
+	if node.TranslationKey == "" {
+		node.TranslationKey = stdpath.Join(file.Dir, file.BaseName)
 	}
 	node.WordCount, node.ReadingTime = d.countReadingStats(node.Content)
 
+	d.validateFrontMatter(node, result.FrontMatter, kind)
+
 	lctx := d.ctx.For(lang)
 	if node.Summary == "" {
 		if summary := fm.GetString("summary"); summary != "" {
@@ -29,3 +29,23 @@ func TestParseNodePreservesRawContent(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "# Hello", section.RawContent)
 }
+
+func TestParseNodeReportsResultErrors(t *testing.T) {
+	root := t.TempDir()
+	contentDir := filepath.Join(root, "content")
+	require.NoError(t, os.MkdirAll(contentDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(contentDir, "hello.md"), []byte("$x^{$"), 0644))
+
+	processor := newAssetTestProcessor(t, root, &parser.Result{
+		Content: `<span class="math inline">\(x^{\)</span>`,
+		Errors:  []string{`invalid math "x^{": mismatched curly brace at position 2`},
+	})
+	page, err := processor.ParsePage("hello.md", false)
+	require.NoError(t, err)
+	assert.Contains(t, page.Content, "math inline")
+
+	errs := processor.ctx.Reporter.Errors()
+	require.Len(t, errs, 1)
+	assert.Equal(t, "hello.md", errs[0].Path)
+	assert.Contains(t, errs[0].Error(), "mismatched curly brace")
+}
@@ -0,0 +1,120 @@
+package content
+
+import (
+	stdpath "path"
+	"strings"
+)
+
+type (
+	// Output 渲染时会写入的文件, 用于在渲染前检查输出路径是否冲突
+	Output struct {
+		// 输出文件, 例如 /posts/hello/index.html
+		File string
+		// page, section, pager, format, alias, asset, taxonomy, term
+		Kind string
+		// 来源文件, 分类和分类项使用分类名称
+		Source string
+	}
+	Outputs []*Output
+)
+
+// OutputFile 返回路径实际写入的文件, 以/结尾的路径写入index.html
+func OutputFile(path string) string {
+	if strings.HasSuffix(path, "/") {
+		path = path + "index.html"
+	}
+	return stdpath.Join("/", path)
+}
+
+// resolveAliasPath 相对路径的alias基于basePath
+//
+//	aliases: ["alias.html", "/alias.html"]
+func (d *Processor) resolveAliasPath(alias string, basePath string) (string, bool) {
+	if alias == "" || alias == "." || stdpath.Clean(alias) != alias {
+		return "", false
+	}
+	if !strings.HasPrefix(alias, "/") {
+		if strings.HasSuffix(basePath, "/") {
+			alias = stdpath.Join(basePath, alias)
+		} else {
+			alias = stdpath.Join(stdpath.Dir(basePath), alias)
+		}
+	}
+	return alias, true
+}
+
+func (outputs Outputs) add(path string, kind string, source string) Outputs {
+	if path == "" {
+		return outputs
+	}
+	return append(outputs, &Output{File: OutputFile(path), Kind: kind, Source: source})
+}
+
+func (outputs Outputs) addAssets(assets Assets) Outputs {
+	for _, asset := range assets {
+		if asset == nil || asset.File == nil || asset.File.Path == "" {
+			continue
+		}
+		outputs = outputs.add(asset.Path, "asset", asset.File.Path)
+	}
+	return outputs
+}
+
+func (d *Processor) PageOutputs(page *Page) Outputs {
+	outputs := make(Outputs, 0)
+	outputs = outputs.add(page.Path, "page", page.File.Path)
+	for _, alias := range page.FrontMatter.GetStringSlice("aliases") {
+		if aliasPath, ok := d.resolveAliasPath(alias, page.Path); ok {
+			outputs = outputs.add(d.resolvePagePath(page, aliasPath), "alias", page.File.Path)
+		}
+	}
+	for _, format := range page.Formats {
+		outputs = outputs.add(format.Path, "format", page.File.Path)
+	}
+	return outputs.addAssets(page.Assets)
+}
+
+func (d *Processor) SectionOutputs(section *Section) Outputs {
+	outputs := make(Outputs, 0)
+	for _, pager := range d.sectionPagers(section) {
+		kind := "section"
+		if pager.PageNum > 1 {
+			kind = "pager"
+		}
+		outputs = outputs.add(pager.Path, kind, section.File.Path)
+	}
+	for _, alias := range section.FrontMatter.GetStringSlice("aliases") {
+		if aliasPath, ok := d.resolveAliasPath(alias, section.Path); ok {
+			outputs = outputs.add(d.resolveSectionPath(section, aliasPath), "alias", section.File.Path)
+		}
+	}
+	for _, format := range section.Formats {
+		outputs = outputs.add(format.Path, "format", section.File.Path)
+	}
+	return outputs.addAssets(section.Assets)
+}
+
+func (d *Processor) TaxonomyOutputs(taxonomy *Taxonomy) Outputs {
+	outputs := make(Outputs, 0)
+	outputs = outputs.add(taxonomy.Path, "taxonomy", taxonomy.Name)
+
+	var walk func(TaxonomyTerms)
+	walk = func(terms TaxonomyTerms) {
+		for _, term := range terms {
+			source := taxonomy.Name + ":" + term.GetFullName()
+			for _, pager := range d.taxonomyTermPagers(term) {
+				kind := "term"
+				if pager.PageNum > 1 {
+					kind = "pager"
+				}
+				outputs = outputs.add(pager.Path, kind, source)
+			}
+			for _, format := range term.Formats {
+				outputs = outputs.add(format.Path, "format", source)
+			}
+			walk(term.Children)
+		}
+	}
+	walk(taxonomy.Terms)
+	return outputs
+}
@@ -24,6 +24,9 @@ type (
 
 		Date     time.Time
 		Modified time.Time
+		// 定时发布和过期时间, 未设置时为零值
+		PublishDate time.Time
+		ExpiryDate  time.Time
 
 		Path      string
 		Permalink string
@@ -32,10 +35,24 @@ type (
 		Assets  Assets
 
 		Formats Formats
+		// 其它语言的版本, key为语言
+		Translations map[string]*Page
+		// 链接到当前页面的内容, 由links hook解析
+		Backlinks Backlinks
 	}
 	Pages []*Page
 )
 
+// IsFuture 是否还未到发布时间
+func (page *Page) IsFuture() bool {
+	return !page.PublishDate.IsZero() && page.PublishDate.After(time.Now())
+}
+
+// IsExpired 是否已经过期
+func (page *Page) IsExpired() bool {
+	return !page.ExpiryDate.IsZero() && !page.ExpiryDate.After(time.Now())
+}
+
 func (page *Page) Ancestors() Sections {
 	if page == nil || page.Section == nil {
 		return nil
@@ -238,6 +255,16 @@ func (d *Processor) IsPageBundle(fullpath string) ([]string, bool) {
 	return nil, false
 }
 
+// parsePageTime 按照front_matter.{name}配置的字段名依次读取时间
+func (d *Processor) parsePageTime(fm *FrontMatter, name string) time.Time {
+	for _, key := range d.ctx.Config.GetStringSlice("front_matter." + name) {
+		if t := fm.GetTime(key); !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
 func (d *Processor) ParsePage(fullpath string, isBundle bool) (*Page, error) {
 	node, err := d.parseNode(fullpath)
 	if err != nil {
@@ -252,6 +279,9 @@ func (d *Processor) ParsePage(fullpath string, isBundle bool) (*Page, error) {
 		Date:     node.FrontMatter.GetTime("date"),
 		Modified: node.FrontMatter.GetTime("modified"),
 		IsBundle: isBundle,
+
+		PublishDate: d.parsePageTime(node.FrontMatter, "publish_date"),
+		ExpiryDate:  d.parsePageTime(node.FrontMatter, "expiry_date"),
 	}
 	if page.Title == "" {
 		if isBundle && page.File.Dir != "" {
@@ -286,6 +316,11 @@ func (d *Processor) ParsePage(fullpath string, isBundle bool) (*Page, error) {
 		}
 		page.Assets = assets
 	}
+	generated, err := d.parseGeneratedAssets(page.Node, page.Path)
+	if err != nil {
+		return nil, err
+	}
+	page.Assets = append(page.Assets, generated...)
 
 	page.Formats = d.ParsePageFormats(page)
 	return page, nil
@@ -382,24 +417,19 @@ func (d *Processor) RenderPage(page *Page, tplset template.TemplateSet, writer c
 	}
 	if tpl := tplset.Lookup("alias.html", "partials/alias.html"); tpl != nil {
 		for _, alias := range page.FrontMatter.GetStringSlice("aliases") {
-			if alias == "" || alias == "." || stdpath.Clean(alias) != alias {
+			aliasPath, ok := d.resolveAliasPath(alias, page.Path)
+			if !ok {
 				d.ctx.Logger.Warnf("invalid alias '%s' for %s", alias, page.File.Path)
+				d.ctx.Reporter.Warn(&core.Error{Op: "render alias", Err: fmt.Errorf("invalid alias '%s'", alias), Path: page.File.Path})
 				continue
 			}
-			// aliases: ["alias.html", "/alias.html"]
-			if !strings.HasPrefix(alias, "/") {
-				if strings.HasSuffix(page.Path, "/") {
-					alias = stdpath.Join(page.Path, alias)
-				} else {
-					alias = stdpath.Join(stdpath.Dir(page.Path), alias)
-				}
-			}
 			// alias可以使用变量，方便重构url
-			alias = d.resolvePagePath(page, alias)
+			alias = d.resolvePagePath(page, aliasPath)
 
 			d.ctx.Logger.Debugf("write page alias [%s] -> %s", alias, page.Path)
 			if err := d.RenderTemplate(alias, tpl, map[string]any{
 				"url":          page.Permalink,
+				"page":         page,
 				"current_lang": page.Lang,
 			}, writer); err != nil {
 				return err
@@ -0,0 +1,351 @@
+package asciidoc
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bytesparadise/libasciidoc/pkg/configuration"
+	adocparser "github.com/bytesparadise/libasciidoc/pkg/parser"
+	"github.com/bytesparadise/libasciidoc/pkg/renderer"
+	"github.com/bytesparadise/libasciidoc/pkg/types"
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/content/parser"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// 使用注释作为摘要分隔符, 不影响asciidoc的渲染
+	ASCIIDOC_SUMMARY = regexp.MustCompile(`(?i)^\s*//\s*(snow:\s*more|<!--more-->)\s*$`)
+	// 没有设置source-highlighter时libasciidoc输出的代码块
+	ASCIIDOC_SOURCE = regexp.MustCompile(`(?s)<pre class="highlight"><code class="language-([^"]+)" data-lang="[^"]*">(.*?)</code></pre>`)
+)
+
+const parserName = "asciidoc"
+
+type Option struct {
+	parser.MarkupOption
+}
+
+// 版本信息和控制asciidoc输出的文档属性
+var builtinAttributes = []string{
+	"revnumber", "revdate", "revremark", "email",
+	types.AttrDocType, types.AttrSyntaxHighlighter, types.AttrChromaClassPrefix,
+	types.AttrIDPrefix, types.AttrIDSeparator, types.AttrNumbered, types.AttrSectionNumbering,
+	types.AttrTableOfContents, types.AttrTableOfContentsLevels, types.AttrTableOfContentsTitle,
+	types.AttrNoHeader, types.AttrNoFooter, types.AttrVersionLabel, types.AttrImagesDir,
+	types.AttrExperimental, types.AttrHardBreaks, types.AttrExampleCaption, types.AttrFigureCaption,
+	types.AttrTableCaption, types.AttrCautionCaption, types.AttrImportantCaption, types.AttrNoteCaption,
+	types.AttrTipCaption, types.AttrWarningCaption,
+	"icons", "sectanchors", "sectlinks", "showtitle", "stem", "xrefstyle",
+}
+
+type adocParser struct {
+	opt *Option
+}
+
+func (p *adocParser) highlightCodeBlock(source, lang string) string {
+	if lang == "text" {
+		lang = ""
+	}
+	return parser.HighlightCode(p.opt.MarkupOption, lang, source, nil)
+}
+
+func (p *adocParser) highlight(content string) string {
+	if p.opt.Style == "" || p.opt.Style == "none" {
+		return content
+	}
+	return ASCIIDOC_SOURCE.ReplaceAllStringFunc(content, func(s string) string {
+		match := ASCIIDOC_SOURCE.FindStringSubmatch(s)
+		return p.highlightCodeBlock(html.UnescapeString(match[2]), match[1])
+	})
+}
+
+// plainText 返回标题的纯文本
+func plainText(elements []any) string {
+	var b strings.Builder
+	for _, element := range elements {
+		switch e := element.(type) {
+		case *types.StringElement:
+			b.WriteString(e.Content)
+		case *types.QuotedText:
+			b.WriteString(plainText(e.Elements))
+		case *types.InlineLink:
+			b.WriteString(e.Attributes.GetAsStringWithDefault(types.AttrInlineLinkText, ""))
+		case *types.SpecialCharacter:
+			b.WriteString(e.Name)
+		case *types.Symbol:
+			b.WriteString(e.Name)
+		case string:
+			b.WriteString(e)
+		}
+	}
+	return b.String()
+}
+
+// headings 使用MarkupOption生成标题锚点, 显式设置的[[id]]和[#id]保持不变
+func (p *adocParser) headings(doc *types.Document, attrs types.Attributes) []*parser.Heading {
+	prefix := attrs.GetAsStringWithDefault(types.AttrIDPrefix, types.DefaultIDPrefix)
+	separator := attrs.GetAsStringWithDefault(types.AttrIDSeparator, types.DefaultIDSeparator)
+
+	ids := make(map[string]string)
+
+	var walk func([]any, []string) []*parser.Heading
+	walk = func(elements []any, parents []string) []*parser.Heading {
+		headings := make([]*parser.Heading, 0)
+		for _, element := range elements {
+			switch e := element.(type) {
+			case *types.Preamble:
+				headings = append(headings, walk(e.Elements, parents)...)
+			case *types.Section:
+				parts := append(slices.Clone(parents), strconv.Itoa(len(headings)+1))
+
+				title := plainText(e.Title)
+				id := e.GetID()
+				if generated, err := types.ReplaceNonAlphanumerics(e.Title, prefix, separator); err == nil && strings.HasPrefix(id, generated) {
+					if newID := p.opt.HeadingID(title, "heading-"+strings.Join(parts, ".")); newID != id {
+						ids[id] = newID
+						e.Attributes[types.AttrID] = newID
+						id = newID
+					}
+				}
+				headings = append(headings, &parser.Heading{
+					Id:       id,
+					Level:    e.Level,
+					Title:    html.EscapeString(title),
+					Children: walk(e.Elements, parts),
+				})
+			}
+		}
+		return headings
+	}
+	toc := walk(doc.BodyElements(), nil)
+
+	if len(ids) == 0 {
+		return toc
+	}
+	// 同步修改asciidoc目录和交叉引用中的锚点
+	var walkToc func([]*types.ToCSection)
+	walkToc = func(sections []*types.ToCSection) {
+		for _, section := range sections {
+			if id, ok := ids[section.ID]; ok {
+				section.ID = id
+			}
+			walkToc(section.Children)
+		}
+	}
+	if doc.TableOfContents != nil {
+		walkToc(doc.TableOfContents.Sections)
+	}
+	for oldID, newID := range ids {
+		if title, ok := doc.ElementReferences[oldID]; ok {
+			doc.ElementReferences[newID] = title
+		}
+	}
+	var walkRefs func(any)
+	walkRefs = func(element any) {
+		switch e := element.(type) {
+		case *types.InternalCrossReference:
+			if id, ok := e.ID.(string); ok && ids[id] != "" {
+				e.ID = ids[id]
+			}
+		case types.WithElements:
+			for _, child := range e.GetElements() {
+				walkRefs(child)
+			}
+		}
+	}
+	for _, element := range doc.Elements {
+		walkRefs(element)
+	}
+	return toc
+}
+
+// frontMatter 把文档属性转换为front matter
+func frontMatter(result *parser.Result, header *types.DocumentHeader) {
+	if header == nil {
+		return
+	}
+	if title := plainText(header.Title); title != "" {
+		result.FrontMatter["title"] = title
+	}
+	if authors := header.Authors(); len(authors) > 0 {
+		names := make([]any, 0, len(authors))
+		for _, author := range authors {
+			if author.DocumentAuthorFullName != nil {
+				names = append(names, author.FullName())
+			}
+		}
+		result.FrontMatter["authors"] = names
+	}
+	if revision := header.Revision(); revision != nil {
+		if revision.Revnumber != "" {
+			result.SetFrontMatter("revnumber", revision.Revnumber)
+		}
+		if revision.Revdate != "" {
+			result.SetFrontMatter("revdate", revision.Revdate)
+		}
+		if revision.Revremark != "" {
+			result.SetFrontMatter("revremark", revision.Revremark)
+		}
+	}
+	for _, element := range header.Elements {
+		attr, ok := element.(*types.AttributeDeclaration)
+		if !ok || attr.Name == types.AttrAuthors || attr.Name == types.AttrRevision {
+			continue
+		}
+		switch value := attr.Value.(type) {
+		case nil:
+			result.FrontMatter[strings.ToLower(attr.Name)] = true
+		case string:
+			result.SetFrontMatter(attr.Name, value)
+		case []any:
+			result.SetFrontMatter(attr.Name, plainText(value))
+		default:
+			result.SetFrontMatter(attr.Name, fmt.Sprint(value))
+		}
+	}
+	if _, ok := result.FrontMatter["date"]; !ok {
+		if revdate, ok := result.FrontMatter["revdate"].(string); ok {
+			result.FrontMatter["date"] = revdate
+		}
+	}
+}
+
+func (p *adocParser) convert(data []byte) (*types.Document, []*parser.Heading, string, error) {
+	// libasciidoc会从当前工作目录读取include::的文件, 只允许使用ParseFS展开后的内容
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if match := ASCIIDOC_INCLUDE.FindSubmatch(bytes.TrimRight(line, "\r")); match != nil {
+			return nil, nil, "", fmt.Errorf("include::%s[] is not supported without content fs", match[1])
+		}
+	}
+	config := configuration.NewConfiguration()
+
+	source, err := adocparser.Preprocess(bytes.NewReader(data), config)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	doc, err := adocparser.ParseDocument(strings.NewReader(source), config)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	attrs := types.Attributes{}
+	if header, _ := doc.Header(); header != nil {
+		for _, element := range header.Elements {
+			if attr, ok := element.(*types.AttributeDeclaration); ok {
+				attrs[attr.Name] = attr.Value
+			}
+		}
+	}
+
+	toc := p.headings(doc, attrs)
+	if !p.opt.ShowToc {
+		toc = nil
+	}
+
+	var w strings.Builder
+	if _, err := renderer.Render(doc, config, &w); err != nil {
+		return nil, nil, "", err
+	}
+	return doc, toc, p.highlight(w.String()), nil
+}
+
+func (p *adocParser) Parse(r io.Reader) (*parser.Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("asciidoc parser read: %w", err)
+	}
+
+	var summary []byte
+
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	for i, line := range lines {
+		if ASCIIDOC_SUMMARY.Match(bytes.TrimRight(line, "\r\n")) {
+			summary = bytes.Join(lines[:i], nil)
+			break
+		}
+	}
+
+	result := &parser.Result{
+		FrontMatter: make(map[string]any),
+	}
+
+	doc, toc, content, err := p.convert(data)
+	if err != nil {
+		return nil, err
+	}
+	header, _ := doc.Header()
+	frontMatter(result, header)
+
+	result.Toc = toc
+	result.Content = content
+	result.RawContent = string(data)
+	result.RawSummary = string(summary)
+
+	if len(summary) > 0 {
+		_, _, content, err := p.convert(summary)
+		if err != nil {
+			return nil, err
+		}
+		result.Summary = content
+	}
+	return result, nil
+}
+
+// ParseFS 使用内容目录展开include::后再解析, 路径相对于当前文件
+func (p *adocParser) ParseFS(fsys fs.FS, file string) (*parser.Result, error) {
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+	inc := newAdocInclude(fsys)
+	data, err = inc.expand(file, data, []string{file})
+	if err != nil {
+		return nil, &parser.IncludeError{Err: err}
+	}
+
+	result, err := p.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	result.Includes = inc.Includes()
+	return result, nil
+}
+
+func (p *adocParser) SupportedExtensions() []string {
+	return []string{".adoc", ".asciidoc"}
+}
+
+// FrontMatterKeys 版本信息和asciidoc内置的文档属性, 其它自定义属性作为普通的front matter
+func (p *adocParser) FrontMatterKeys(string) []string {
+	return builtinAttributes
+}
+
+func New(opt *Option) *adocParser {
+	return &adocParser{opt: opt}
+}
+
+func NewWithContext(ctx *core.Context) *adocParser {
+	// libasciidoc使用logrus默认的logger输出每个文档的解析过程, 只在启用解析器时调整
+	if logrus.GetLevel() > logrus.WarnLevel {
+		logrus.SetLevel(logrus.WarnLevel)
+	}
+
+	opt := &Option{
+		MarkupOption: parser.NewMarkupOption(ctx, parserName),
+	}
+	return New(opt)
+}
+
+func init() {
+	parser.Register(parserName, func(ctx *core.Context) parser.MarkupParser {
+		return NewWithContext(ctx)
+	})
+}
@@ -0,0 +1,156 @@
+package asciidoc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/honmaple/snow/internal/site/content/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseAdoc(t *testing.T, text string, opt *Option) *parser.Result {
+	t.Helper()
+
+	if opt == nil {
+		opt = &Option{}
+	}
+	result, err := New(opt).Parse(strings.NewReader(text))
+	require.NoError(t, err)
+	return result
+}
+
+func TestMeta(t *testing.T) {
+	result := parseAdoc(t, `= Hello *World*
+Snow Author <snow@example.com>; Other Author
+v1.0, 2023-02-24: first release
+:description: some description
+:tags: [snow, hello]
+:draft:
+
+content
+`, nil)
+
+	assert.Equal(t, "Hello World", result.FrontMatter["title"])
+	assert.Equal(t, []any{"Snow Author", "Other Author"}, result.FrontMatter["authors"])
+	assert.Equal(t, "1.0", result.FrontMatter["revnumber"])
+	assert.Equal(t, "2023-02-24", result.FrontMatter["revdate"])
+	assert.Equal(t, "2023-02-24", result.FrontMatter["date"])
+	assert.Equal(t, "first release", result.FrontMatter["revremark"])
+	assert.Equal(t, "some description", result.FrontMatter["description"])
+	assert.Equal(t, []string{"snow", "hello"}, result.FrontMatter["tags"])
+	assert.Equal(t, true, result.FrontMatter["draft"])
+	assert.Contains(t, result.Content, "<p>content</p>")
+}
+
+func TestDateAttribute(t *testing.T) {
+	result := parseAdoc(t, `= Title
+v1.0, 2023-02-24
+:date: 2024-01-01
+
+content
+`, nil)
+
+	assert.Equal(t, "2024-01-01", result.FrontMatter["date"])
+}
+
+func TestToc(t *testing.T) {
+	text := `= Title
+
+== Section One
+
+see <<_section_two>>
+
+=== Sub Section
+
+[#custom]
+== Section Two
+`
+	result := parseAdoc(t, text, &Option{MarkupOption: parser.MarkupOption{ShowToc: true}})
+
+	require.Len(t, result.Toc, 2)
+	assert.Equal(t, "section-one", result.Toc[0].Id)
+	assert.Equal(t, "Section One", result.Toc[0].Title)
+	assert.Equal(t, 1, result.Toc[0].Level)
+	require.Len(t, result.Toc[0].Children, 1)
+	assert.Equal(t, "sub-section", result.Toc[0].Children[0].Id)
+	assert.Equal(t, "custom", result.Toc[1].Id)
+
+	assert.Contains(t, result.Content, `id="section-one"`)
+	assert.Contains(t, result.Content, `id="sub-section"`)
+	assert.Contains(t, result.Content, `id="custom"`)
+
+	result = parseAdoc(t, text, &Option{MarkupOption: parser.MarkupOption{ShowToc: true, TocId: "index"}})
+	require.Len(t, result.Toc, 2)
+	assert.Equal(t, "heading-1", result.Toc[0].Id)
+	assert.Equal(t, "heading-1.1", result.Toc[0].Children[0].Id)
+
+	result = parseAdoc(t, text, nil)
+	assert.Empty(t, result.Toc)
+	assert.Contains(t, result.Content, `id="section-one"`)
+}
+
+func TestCrossReference(t *testing.T) {
+	result := parseAdoc(t, `= Title
+
+== Section One
+
+see <<_section_two>>
+
+== Section Two
+`, nil)
+
+	assert.Contains(t, result.Content, `href="#section-two"`)
+	assert.NotContains(t, result.Content, "_section_two")
+}
+
+func TestSummary(t *testing.T) {
+	result := parseAdoc(t, `= Title
+
+summary
+
+// snow: more
+content
+`, nil)
+
+	assert.Equal(t, "= Title\n\nsummary\n\n", result.RawSummary)
+	assert.Contains(t, result.Summary, "summary")
+	assert.NotContains(t, result.Summary, "content")
+	assert.Contains(t, result.Content, "content")
+
+	result = parseAdoc(t, `summary
+// <!--more-->
+content
+`, nil)
+	assert.Equal(t, "summary\n", result.RawSummary)
+
+	result = parseAdoc(t, "content\n", nil)
+	assert.Empty(t, result.Summary)
+}
+
+func TestHighlight(t *testing.T) {
+	text := `[source,go]
+----
+func main() {}
+----
+`
+	result := parseAdoc(t, text, &Option{MarkupOption: parser.MarkupOption{Style: "monokai"}})
+	assert.Contains(t, result.Content, `<pre style="`)
+	assert.Contains(t, result.Content, "func")
+
+	result = parseAdoc(t, text, &Option{MarkupOption: parser.MarkupOption{Style: "none"}})
+	assert.Contains(t, result.Content, `<code class="language-go" data-lang="go">func main() {}</code>`)
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestReaderErrorIsReturned(t *testing.T) {
+	_, err := New(&Option{}).Parse(errReader{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "read failed")
+}
@@ -0,0 +1,237 @@
+package asciidoc
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	stdpath "path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	ASCIIDOC_INCLUDE = regexp.MustCompile(`^include::([^\[]*)\[(.*)\]\s*$`)
+	ASCIIDOC_TAG     = regexp.MustCompile(`\b(tag|end)::(\S+?)\[\]`)
+	ASCIIDOC_SECTION = regexp.MustCompile(`^(=+)(\s.*)$`)
+)
+
+// includeAttributes 解析include::file[...]中的属性, 双引号中的逗号不会被分割
+func includeAttributes(s string) map[string]string {
+	var (
+		attrs  = make(map[string]string)
+		field  strings.Builder
+		quoted bool
+	)
+	add := func() {
+		k, v, _ := strings.Cut(field.String(), "=")
+		if k = strings.TrimSpace(k); k != "" {
+			attrs[k] = strings.TrimSpace(v)
+		}
+		field.Reset()
+	}
+	for _, c := range s {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			add()
+		default:
+			field.WriteRune(c)
+		}
+	}
+	add()
+	return attrs
+}
+
+// includeLines 按照lines属性选择行, 例如1..3;5, 结束的行为-1或者为空时表示到文件结尾
+func includeLines(lines []string, value string) ([]string, error) {
+	results := make([]string, 0)
+	for _, r := range strings.FieldsFunc(value, func(c rune) bool { return c == ';' || c == ',' }) {
+		from, to, found := strings.Cut(strings.TrimSpace(r), "..")
+		start, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil || start < 1 {
+			return nil, fmt.Errorf("invalid lines %q", value)
+		}
+		end := start
+		if found {
+			to = strings.TrimSpace(to)
+			if to == "" || to == "-1" {
+				end = len(lines)
+			} else if end, err = strconv.Atoi(to); err != nil || end < start {
+				return nil, fmt.Errorf("invalid lines %q", value)
+			}
+		}
+		if start > len(lines) {
+			return nil, fmt.Errorf("lines out of range, file has %d lines", len(lines))
+		}
+		results = append(results, lines[start-1:min(end, len(lines))]...)
+	}
+	return results, nil
+}
+
+// includeTags 选择tag::name[]和end::name[]之间的行, 标记所在的行不会输出
+func includeTags(lines []string, value string) ([]string, error) {
+	tags := strings.FieldsFunc(value, func(c rune) bool { return c == ';' || c == ',' })
+
+	var (
+		results = make([]string, 0)
+		found   = make(map[string]bool)
+		active  = make([]string, 0)
+	)
+	for _, line := range lines {
+		if match := ASCIIDOC_TAG.FindStringSubmatch(line); match != nil {
+			if match[1] == "tag" {
+				active = append(active, match[2])
+				found[match[2]] = true
+			} else if i := slices.Index(active, match[2]); i >= 0 {
+				active = slices.Delete(active, i, i+1)
+			}
+			continue
+		}
+		for _, tag := range active {
+			if slices.Contains(tags, tag) {
+				results = append(results, line)
+				break
+			}
+		}
+	}
+	for _, tag := range tags {
+		if !found[tag] {
+			return nil, fmt.Errorf("tag %q not found", tag)
+		}
+	}
+	return results, nil
+}
+
+// levelOffset 调整被引用文件中章节标题的级别, 代码块中的内容保持不变
+func levelOffset(lines []string, value string) ([]string, error) {
+	offset, err := strconv.Atoi(strings.TrimPrefix(value, "+"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid leveloffset %q", value)
+	}
+	delimiter := ""
+	for i, line := range lines {
+		text := strings.TrimRight(line, "\r\n")
+		switch {
+		case delimiter != "":
+			if text == delimiter {
+				delimiter = ""
+			}
+		case text == "----" || text == "...." || text == "```" || text == "++++" || text == "////":
+			delimiter = text
+		default:
+			if match := ASCIIDOC_SECTION.FindStringSubmatch(text); match != nil {
+				level := max(len(match[1])+offset, 1)
+				lines[i] = strings.Repeat("=", level) + match[2] + line[len(text):]
+			}
+		}
+	}
+	return lines, nil
+}
+
+// adocInclude 使用内容目录展开include::指令, 路径相对于当前的文件, /开头的路径相对于内容目录
+type adocInclude struct {
+	fsys  fs.FS
+	files map[string]bool
+}
+
+func (inc *adocInclude) include(current string, target string, value string, stack []string) (string, error) {
+	if strings.Contains(target, "://") {
+		return "", fmt.Errorf("%s: remote include %q is not supported", current, target)
+	}
+	if strings.Contains(target, "{") {
+		return "", fmt.Errorf("%s: attribute reference in include %q is not supported", current, target)
+	}
+
+	name := stdpath.Join(stdpath.Dir(current), target)
+	if strings.HasPrefix(target, "/") {
+		name = strings.TrimPrefix(stdpath.Clean(target), "/")
+	}
+	if !fs.ValidPath(name) {
+		return "", fmt.Errorf("%s: invalid include file %q", current, target)
+	}
+	if slices.Contains(stack, name) {
+		return "", fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), name)
+	}
+
+	attrs := includeAttributes(value)
+	data, err := fs.ReadFile(inc.fsys, name)
+	if err != nil {
+		// opts=optional时忽略不存在的文件
+		if slices.Contains(strings.Split(attrs["opts"], ";"), "optional") {
+			return "", nil
+		}
+		return "", fmt.Errorf("%s: include %w", current, err)
+	}
+	inc.files[name] = true
+
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if v, ok := attrs["lines"]; ok {
+		if lines, err = includeLines(lines, v); err != nil {
+			return "", fmt.Errorf("%s: include %s: %w", current, target, err)
+		}
+	} else if v, ok := attrs["tags"]; ok {
+		if lines, err = includeTags(lines, v); err != nil {
+			return "", fmt.Errorf("%s: include %s: %w", current, target, err)
+		}
+	} else if v, ok := attrs["tag"]; ok {
+		if lines, err = includeTags(lines, v); err != nil {
+			return "", fmt.Errorf("%s: include %s: %w", current, target, err)
+		}
+	}
+
+	ext := stdpath.Ext(name)
+	if ext != ".adoc" && ext != ".asciidoc" {
+		return strings.Join(lines, ""), nil
+	}
+	if v, ok := attrs["leveloffset"]; ok {
+		if lines, err = levelOffset(lines, v); err != nil {
+			return "", fmt.Errorf("%s: include %s: %w", current, target, err)
+		}
+	}
+	expanded, err := inc.expand(name, []byte(strings.Join(lines, "")), append(stack, name))
+	if err != nil {
+		return "", err
+	}
+	return string(expanded), nil
+}
+
+// expand 展开内容中的include::, 被引用的asciidoc文件会继续展开
+func (inc *adocInclude) expand(current string, data []byte, stack []string) ([]byte, error) {
+	var b bytes.Buffer
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		match := ASCIIDOC_INCLUDE.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+		if match == nil {
+			b.WriteString(line)
+			continue
+		}
+		text, err := inc.include(current, match[1], match[2], stack)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(text)
+		if text != "" && !strings.HasSuffix(text, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// Includes 返回展开时读取的文件
+func (inc *adocInclude) Includes() []string {
+	files := make([]string, 0, len(inc.files))
+	for file := range inc.files {
+		files = append(files, file)
+	}
+	slices.Sort(files)
+	return files
+}
+
+func newAdocInclude(fsys fs.FS) *adocInclude {
+	return &adocInclude{fsys: fsys, files: make(map[string]bool)}
+}
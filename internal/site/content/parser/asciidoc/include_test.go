@@ -0,0 +1,77 @@
+package asciidoc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/honmaple/snow/internal/site/content/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncludeFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"posts/index.adoc": &fstest.MapFile{Data: []byte(`= Include
+
+include::_chapter.adoc[leveloffset=+1]
+
+[source,go]
+----
+include::code/main.go[tag=main]
+----
+
+[source,go]
+----
+include::code/main.go[lines="1..2"]
+----
+
+include::_missing.adoc[opts=optional]
+`)},
+		"posts/_chapter.adoc": &fstest.MapFile{Data: []byte("= Chapter\n\ninclude::_nested.adoc[]\n")},
+		"posts/_nested.adoc":  &fstest.MapFile{Data: []byte("nested paragraph\n")},
+		"posts/code/main.go":  &fstest.MapFile{Data: []byte("package main\n\n// tag::main[]\nfunc main() {}\n// end::main[]\n")},
+	}
+
+	result, err := New(&Option{}).ParseFS(fsys, "posts/index.adoc")
+	require.NoError(t, err)
+	assert.Contains(t, result.Content, "<h2 id=\"chapter\">Chapter</h2>")
+	assert.Contains(t, result.Content, "nested paragraph")
+	assert.Contains(t, result.Content, "func main() {}")
+	assert.NotContains(t, result.Content, "tag::main")
+	assert.Contains(t, result.Content, "package main</code>")
+	assert.Equal(t, []string{"posts/_chapter.adoc", "posts/_nested.adoc", "posts/code/main.go"}, result.Includes)
+}
+
+func TestIncludeErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.adoc":       &fstest.MapFile{Data: []byte("include::b.adoc[]\n")},
+		"b.adoc":       &fstest.MapFile{Data: []byte("include::a.adoc[]\n")},
+		"outside.adoc": &fstest.MapFile{Data: []byte("include::../../etc/passwd[]\n")},
+		"missing.adoc": &fstest.MapFile{Data: []byte("include::missing.go[]\n")},
+		"tag.adoc":     &fstest.MapFile{Data: []byte("include::code.go[tag=missing]\n")},
+		"remote.adoc":  &fstest.MapFile{Data: []byte("include::https://example.com/a.adoc[]\n")},
+		"code.go":      &fstest.MapFile{Data: []byte("package main\n")},
+	}
+
+	for file, msg := range map[string]string{
+		"a.adoc":       "include cycle: a.adoc -> b.adoc -> a.adoc",
+		"outside.adoc": `invalid include file "../../etc/passwd"`,
+		"missing.adoc": "include open missing.go",
+		"tag.adoc":     `tag "missing" not found`,
+		"remote.adoc":  "remote include",
+	} {
+		_, err := New(&Option{}).ParseFS(fsys, file)
+		require.Error(t, err, file)
+		assert.Contains(t, err.Error(), msg, file)
+
+		var includeErr *parser.IncludeError
+		assert.True(t, errors.As(err, &includeErr), file)
+	}
+
+	// 没有内容目录时不会从当前工作目录读取文件
+	_, err := New(&Option{}).Parse(strings.NewReader("include::/etc/passwd[]\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported")
+}
@@ -0,0 +1,254 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// 缓存格式变化时需要修改版本号, 使旧的缓存失效
+const cacheVersion = "4"
+
+const DefaultCacheDir = ".snow-cache"
+
+// cacheEntry 缓存的解析结果, 以及解析时读取的其它文件的hash
+type cacheEntry struct {
+	Result   *Result
+	Includes map[string]string
+}
+
+type Cache struct {
+	Parser
+
+	ctx    *core.Context
+	dir    string
+	salt   string
+	used   sync.Map
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func (c *Cache) key(ext string, lang string, defaults []byte, data []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.salt))
+	hash.Write([]byte{0})
+	hash.Write([]byte(ext))
+	hash.Write([]byte{0})
+	hash.Write([]byte(lang))
+	hash.Write([]byte{0})
+	hash.Write(defaults)
+	hash.Write([]byte{0})
+	hash.Write(data)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (c *Cache) file(key string) string {
+	return filepath.Join(c.dir, "content", key[:2], key+".gob")
+}
+
+func fileHash(fsys fs.FS, file string) (string, error) {
+	buf, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(buf)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+func (c *Cache) load(fsys fs.FS, key string) (*Result, bool) {
+	buf, err := os.ReadFile(c.file(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := gob.NewDecoder(bytes.NewReader(buf)).Decode(&entry); err != nil || entry.Result == nil {
+		return nil, false
+	}
+	// 引用的文件变化时需要重新解析
+	for file, hash := range entry.Includes {
+		if h, err := fileHash(fsys, file); err != nil || h != hash {
+			return nil, false
+		}
+	}
+	return entry.Result, true
+}
+
+func (c *Cache) store(fsys fs.FS, key string, result *Result) error {
+	entry := cacheEntry{
+		Result:   result,
+		Includes: make(map[string]string),
+	}
+	for _, file := range result.Includes {
+		hash, err := fileHash(fsys, file)
+		if err != nil {
+			return err
+		}
+		entry.Includes[file] = hash
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+		return err
+	}
+
+	file := c.file(key)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	// 先写入临时文件再重命名, 避免并发解析时读到不完整的缓存
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+func (c *Cache) Parse(fsys fs.FS, file string) (*Result, error) {
+	return c.ParseDefaults(fsys, file, nil)
+}
+
+// ParseDefaults cascade中的解析配置不同时使用不同的缓存
+func (c *Cache) ParseDefaults(fsys fs.FS, file string, defaults map[string]any) (*Result, error) {
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+	var buf []byte
+	if len(defaults) > 0 {
+		if buf, err = json.Marshal(defaults); err != nil {
+			return nil, err
+		}
+	}
+	key := c.key(stdpath.Ext(file), fileLang(file), buf, data)
+	c.used.Store(key, true)
+
+	if result, ok := c.load(fsys, key); ok {
+		c.hits.Add(1)
+		return result, nil
+	}
+	c.misses.Add(1)
+
+	var result *Result
+	if p, ok := c.Parser.(DefaultsParser); ok {
+		result, err = p.ParseDefaults(fsys, file, defaults)
+	} else {
+		result, err = c.Parser.Parse(fsys, file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := c.store(fsys, key, result); err != nil {
+		c.ctx.Logger.Debugf("cache parsed content %s err: %s", file, err.Error())
+	}
+	return result, nil
+}
+
+func (c *Cache) FrontMatterKeys(file string) []string {
+	if p, ok := c.Parser.(FrontMatterKeysParser); ok {
+		return p.FrontMatterKeys(file)
+	}
+	return nil
+}
+
+// Stats 返回本次构建缓存命中和未命中的数量
+func (c *Cache) Stats() (int64, int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+// Prune 删除本次构建没有使用到的缓存文件
+func (c *Cache) Prune() error {
+	root := filepath.Join(c.dir, "content")
+	if _, err := os.Stat(root); err != nil {
+		return nil
+	}
+	return filepath.WalkDir(root, func(path string, info fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		name := info.Name()
+		if key, ok := strings.CutSuffix(name, ".gob"); ok {
+			if _, used := c.used.Load(key); used {
+				return nil
+			}
+		}
+		return os.Remove(path)
+	})
+}
+
+func (c *Cache) Clear() error {
+	return ClearCache(c.dir)
+}
+
+func ClearCache(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	return os.RemoveAll(filepath.Join(dir, "content"))
+}
+
+func cacheSalt(ctx *core.Context) string {
+	// 解析结果只依赖于文件内容, 各个语言的markups配置和存在的渲染模版
+	markups := map[string]any{
+		"": ctx.Config.Get("markups"),
+	}
+	for lang, lctx := range ctx.OtherLanguages {
+		markups[lang] = lctx.Config.Get("markups")
+	}
+	buf, err := json.Marshal(markups)
+	if err != nil {
+		buf = []byte(fmt.Sprintf("%v", markups))
+	}
+	hooks := slices.Sorted(maps.Keys(RenderHookTemplates(ctx)))
+	return cacheVersion + ":" + string(buf) + ":" + strings.Join(hooks, ",")
+}
+
+func NewCache(ctx *core.Context, p Parser, dir string) *Cache {
+	if dir == "" {
+		dir = DefaultCacheDir
+	}
+	return &Cache{
+		Parser: p,
+		ctx:    ctx,
+		dir:    dir,
+		salt:   cacheSalt(ctx),
+	}
+}
+
+func init() {
+	// front matter中可能出现的类型, gob需要提前注册
+	gob.Register(map[string]any{})
+	gob.Register([]any{})
+	gob.Register([]string{})
+	gob.Register(time.Time{})
+	gob.Register(toml.LocalDate{})
+	gob.Register(toml.LocalTime{})
+	gob.Register(toml.LocalDateTime{})
+}
@@ -0,0 +1,194 @@
+package parser
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countParser struct {
+	count int
+}
+
+func (p *countParser) Parse(fsys fs.FS, file string) (*Result, error) {
+	p.count++
+
+	buf, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		FrontMatter: map[string]any{
+			"title": "hello",
+			"date":  time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			"tags":  []any{"go", "snow"},
+		},
+		Toc: []*Heading{
+			{Id: "h1", Level: 1, Title: "H1", Children: []*Heading{{Id: "h2", Level: 2, Title: "H2"}}},
+		},
+		Content: string(buf),
+	}, nil
+}
+
+func (p *countParser) SupportedExtensions() []string {
+	return []string{".md"}
+}
+
+func newCacheTestContext(t *testing.T) *core.Context {
+	t.Helper()
+
+	ctx, err := core.NewContext(core.DefaultConfig())
+	require.NoError(t, err)
+	return ctx
+}
+
+func TestCacheSkipsUnchangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	fsys := fstest.MapFS{
+		"hello.md": &fstest.MapFile{Data: []byte("hello")},
+	}
+
+	p := &countParser{}
+	cache := NewCache(newCacheTestContext(t), p, dir)
+
+	result, err := cache.Parse(fsys, "hello.md")
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.count)
+
+	// 新的缓存实例模拟再次构建
+	cache = NewCache(newCacheTestContext(t), p, dir)
+	cached, err := cache.Parse(fsys, "hello.md")
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.count)
+	assert.Equal(t, result, cached)
+
+	hits, misses := cache.Stats()
+	assert.Equal(t, int64(1), hits)
+	assert.Equal(t, int64(0), misses)
+}
+
+func TestCacheInvalidatesChangedContentAndConfig(t *testing.T) {
+	dir := t.TempDir()
+	fsys := fstest.MapFS{
+		"hello.md": &fstest.MapFile{Data: []byte("hello")},
+	}
+
+	p := &countParser{}
+	ctx := newCacheTestContext(t)
+
+	_, err := NewCache(ctx, p, dir).Parse(fsys, "hello.md")
+	require.NoError(t, err)
+
+	fsys["hello.md"] = &fstest.MapFile{Data: []byte("hello world")}
+	result, err := NewCache(ctx, p, dir).Parse(fsys, "hello.md")
+	require.NoError(t, err)
+	assert.Equal(t, 2, p.count)
+	assert.Equal(t, "hello world", result.Content)
+
+	ctx.Config.Set("markups._default.style", "dracula")
+	_, err = NewCache(ctx, p, dir).Parse(fsys, "hello.md")
+	require.NoError(t, err)
+	assert.Equal(t, 3, p.count)
+}
+
+func TestCacheSeparatesLanguages(t *testing.T) {
+	dir := t.TempDir()
+	fsys := fstest.MapFS{
+		"hello.md":    &fstest.MapFile{Data: []byte("hello")},
+		"hello.zh.md": &fstest.MapFile{Data: []byte("hello")},
+	}
+
+	p := &countParser{}
+	cache := NewCache(newCacheTestContext(t), p, dir)
+
+	_, err := cache.Parse(fsys, "hello.md")
+	require.NoError(t, err)
+	// 不同语言可能使用不同的markups配置
+	_, err = cache.Parse(fsys, "hello.zh.md")
+	require.NoError(t, err)
+	assert.Equal(t, 2, p.count)
+	assert.Equal(t, "zh", fileLang("hello.zh.md"))
+	assert.Equal(t, "", fileLang("posts/hello.md"))
+}
+
+func TestCachePruneAndClear(t *testing.T) {
+	dir := t.TempDir()
+	fsys := fstest.MapFS{
+		"a.md": &fstest.MapFile{Data: []byte("a")},
+		"b.md": &fstest.MapFile{Data: []byte("b")},
+	}
+
+	p := &countParser{}
+	ctx := newCacheTestContext(t)
+
+	cache := NewCache(ctx, p, dir)
+	_, err := cache.Parse(fsys, "a.md")
+	require.NoError(t, err)
+	_, err = cache.Parse(fsys, "b.md")
+	require.NoError(t, err)
+
+	cache = NewCache(ctx, p, dir)
+	_, err = cache.Parse(fsys, "a.md")
+	require.NoError(t, err)
+	require.NoError(t, cache.Prune())
+
+	_, err = NewCache(ctx, p, dir).Parse(fsys, "b.md")
+	require.NoError(t, err)
+	assert.Equal(t, 3, p.count)
+
+	require.NoError(t, ClearCache(dir))
+	_, err = NewCache(ctx, p, dir).Parse(fsys, "a.md")
+	require.NoError(t, err)
+	assert.Equal(t, 4, p.count)
+}
+
+type includeParser struct {
+	count int
+}
+
+func (p *includeParser) Parse(fsys fs.FS, file string) (*Result, error) {
+	p.count++
+
+	buf, err := fs.ReadFile(fsys, "common.org")
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Content: string(buf), Includes: []string{"common.org"}}, nil
+}
+
+func (p *includeParser) SupportedExtensions() []string {
+	return []string{".org"}
+}
+
+func TestCacheInvalidatesChangedIncludes(t *testing.T) {
+	dir := t.TempDir()
+	fsys := fstest.MapFS{
+		"hello.org":  &fstest.MapFile{Data: []byte("#+INCLUDE: \"common.org\"")},
+		"common.org": &fstest.MapFile{Data: []byte("v1")},
+	}
+
+	p := &includeParser{}
+	_, err := NewCache(newCacheTestContext(t), p, dir).Parse(fsys, "hello.org")
+	require.NoError(t, err)
+
+	result, err := NewCache(newCacheTestContext(t), p, dir).Parse(fsys, "hello.org")
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.count)
+	assert.Equal(t, "v1", result.Content)
+
+	fsys["common.org"] = &fstest.MapFile{Data: []byte("v2")}
+	result, err = NewCache(newCacheTestContext(t), p, dir).Parse(fsys, "hello.org")
+	require.NoError(t, err)
+	assert.Equal(t, 2, p.count)
+	assert.Equal(t, "v2", result.Content)
+
+	delete(fsys, "common.org")
+	_, err = NewCache(newCacheTestContext(t), p, dir).Parse(fsys, "hello.org")
+	require.Error(t, err)
+	assert.Equal(t, 3, p.count)
+}
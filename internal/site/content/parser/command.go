@@ -0,0 +1,204 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/spf13/cast"
+)
+
+var COMMAND_MORE = regexp.MustCompile(`(?i)<!--more-->`)
+
+const (
+	CommandOutputHTML = "html"
+	CommandOutputJSON = "json"
+
+	defaultCommandTimeout = 30 * time.Second
+)
+
+type (
+	CommandOption struct {
+		Command     []string
+		Extensions  []string
+		Output      string
+		Timeout     time.Duration
+		Concurrency int
+	}
+	// commandHeading 外部命令输出的目录
+	commandHeading struct {
+		Id       string            `json:"id"`
+		Level    int               `json:"level"`
+		Title    string            `json:"title"`
+		Children []*commandHeading `json:"children"`
+	}
+	// commandEnvelope 外部命令输出的json格式
+	commandEnvelope struct {
+		FrontMatter map[string]any    `json:"front_matter"`
+		Toc         []*commandHeading `json:"toc"`
+		Summary     string            `json:"summary"`
+		Content     string            `json:"content"`
+	}
+)
+
+func (h *commandHeading) heading() *Heading {
+	heading := &Heading{
+		Id:       h.Id,
+		Level:    h.Level,
+		Title:    h.Title,
+		Children: make([]*Heading, 0, len(h.Children)),
+	}
+	for _, child := range h.Children {
+		heading.Children = append(heading.Children, child.heading())
+	}
+	return heading
+}
+
+// commandParser 使用外部命令解析内容, 内容通过stdin传入, 从stdout读取html或者json
+type commandParser struct {
+	name string
+	opt  *CommandOption
+	// 限制同时运行的命令数量
+	sem chan struct{}
+}
+
+func (p *commandParser) run(data []byte, lang string) ([]byte, error) {
+	p.sem <- struct{}{}
+	defer func() { <-p.sem }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.opt.Timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, p.opt.Command[0], p.opt.Command[1:]...)
+	cmd.Env = append(os.Environ(), "SNOW_LANG="+lang)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%s command timed out after %s", p.name, p.opt.Timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s command: %w: %s", p.name, err, msg)
+		}
+		return nil, fmt.Errorf("%s command: %w", p.name, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+func (p *commandParser) Parse(r io.Reader) (*Result, error) {
+	return p.ParseLang(r, "")
+}
+
+func (p *commandParser) ParseLang(r io.Reader, lang string) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s parser read: %w", p.name, err)
+	}
+	fm, body, err := SplitFrontMatter(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", p.name, err)
+	}
+
+	out, err := p.run(body, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		FrontMatter: fm,
+		RawContent:  string(body),
+	}
+	if p.opt.Output != CommandOutputJSON {
+		result.Content = string(out)
+		if loc := COMMAND_MORE.FindIndex(out); loc != nil {
+			result.Summary = string(out[:loc[0]])
+		}
+		return result, nil
+	}
+
+	var envelope commandEnvelope
+	if err := json.Unmarshal(out, &envelope); err != nil {
+		return nil, fmt.Errorf("%s command output: %w", p.name, err)
+	}
+	// 文件中的front matter优先
+	for k, v := range envelope.FrontMatter {
+		if _, ok := result.FrontMatter[strings.ToLower(k)]; !ok {
+			result.FrontMatter[strings.ToLower(k)] = v
+		}
+	}
+	for _, h := range envelope.Toc {
+		result.Toc = append(result.Toc, h.heading())
+	}
+	result.Summary = envelope.Summary
+	result.Content = envelope.Content
+	return result, nil
+}
+
+func (p *commandParser) SupportedExtensions() []string {
+	return p.opt.Extensions
+}
+
+func NewCommandParser(name string, opt *CommandOption) MarkupParser {
+	if opt.Timeout <= 0 {
+		opt.Timeout = defaultCommandTimeout
+	}
+	if opt.Concurrency <= 0 {
+		opt.Concurrency = runtime.NumCPU()
+	}
+	return &commandParser{
+		name: name,
+		opt:  opt,
+		sem:  make(chan struct{}, opt.Concurrency),
+	}
+}
+
+// newCommandParsers 返回markups中配置了command的解析器, 与内置解析器同名的配置会被忽略
+func newCommandParsers(ctx *core.Context) map[string]MarkupParser {
+	parsers := make(map[string]MarkupParser)
+	for name := range ctx.Config.GetStringMap("markups") {
+		if _, ok := factories[name]; ok || name == "_default" {
+			continue
+		}
+		key := "markups." + name
+		if ctx.Config.IsSet(key+".enabled") && !ctx.Config.GetBool(key+".enabled") {
+			continue
+		}
+		command := cast.ToStringSlice(ctx.Config.Get(key + ".command"))
+		if len(command) == 0 {
+			continue
+		}
+
+		exts := make([]string, 0)
+		for _, ext := range ctx.Config.GetStringSlice(key + ".extensions") {
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			exts = append(exts, ext)
+		}
+		if len(exts) == 0 {
+			ctx.Logger.Warnf("markup %s has command but no extensions", name)
+			continue
+		}
+		parsers[name] = NewCommandParser(name, &CommandOption{
+			Command:     command,
+			Extensions:  exts,
+			Output:      ctx.Config.GetString(key + ".output"),
+			Timeout:     ctx.Config.GetDuration(key + ".timeout"),
+			Concurrency: ctx.Config.GetInt(key + ".concurrency"),
+		})
+	}
+	return parsers
+}
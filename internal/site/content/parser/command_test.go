@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newShellParser(t *testing.T, script string, output string) MarkupParser {
+	t.Helper()
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	return NewCommandParser("test", &CommandOption{
+		Command:    []string{"sh", "-c", script},
+		Extensions: []string{".rst"},
+		Output:     output,
+		Timeout:    5 * time.Second,
+	})
+}
+
+func TestCommandParserHTML(t *testing.T) {
+	p := newShellParser(t, `printf '<p>'; tr -d '\n'; printf '</p>\n<!--more-->\n<p>more</p>'`, CommandOutputHTML)
+
+	result, err := p.Parse(strings.NewReader("---\ntitle: Hello\ntags: [a, b]\n---\nbody\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hello", result.FrontMatter["title"])
+	assert.Equal(t, []any{"a", "b"}, result.FrontMatter["tags"])
+	assert.Equal(t, "body\n", result.RawContent)
+	assert.Equal(t, "<p>body</p>\n<!--more-->\n<p>more</p>", result.Content)
+	assert.Equal(t, "<p>body</p>\n", result.Summary)
+
+	result, err = p.Parse(strings.NewReader("+++\ntitle = \"Toml\"\n+++\nbody"))
+	require.NoError(t, err)
+	assert.Equal(t, "Toml", result.FrontMatter["title"])
+	assert.Equal(t, "body", result.RawContent)
+}
+
+func TestCommandParserJSON(t *testing.T) {
+	p := newShellParser(t, `cat >/dev/null; cat <<'EOF'
+{
+  "front_matter": {"title": "From Command", "Draft": true},
+  "toc": [{"id": "a", "level": 1, "title": "A", "children": [{"id": "b", "level": 2, "title": "B"}]}],
+  "summary": "<p>summary</p>",
+  "content": "<p>content</p>"
+}
+EOF`, CommandOutputJSON)
+
+	result, err := p.Parse(strings.NewReader("---\ntitle: From File\n---\nbody\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "From File", result.FrontMatter["title"])
+	assert.Equal(t, true, result.FrontMatter["draft"])
+	assert.Equal(t, "<p>summary</p>", result.Summary)
+	assert.Equal(t, "<p>content</p>", result.Content)
+	require.Len(t, result.Toc, 1)
+	assert.Equal(t, "a", result.Toc[0].Id)
+	require.Len(t, result.Toc[0].Children, 1)
+	assert.Equal(t, "B", result.Toc[0].Children[0].Title)
+}
+
+func TestCommandParserErrors(t *testing.T) {
+	p := newShellParser(t, `echo "bad input" >&2; exit 3`, CommandOutputHTML)
+	_, err := p.Parse(strings.NewReader("body"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad input")
+
+	p = newShellParser(t, `echo "not json"`, CommandOutputJSON)
+	_, err = p.Parse(strings.NewReader("body"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "command output")
+
+	p = newShellParser(t, `cat`, CommandOutputHTML)
+	_, err = p.Parse(strings.NewReader("---\ntitle: missing close\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "front matter is not closed")
+}
+
+func TestCommandParserTimeout(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	p := NewCommandParser("slow", &CommandOption{
+		Command: []string{"sh", "-c", "exec sleep 5"},
+		Timeout: 100 * time.Millisecond,
+	})
+
+	_, err := p.Parse(strings.NewReader("body"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+}
+
+func TestCommandParserRegisteredFromConfig(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	conf := core.DefaultConfig()
+	conf.Set("markups.rst.command", []string{"sh", "-c", `printf '<div>'; cat; printf '</div>'`})
+	conf.Set("markups.rst.extensions", []string{"rst", ".rest"})
+	conf.Set("markups.disabled.command", "cat")
+	conf.Set("markups.disabled.extensions", []string{".txt"})
+	conf.Set("markups.disabled.enabled", false)
+
+	ctx, err := core.NewContext(conf)
+	require.NoError(t, err)
+
+	p := New(ctx)
+	assert.Contains(t, p.SupportedExtensions(), ".rst")
+	assert.Contains(t, p.SupportedExtensions(), ".rest")
+	assert.NotContains(t, p.SupportedExtensions(), ".txt")
+
+	result, err := p.Parse(fstest.MapFS{
+		"hello.rst": &fstest.MapFile{Data: []byte("hello")},
+	}, "hello.rst")
+	require.NoError(t, err)
+	assert.Equal(t, "<div>hello</div>", result.Content)
+}
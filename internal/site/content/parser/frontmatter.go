@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// 兼容hugo, ---为yaml格式, +++为toml格式
+var FRONT_MATTER_LINE = regexp.MustCompile(`^[-|\+]{3}\s*$`)
+
+func parseFrontMatter(fence string, data []byte) (map[string]any, error) {
+	cf := viper.New()
+	if fence == "---" {
+		cf.SetConfigType("yaml")
+	} else {
+		cf.SetConfigType("toml")
+	}
+	if err := cf.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return cf.AllSettings(), nil
+}
+
+// ReadFrontMatter 读取到结束的fence为止, 第一行的fence需要调用者判断
+func ReadFrontMatter(scanner *bufio.Scanner, line string) (map[string]any, error) {
+	var b bytes.Buffer
+
+	fence := strings.TrimSpace(line)
+	for scanner.Scan() {
+		l := scanner.Text()
+		if strings.TrimSpace(l) == fence {
+			return parseFrontMatter(fence, b.Bytes())
+		}
+		b.WriteString(l)
+		b.WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("front matter scan: %w", err)
+	}
+	return nil, fmt.Errorf("front matter is not closed: %s", fence)
+}
+
+// SplitFrontMatter 分离开头的front matter和剩余的内容, 没有front matter时返回原内容
+func SplitFrontMatter(data []byte) (map[string]any, []byte, error) {
+	line, rest, _ := bytes.Cut(data, []byte("\n"))
+	if !FRONT_MATTER_LINE.Match(bytes.TrimRight(line, "\r")) {
+		return make(map[string]any), data, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(rest))
+	scanner.Buffer(make([]byte, 1024), 1024*1024)
+
+	var size int
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		size += advance
+		return advance, token, err
+	})
+	fm, err := ReadFrontMatter(scanner, string(line))
+	if err != nil {
+		return nil, nil, err
+	}
+	return fm, rest[size:], nil
+}
@@ -2,8 +2,19 @@ package parser
 
 import (
 	"fmt"
+	"html"
+	"strconv"
+	"strings"
 
-	"github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+const (
+	HighlightModeInline  = "inline"
+	HighlightModeClasses = "classes"
 )
 
 type OnlyPreWrapper struct{}
@@ -16,15 +27,265 @@ func (OnlyPreWrapper) End(code bool) string {
 	return `</pre>`
 }
 
-type HTMLFormatter = html.Formatter
+type HTMLFormatter = chromahtml.Formatter
+
+// CodeAttributes 代码块的属性
+//
+//	```go {hl_lines="3-5" linenostart=10 title="main.go"}
+//	#+begin_src go :hl_lines 3-5 :linenostart 10 :title main.go
+type CodeAttributes struct {
+	// 高亮的行, 从代码块的第一行开始计数
+	HighlightLines [][2]int
+	LineNoStart    int
+	Title          string
+	// 为空时使用show_line_numbers配置
+	LineNumbers *bool
+	// 是否进行语法高亮, 为空时使用style配置
+	Highlight *bool
+}
+
+func parseBool(s string) (bool, bool) {
+	switch strings.ToLower(s) {
+	case "true", "yes", "t", "on", "1", "":
+		return true, true
+	case "false", "no", "nil", "off", "0":
+		return false, true
+	}
+	return false, false
+}
+
+// parseLineRanges 解析"3-5 8"或者"3-5,8"格式的行号
+func parseLineRanges(s string) [][2]int {
+	ranges := make([][2]int, 0)
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '[' || r == ']'
+	}) {
+		start, end, found := strings.Cut(field, "-")
+		from, err := strconv.Atoi(start)
+		if err != nil {
+			continue
+		}
+		to := from
+		if found {
+			if to, err = strconv.Atoi(end); err != nil || to < from {
+				continue
+			}
+		}
+		ranges = append(ranges, [2]int{from, to})
+	}
+	return ranges
+}
+
+// NewCodeAttributes 使用key-value创建代码块属性, 未知的key会被忽略
+func NewCodeAttributes(attrs map[string]string) *CodeAttributes {
+	if len(attrs) == 0 {
+		return nil
+	}
+	c := &CodeAttributes{}
+	for k, v := range attrs {
+		switch strings.ToLower(k) {
+		case "hl_lines":
+			c.HighlightLines = parseLineRanges(v)
+		case "linenostart":
+			c.LineNoStart, _ = strconv.Atoi(v)
+		case "title":
+			c.Title = v
+		case "linenos":
+			if b, ok := parseBool(v); ok {
+				c.LineNumbers = &b
+			}
+		case "highlight":
+			if b, ok := parseBool(v); ok {
+				c.Highlight = &b
+			}
+		}
+	}
+	return c
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// ParseCodeInfo 解析markdown代码块的info, 例如go {hl_lines="3-5" title="main.go"}
+func ParseCodeInfo(info string) (string, *CodeAttributes) {
+	info = strings.TrimSpace(info)
+
+	lang, rest := info, ""
+	if i := strings.IndexAny(info, " {"); i >= 0 {
+		lang, rest = info[:i], strings.TrimSpace(info[i:])
+	}
+	if !strings.HasPrefix(rest, "{") || !strings.HasSuffix(rest, "}") {
+		return lang, nil
+	}
+	rest = rest[1 : len(rest)-1]
+
+	attrs := make(map[string]string)
+	for len(rest) > 0 {
+		rest = strings.TrimLeft(rest, " ,")
+		if rest == "" {
+			break
+		}
+		end := strings.IndexAny(rest, "= ,")
+		if end < 0 {
+			attrs[rest] = ""
+			break
+		}
+		key := rest[:end]
+		if rest[end] != '=' {
+			attrs[key] = ""
+			rest = rest[end:]
+			continue
+		}
+		rest = rest[end+1:]
+
+		var value string
+		if len(rest) > 0 && (rest[0] == '"' || rest[0] == '\'') {
+			if i := strings.IndexByte(rest[1:], rest[0]); i >= 0 {
+				value, rest = rest[1:i+1], rest[i+2:]
+			} else {
+				value, rest = rest[1:], ""
+			}
+		} else if i := strings.IndexAny(rest, " ,"); i >= 0 {
+			value, rest = rest[:i], rest[i:]
+		} else {
+			value, rest = rest, ""
+		}
+		attrs[key] = value
+	}
+	return lang, NewCodeAttributes(attrs)
+}
 
-func NewHTMLFormatter(opt MarkupOption) *html.Formatter {
-	opts := make([]html.Option, 0)
-	if opt.ShowLineNumbers {
-		opts = append(opts, html.WithLineNumbers(true))
+// ParseCodeParams 解析org代码块的参数, 例如[go :hl_lines 3-5 :title main.go]
+func ParseCodeParams(params []string) (string, *CodeAttributes) {
+	lang := ""
+	if len(params) > 0 && !strings.HasPrefix(params[0], ":") {
+		lang, params = params[0], params[1:]
+	}
+
+	attrs := make(map[string]string)
+	for i := 0; i < len(params); i++ {
+		key, ok := strings.CutPrefix(params[i], ":")
+		if !ok || key == "" {
+			continue
+		}
+		value := make([]string, 0)
+		for i+1 < len(params) && !strings.HasPrefix(params[i+1], ":") {
+			i++
+			if params[i] != "" {
+				value = append(value, params[i])
+			}
+		}
+		attrs[key] = unquote(strings.Join(value, " "))
+	}
+	return lang, NewCodeAttributes(attrs)
+}
+
+func newHTMLFormatter(opt MarkupOption, attrs *CodeAttributes) *chromahtml.Formatter {
+	lineNumbers := opt.ShowLineNumbers
+	if attrs != nil && attrs.LineNumbers != nil {
+		lineNumbers = *attrs.LineNumbers
+	}
+
+	opts := make([]chromahtml.Option, 0)
+	if lineNumbers {
+		opts = append(opts, chromahtml.WithLineNumbers(true))
 	}
 	if opt.PreventPreCode {
-		opts = append(opts, html.WithPreWrapper(OnlyPreWrapper{}))
+		opts = append(opts, chromahtml.WithPreWrapper(OnlyPreWrapper{}))
+	}
+	if opt.HighlightMode == HighlightModeClasses {
+		opts = append(opts, chromahtml.WithClasses(true))
+	}
+	if attrs != nil {
+		base := 1
+		if attrs.LineNoStart > 0 {
+			base = attrs.LineNoStart
+			opts = append(opts, chromahtml.BaseLineNumber(base))
+		}
+		if len(attrs.HighlightLines) > 0 {
+			// chroma使用显示的行号判断是否高亮
+			ranges := make([][2]int, 0, len(attrs.HighlightLines))
+			for _, r := range attrs.HighlightLines {
+				ranges = append(ranges, [2]int{r[0] + base - 1, r[1] + base - 1})
+			}
+			opts = append(opts, chromahtml.HighlightLines(ranges))
+		}
+	}
+	return chromahtml.New(opts...)
+}
+
+func NewHTMLFormatter(opt MarkupOption) *chromahtml.Formatter {
+	return newHTMLFormatter(opt, nil)
+}
+
+// HighlightCode 使用chroma高亮代码, lang为空时自动识别语言
+func HighlightCode(opt MarkupOption, lang string, source string, attrs *CodeAttributes) string {
+	var w strings.Builder
+
+	enabled := opt.Style != "" && opt.Style != "none"
+	if attrs != nil && attrs.Highlight != nil {
+		enabled = enabled && *attrs.Highlight
+	}
+	if enabled {
+		var lexer chroma.Lexer
+		if lang == "" {
+			lexer = lexers.Analyse(source)
+		} else {
+			lexer = lexers.Get(lang)
+		}
+		if lexer == nil {
+			lexer = lexers.Fallback
+		}
+
+		style := styles.Get(opt.Style)
+		if style == nil {
+			style = styles.Fallback
+		}
+
+		it, _ := lexer.Tokenise(nil, source)
+		newHTMLFormatter(opt, attrs).Format(&w, style, it)
+	} else {
+		w.WriteString("<pre><code")
+		if lang != "" {
+			fmt.Fprintf(&w, ` class="language-%s"`, html.EscapeString(lang))
+		}
+		w.WriteString(">")
+		w.WriteString(html.EscapeString(source))
+		w.WriteString("</code></pre>")
+	}
+
+	if attrs == nil || attrs.Title == "" {
+		return w.String()
+	}
+	return fmt.Sprintf("<figure class=\"code-block\">\n<figcaption>%s</figcaption>\n%s\n</figure>", html.EscapeString(attrs.Title), w.String())
+}
+
+// ChromaCSS 返回classes模式使用的样式, darkStyle不为空时在prefers-color-scheme: dark中使用
+func ChromaCSS(style string, darkStyle string) (string, error) {
+	var b strings.Builder
+
+	formatter := chromahtml.New(chromahtml.WithClasses(true))
+	for i, name := range []string{style, darkStyle} {
+		if i > 0 && name == "" {
+			break
+		}
+		s, ok := styles.Registry[strings.ToLower(name)]
+		if !ok {
+			return "", fmt.Errorf("unknown chroma style: %s", name)
+		}
+		if i > 0 {
+			b.WriteString("@media (prefers-color-scheme: dark) {\n")
+		}
+		if err := formatter.WriteCSS(&b, s); err != nil {
+			return "", err
+		}
+		if i > 0 {
+			b.WriteString("}\n")
+		}
 	}
-	return html.New(opts...)
+	return b.String(), nil
 }
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChromaCSS(t *testing.T) {
+	css, err := ChromaCSS("github", "")
+	require.NoError(t, err)
+	assert.Contains(t, css, ".chroma")
+	assert.NotContains(t, css, "prefers-color-scheme")
+
+	css, err = ChromaCSS("github", "dracula")
+	require.NoError(t, err)
+	light, dark, ok := strings.Cut(css, "@media (prefers-color-scheme: dark) {\n")
+	require.True(t, ok)
+	assert.Contains(t, light, ".chroma")
+	assert.Contains(t, dark, ".chroma")
+	assert.True(t, strings.HasSuffix(dark, "}\n"))
+
+	_, err = ChromaCSS("unknown", "")
+	require.Error(t, err)
+	_, err = ChromaCSS("github", "unknown")
+	require.Error(t, err)
+}
+
+func TestParseCodeInfo(t *testing.T) {
+	lang, attrs := ParseCodeInfo("go")
+	assert.Equal(t, "go", lang)
+	assert.Nil(t, attrs)
+
+	lang, attrs = ParseCodeInfo(`go {hl_lines="3-5 8" linenostart=10, title="main file.go" linenos=false}`)
+	assert.Equal(t, "go", lang)
+	require.NotNil(t, attrs)
+	assert.Equal(t, [][2]int{{3, 5}, {8, 8}}, attrs.HighlightLines)
+	assert.Equal(t, 10, attrs.LineNoStart)
+	assert.Equal(t, "main file.go", attrs.Title)
+	require.NotNil(t, attrs.LineNumbers)
+	assert.False(t, *attrs.LineNumbers)
+	assert.Nil(t, attrs.Highlight)
+
+	lang, attrs = ParseCodeInfo(`{highlight=false}`)
+	assert.Equal(t, "", lang)
+	require.NotNil(t, attrs)
+	require.NotNil(t, attrs.Highlight)
+	assert.False(t, *attrs.Highlight)
+}
+
+func TestParseCodeParams(t *testing.T) {
+	lang, attrs := ParseCodeParams([]string{"go", ":hl_lines", "3-5,8", ":title", `"main`, `file.go"`, ":linenos", "yes"})
+	assert.Equal(t, "go", lang)
+	require.NotNil(t, attrs)
+	assert.Equal(t, [][2]int{{3, 5}, {8, 8}}, attrs.HighlightLines)
+	assert.Equal(t, "main file.go", attrs.Title)
+	require.NotNil(t, attrs.LineNumbers)
+	assert.True(t, *attrs.LineNumbers)
+
+	lang, attrs = ParseCodeParams([]string{"python"})
+	assert.Equal(t, "python", lang)
+	assert.Nil(t, attrs)
+}
+
+func TestHighlightCode(t *testing.T) {
+	source := "a := 1\nb := 2\nc := 3\n"
+	opt := MarkupOption{Style: "monokai", ShowLineNumbers: true, HighlightMode: HighlightModeClasses}
+
+	content := HighlightCode(opt, "go", source, &CodeAttributes{
+		HighlightLines: [][2]int{{2, 2}},
+		LineNoStart:    10,
+		Title:          "a<b>.go",
+	})
+	assert.True(t, strings.HasPrefix(content, "<figure class=\"code-block\">\n<figcaption>a&lt;b&gt;.go</figcaption>\n"))
+	assert.Contains(t, content, `<span class="ln">10</span>`)
+	assert.Contains(t, content, `<span class="line hl"><span class="ln">11</span>`)
+	assert.NotContains(t, content, `<span class="line hl"><span class="ln">12</span>`)
+
+	off := false
+	content = HighlightCode(opt, "go", source, &CodeAttributes{LineNumbers: &off})
+	assert.NotContains(t, content, `class="ln"`)
+
+	content = HighlightCode(opt, "go", "a < b", &CodeAttributes{Highlight: &off})
+	assert.Equal(t, `<pre><code class="language-go">a &lt; b</code></pre>`, content)
+}
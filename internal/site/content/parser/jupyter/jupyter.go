@@ -0,0 +1,339 @@
+package jupyter
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/content/parser"
+	"github.com/honmaple/snow/internal/site/content/parser/markdown"
+)
+
+var (
+	// 第一个raw单元格可以作为front matter
+	JUPYTER_META = regexp.MustCompile(`^\s*(---|\+\+\+)\s*\n`)
+	// 代码单元格在markdown中的占位符
+	jupyterPlaceholder = regexp.MustCompile(`(?:<p>)?\x{E000}(\d+)\x{E001}(?:</p>)?`)
+	// traceback中的终端颜色
+	jupyterANSI = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+)
+
+// 按照优先级选择display_data和execute_result的输出格式
+var outputMimeTypes = []string{
+	"text/html",
+	"image/svg+xml",
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"text/plain",
+}
+
+var imageExtensions = map[string]string{
+	"image/svg+xml": ".svg",
+	"image/png":     ".png",
+	"image/jpeg":    ".jpg",
+	"image/gif":     ".gif",
+}
+
+const (
+	parserName = "jupyter"
+	// 使用私有区字符作为占位符, 不会被markdown解析器处理
+	placeholderStart = "\uE000"
+	placeholderEnd   = "\uE001"
+)
+
+type Option struct {
+	parser.MarkupOption
+	HideInput      bool
+	HideOutput     bool
+	StripTraceback bool
+}
+
+type jupyterParser struct {
+	opt      *Option
+	markdown parser.MarkupParser
+}
+
+// notebookRenderer 保存单个notebook的渲染结果
+type notebookRenderer struct {
+	*jupyterParser
+
+	lang    string
+	html    []string
+	sources []string
+	assets  map[string][]byte
+}
+
+func (r *notebookRenderer) placeholder(html string, source string) string {
+	r.html = append(r.html, html)
+	r.sources = append(r.sources, source)
+	return fmt.Sprintf("%s%d%s", placeholderStart, len(r.html)-1, placeholderEnd)
+}
+
+func (r *notebookRenderer) restore(s string, values []string) string {
+	return jupyterPlaceholder.ReplaceAllStringFunc(s, func(m string) string {
+		i, _ := strconv.Atoi(jupyterPlaceholder.FindStringSubmatch(m)[1])
+		return values[i]
+	})
+}
+
+// asset 保存图片并返回附件名称, 相同内容的图片只保存一次, 解析页面时src会替换为附件的permalink
+func (r *notebookRenderer) asset(mime string, data []byte) string {
+	sum := sha256.Sum256(data)
+	name := "jupyter-" + hex.EncodeToString(sum[:8]) + imageExtensions[mime]
+	r.assets[name] = data
+	return name
+}
+
+// image 解析图片输出, svg为文本, 其它图片使用base64编码
+func (r *notebookRenderer) image(mime string, text string) (string, bool) {
+	if mime == "image/svg+xml" {
+		return r.asset(mime, []byte(text)), true
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+	if err != nil {
+		return "", false
+	}
+	return r.asset(mime, data), true
+}
+
+func (r *notebookRenderer) highlight(source string) string {
+	return parser.HighlightCode(r.opt.MarkupOption, r.lang, source, nil)
+}
+
+func (r *notebookRenderer) renderOutput(o *output) string {
+	switch o.Type {
+	case "stream":
+		return fmt.Sprintf(`<pre class="jupyter-stream jupyter-%s">%s</pre>`, html.EscapeString(o.Name), html.EscapeString(o.Text.String()))
+	case "error":
+		text := o.Ename + ": " + o.Evalue
+		if !r.opt.StripTraceback && len(o.Traceback) > 0 {
+			text = jupyterANSI.ReplaceAllString(strings.Join(o.Traceback, "\n"), "")
+		}
+		return fmt.Sprintf(`<pre class="jupyter-error">%s</pre>`, html.EscapeString(text))
+	case "execute_result", "display_data":
+		for _, mime := range outputMimeTypes {
+			text, ok := o.data(mime)
+			if !ok {
+				continue
+			}
+			switch mime {
+			case "text/html":
+				return `<div class="jupyter-html">` + text + `</div>`
+			case "text/plain":
+				return fmt.Sprintf(`<pre class="jupyter-text">%s</pre>`, html.EscapeString(text))
+			}
+			name, ok := r.image(mime, text)
+			if !ok {
+				continue
+			}
+			attrs := ""
+			if meta, ok := o.Metadata[mime].(map[string]any); ok {
+				for _, key := range []string{"width", "height"} {
+					if v, ok := meta[key]; ok {
+						attrs += fmt.Sprintf(` %s="%v"`, key, v)
+					}
+				}
+			}
+			return fmt.Sprintf(`<img class="jupyter-image" src="%s" alt="output"%s />`, name, attrs)
+		}
+	}
+	return ""
+}
+
+func (r *notebookRenderer) renderCode(c *cell) string {
+	tags := c.tags()
+
+	var b strings.Builder
+	if source := c.Source.String(); source != "" && !r.opt.HideInput && !tags["remove-input"] {
+		b.WriteString(`<div class="jupyter-input">`)
+		b.WriteString(r.highlight(source))
+		b.WriteString("</div>\n")
+	}
+	if len(c.Outputs) > 0 && !r.opt.HideOutput && !tags["remove-output"] {
+		var outputs strings.Builder
+		for _, o := range c.Outputs {
+			outputs.WriteString(r.renderOutput(o))
+		}
+		if outputs.Len() > 0 {
+			b.WriteString(`<div class="jupyter-output">`)
+			b.WriteString(outputs.String())
+			b.WriteString("</div>\n")
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return `<div class="jupyter-cell">` + "\n" + b.String() + "</div>"
+}
+
+// renderMarkdown 把markdown单元格中的attachment:链接替换为附件名称
+func (r *notebookRenderer) renderMarkdown(c *cell) string {
+	source := c.Source.String()
+	for _, name := range slices.Sorted(maps.Keys(c.Attachments)) {
+		for _, mime := range outputMimeTypes {
+			data, ok := c.Attachments[name][mime]
+			if _, isImage := imageExtensions[mime]; !ok || !isImage {
+				continue
+			}
+			if asset, ok := r.image(mime, data.String()); ok {
+				source = strings.ReplaceAll(source, "attachment:"+name, asset)
+			}
+			break
+		}
+	}
+	return source
+}
+
+func (r *notebookRenderer) render(nb *notebook) string {
+	var b strings.Builder
+
+	cells := nb.Cells
+	if len(cells) > 0 && cells[0].Type == "raw" && JUPYTER_META.MatchString(cells[0].Source.String()) {
+		b.WriteString(strings.TrimRight(cells[0].Source.String(), "\n"))
+		b.WriteString("\n")
+		cells = cells[1:]
+	}
+	// 空行避免markdown解析器把第一行当作key: value格式的元数据
+	b.WriteString("\n")
+
+	for _, c := range cells {
+		if c.tags()["remove-cell"] {
+			continue
+		}
+		switch c.Type {
+		case "markdown":
+			b.WriteString(r.renderMarkdown(c))
+			b.WriteString("\n\n")
+		case "code":
+			if html := r.renderCode(c); html != "" {
+				b.WriteString(r.placeholder(html, c.Source.String()))
+				b.WriteString("\n\n")
+			}
+		case "raw":
+			// 只输出格式为html的raw单元格
+			if format, _ := c.Metadata["format"].(string); format == "text/html" {
+				b.WriteString(r.placeholder(c.Source.String(), ""))
+				b.WriteString("\n\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// frontMatter 使用notebook的metadata补充front matter, raw单元格中的配置优先
+func frontMatter(result *parser.Result, nb *notebook) {
+	values := make(map[string]any)
+	if title, ok := nb.Metadata["title"].(string); ok && title != "" {
+		values["title"] = title
+	}
+	if authors, ok := nb.Metadata["authors"].([]any); ok {
+		names := make([]any, 0, len(authors))
+		for _, author := range authors {
+			switch v := author.(type) {
+			case string:
+				names = append(names, v)
+			case map[string]any:
+				if name, ok := v["name"].(string); ok {
+					names = append(names, name)
+				}
+			}
+		}
+		if len(names) > 0 {
+			values["authors"] = names
+		}
+	}
+	if snow, ok := nb.Metadata["snow"].(map[string]any); ok {
+		for k, v := range snow {
+			values[strings.ToLower(k)] = v
+		}
+	}
+	for k, v := range values {
+		if _, ok := result.FrontMatter[k]; !ok {
+			result.FrontMatter[k] = v
+		}
+	}
+}
+
+func (p *jupyterParser) Parse(r io.Reader) (*parser.Result, error) {
+	return p.ParseLang(r, "")
+}
+
+func (p *jupyterParser) ParseLang(r io.Reader, lang string) (*parser.Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("jupyter parser read: %w", err)
+	}
+
+	var nb notebook
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return nil, fmt.Errorf("jupyter parser decode: %w", err)
+	}
+
+	nr := &notebookRenderer{
+		jupyterParser: p,
+		lang:          nb.language(),
+		assets:        make(map[string][]byte),
+	}
+	source := strings.NewReader(nr.render(&nb))
+
+	var result *parser.Result
+	if m, ok := p.markdown.(parser.LangMarkupParser); ok {
+		result, err = m.ParseLang(source, lang)
+	} else {
+		result, err = p.markdown.Parse(source)
+	}
+	if err != nil {
+		return nil, err
+	}
+	frontMatter(result, &nb)
+
+	result.Content = nr.restore(result.Content, nr.html)
+	result.Summary = nr.restore(result.Summary, nr.html)
+	result.RawContent = nr.restore(result.RawContent, nr.sources)
+	result.RawSummary = nr.restore(result.RawSummary, nr.sources)
+	if len(nr.assets) > 0 {
+		result.Assets = nr.assets
+	}
+	return result, nil
+}
+
+func (p *jupyterParser) SupportedExtensions() []string {
+	return []string{".ipynb"}
+}
+
+func New(opt *Option) *jupyterParser {
+	return &jupyterParser{
+		opt:      opt,
+		markdown: markdown.New(&markdown.Option{MarkupOption: opt.MarkupOption}),
+	}
+}
+
+func NewWithContext(ctx *core.Context) *jupyterParser {
+	opt := &Option{
+		MarkupOption:   parser.NewMarkupOption(ctx, parserName),
+		HideInput:      ctx.GetMarkupConfig(parserName, "hide_input").Bool(),
+		HideOutput:     ctx.GetMarkupConfig(parserName, "hide_output").Bool(),
+		StripTraceback: ctx.GetMarkupConfig(parserName, "strip_traceback").Bool(),
+	}
+	p := New(opt)
+	// markdown单元格使用markups.markdown的配置
+	p.markdown = markdown.NewWithContext(ctx)
+	return p
+}
+
+func init() {
+	parser.Register(parserName, func(ctx *core.Context) parser.MarkupParser {
+		return NewWithContext(ctx)
+	})
+}
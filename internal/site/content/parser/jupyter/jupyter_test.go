@@ -0,0 +1,157 @@
+package jupyter
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/honmaple/snow/internal/site/content/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var png = []byte("\x89PNG\r\n\x1a\nfake")
+
+func parseNotebook(t *testing.T, text string, opt *Option) *parser.Result {
+	t.Helper()
+
+	if opt == nil {
+		opt = &Option{MarkupOption: parser.MarkupOption{Style: "none"}}
+	}
+	result, err := New(opt).Parse(strings.NewReader(text))
+	require.NoError(t, err)
+	return result
+}
+
+const notebookText = `{
+ "metadata": {
+  "title": "Notebook Title",
+  "authors": [{"name": "snow"}],
+  "snow": {"tags": ["python", "data"]},
+  "language_info": {"name": "python"}
+ },
+ "nbformat": 4,
+ "nbformat_minor": 5,
+ "cells": [
+  {"cell_type": "markdown", "metadata": {}, "source": ["# Intro\n", "\n", "summary"]},
+  {"cell_type": "markdown", "metadata": {}, "source": "<!--more-->"},
+  {
+   "cell_type": "code", "metadata": {}, "execution_count": 1,
+   "source": ["print(1 < 2)"],
+   "outputs": [
+    {"output_type": "stream", "name": "stdout", "text": ["True\n"]},
+    {"output_type": "execute_result", "execution_count": 1, "metadata": {}, "data": {"text/plain": ["<Figure>"], "image/png": "` + "PNGDATA" + `"}},
+    {"output_type": "error", "ename": "ValueError", "evalue": "bad", "traceback": ["\u001b[0;31mValueError\u001b[0m Traceback", "ValueError: bad"]}
+   ]
+  },
+  {"cell_type": "markdown", "metadata": {}, "source": "## Section"}
+ ]
+}`
+
+func newNotebook() string {
+	return strings.Replace(notebookText, "PNGDATA", base64.StdEncoding.EncodeToString(png), 1)
+}
+
+func TestNotebook(t *testing.T) {
+	result := parseNotebook(t, newNotebook(), nil)
+
+	assert.Equal(t, "Notebook Title", result.FrontMatter["title"])
+	assert.Equal(t, []any{"snow"}, result.FrontMatter["authors"])
+	assert.Equal(t, []any{"python", "data"}, result.FrontMatter["tags"])
+
+	assert.Contains(t, result.Content, "<h1>Intro</h1>")
+	assert.Contains(t, result.Content, `<code class="language-python">print(1 &lt; 2)</code>`)
+	assert.Contains(t, result.Content, `<pre class="jupyter-stream jupyter-stdout">True`)
+	assert.Contains(t, result.Content, "ValueError Traceback\nValueError: bad")
+	assert.NotContains(t, result.Content, "\u001b")
+	assert.NotContains(t, result.Content, "&lt;Figure&gt;")
+	assert.NotContains(t, result.Content, "<p>")
+
+	require.Len(t, result.Assets, 1)
+	for name, data := range result.Assets {
+		assert.True(t, strings.HasPrefix(name, "jupyter-"))
+		assert.True(t, strings.HasSuffix(name, ".png"))
+		assert.Equal(t, png, data)
+		assert.Contains(t, result.Content, `<img class="jupyter-image" src="`+name+`"`)
+	}
+
+	assert.Contains(t, result.Summary, "summary")
+	assert.NotContains(t, result.Summary, "jupyter-cell")
+	assert.Contains(t, result.RawContent, "print(1 < 2)")
+	assert.NotContains(t, result.RawContent, placeholderStart)
+}
+
+func TestRawCellFrontMatter(t *testing.T) {
+	result := parseNotebook(t, `{
+ "metadata": {"title": "Metadata Title", "snow": {"draft": true}},
+ "cells": [
+  {"cell_type": "raw", "metadata": {}, "source": ["---\n", "title: Raw Title\n", "date: 2024-01-15\n", "---\n"]},
+  {"cell_type": "markdown", "metadata": {}, "source": "Note: not front matter"}
+ ]
+}`, nil)
+
+	assert.Equal(t, "Raw Title", result.FrontMatter["title"])
+	assert.Equal(t, true, result.FrontMatter["draft"])
+	assert.NotNil(t, result.FrontMatter["date"])
+	assert.NotContains(t, result.FrontMatter, "note")
+	assert.Contains(t, result.Content, "<p>Note: not front matter</p>")
+}
+
+func TestOptions(t *testing.T) {
+	text := newNotebook()
+
+	result := parseNotebook(t, text, &Option{HideInput: true, MarkupOption: parser.MarkupOption{Style: "none"}})
+	assert.NotContains(t, result.Content, "jupyter-input")
+	assert.Contains(t, result.Content, "jupyter-output")
+
+	result = parseNotebook(t, text, &Option{HideOutput: true, MarkupOption: parser.MarkupOption{Style: "none"}})
+	assert.Contains(t, result.Content, "jupyter-input")
+	assert.NotContains(t, result.Content, "jupyter-output")
+	assert.Empty(t, result.Assets)
+
+	result = parseNotebook(t, text, &Option{StripTraceback: true, MarkupOption: parser.MarkupOption{Style: "none"}})
+	assert.Contains(t, result.Content, `<pre class="jupyter-error">ValueError: bad</pre>`)
+	assert.NotContains(t, result.Content, "Traceback")
+}
+
+func TestCellTags(t *testing.T) {
+	result := parseNotebook(t, `{
+ "metadata": {},
+ "cells": [
+  {"cell_type": "code", "metadata": {"tags": ["remove-input"]}, "source": "hidden_input()", "outputs": [{"output_type": "stream", "name": "stdout", "text": "visible output"}]},
+  {"cell_type": "code", "metadata": {"tags": ["remove-cell"]}, "source": "removed()", "outputs": []},
+  {"cell_type": "raw", "metadata": {"format": "text/html"}, "source": "<hr class=\"raw\">"}
+ ]
+}`, nil)
+
+	assert.NotContains(t, result.Content, "hidden_input")
+	assert.Contains(t, result.Content, "visible output")
+	assert.NotContains(t, result.Content, "removed")
+	assert.Contains(t, result.Content, `<hr class="raw">`)
+}
+
+func TestAttachments(t *testing.T) {
+	data := base64.StdEncoding.EncodeToString(png)
+	result := parseNotebook(t, `{
+ "metadata": {},
+ "cells": [
+  {"cell_type": "markdown", "metadata": {}, "source": "![cat](attachment:cat.png)", "attachments": {"cat.png": {"image/png": "`+data+`"}}}
+ ]
+}`, nil)
+
+	require.Len(t, result.Assets, 1)
+	for name := range result.Assets {
+		assert.Contains(t, result.Content, `<img src="`+name+`" alt="cat">`)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	result := parseNotebook(t, newNotebook(), &Option{MarkupOption: parser.MarkupOption{Style: "monokai"}})
+	assert.Contains(t, result.Content, `<pre style="`)
+}
+
+func TestInvalidNotebook(t *testing.T) {
+	_, err := New(&Option{}).Parse(strings.NewReader("{"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "jupyter parser decode")
+}
@@ -0,0 +1,92 @@
+package jupyter
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+type (
+	// multiline notebook中的文本可以是字符串或者字符串数组
+	multiline string
+
+	notebook struct {
+		Metadata map[string]any `json:"metadata"`
+		Format   int            `json:"nbformat"`
+		Cells    []*cell        `json:"cells"`
+	}
+	cell struct {
+		Type        string                          `json:"cell_type"`
+		Source      multiline                       `json:"source"`
+		Metadata    map[string]any                  `json:"metadata"`
+		Outputs     []*output                       `json:"outputs"`
+		Attachments map[string]map[string]multiline `json:"attachments"`
+	}
+	output struct {
+		Type      string                     `json:"output_type"`
+		Name      string                     `json:"name"`
+		Text      multiline                  `json:"text"`
+		Data      map[string]json.RawMessage `json:"data"`
+		Metadata  map[string]any             `json:"metadata"`
+		Ename     string                     `json:"ename"`
+		Evalue    string                     `json:"evalue"`
+		Traceback []string                   `json:"traceback"`
+	}
+)
+
+func (s *multiline) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*s = multiline(strings.Join(lines, ""))
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	*s = multiline(str)
+	return nil
+}
+
+func (s multiline) String() string {
+	return string(s)
+}
+
+// language 返回notebook代码单元格的语言
+func (nb *notebook) language() string {
+	if info, ok := nb.Metadata["language_info"].(map[string]any); ok {
+		if lang, ok := info["name"].(string); ok && lang != "" {
+			return lang
+		}
+	}
+	if info, ok := nb.Metadata["kernelspec"].(map[string]any); ok {
+		if lang, ok := info["language"].(string); ok && lang != "" {
+			return lang
+		}
+	}
+	return "python"
+}
+
+// tags 返回单元格的metadata.tags
+func (c *cell) tags() map[string]bool {
+	tags := make(map[string]bool)
+	values, _ := c.Metadata["tags"].([]any)
+	for _, v := range values {
+		if tag, ok := v.(string); ok {
+			tags[tag] = true
+		}
+	}
+	return tags
+}
+
+// data 返回输出中指定类型的文本内容
+func (o *output) data(mime string) (string, bool) {
+	raw, ok := o.Data[mime]
+	if !ok {
+		return "", false
+	}
+	var s multiline
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", false
+	}
+	return s.String(), true
+}
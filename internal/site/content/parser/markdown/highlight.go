@@ -3,9 +3,6 @@ package markdown
 import (
 	"bytes"
 
-	"github.com/alecthomas/chroma/v2"
-	"github.com/alecthomas/chroma/v2/lexers"
-	"github.com/alecthomas/chroma/v2/styles"
 	contentparser "github.com/honmaple/snow/internal/site/content/parser"
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
@@ -14,8 +11,7 @@ import (
 )
 
 type highlightExtension struct {
-	opt       *Option
-	formatter *contentparser.HTMLFormatter
+	opt *Option
 }
 
 func (r *highlightExtension) Extend(m goldmark.Markdown) {
@@ -29,25 +25,9 @@ func (r *highlightExtension) RegisterFuncs(reg renderer.NodeRendererFuncRegister
 	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
 }
 
-func (r *highlightExtension) render(w util.BufWriter, lang string, source string) error {
-	var lexer chroma.Lexer
-
-	if lang != "" {
-		lexer = lexers.Get(lang)
-	} else {
-		lexer = lexers.Analyse(source)
-	}
-	if lexer == nil {
-		lexer = lexers.Fallback
-	}
-
-	style := styles.Get(r.opt.Style)
-	if style == nil {
-		style = styles.Fallback
-	}
-
-	it, _ := lexer.Tokenise(nil, source)
-	return r.formatter.Format(w, style, it)
+func (r *highlightExtension) render(w util.BufWriter, lang string, source string, attrs *contentparser.CodeAttributes) error {
+	_, err := w.WriteString(contentparser.HighlightCode(r.opt.MarkupOption, lang, source, attrs) + "\n")
+	return err
 }
 
 func (r *highlightExtension) renderCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
@@ -62,7 +42,7 @@ func (r *highlightExtension) renderCodeBlock(w util.BufWriter, source []byte, no
 		line := n.Lines().At(i)
 		buf.Write(line.Value(source))
 	}
-	return ast.WalkContinue, r.render(w, "", buf.String())
+	return ast.WalkContinue, r.render(w, "", buf.String(), nil)
 }
 
 func (r *highlightExtension) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
@@ -77,14 +57,18 @@ func (r *highlightExtension) renderFencedCodeBlock(w util.BufWriter, source []by
 		line := n.Lines().At(i)
 		buf.Write(line.Value(source))
 	}
-	lang := string(n.Language(source))
-	return ast.WalkContinue, r.render(w, lang, buf.String())
+
+	var info string
+	if n.Info != nil {
+		info = string(n.Info.Segment.Value(source))
+	}
+	lang, attrs := contentparser.ParseCodeInfo(info)
+	return ast.WalkContinue, r.render(w, lang, buf.String(), attrs)
 }
 
 func NewHighlightExtension(opt *Option) goldmark.Extender {
 	r := &highlightExtension{
-		opt:       opt,
-		formatter: contentparser.NewHTMLFormatter(opt.MarkupOption),
+		opt: opt,
 	}
 	return r
 }
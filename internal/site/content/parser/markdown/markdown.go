@@ -7,21 +7,17 @@ import (
 	"io"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/honmaple/snow/internal/core"
 	"github.com/honmaple/snow/internal/site/content/parser"
-	"github.com/spf13/viper"
+	"github.com/spf13/cast"
 	"github.com/yuin/goldmark"
-	"github.com/yuin/goldmark/extension"
 	goldmarkParser "github.com/yuin/goldmark/parser"
-	"github.com/yuin/goldmark/renderer"
-	"github.com/yuin/goldmark/renderer/html"
 	"github.com/yuin/goldmark/text"
 )
 
 var (
-	// 兼容hugo
-	MARKDOWN_LINE = regexp.MustCompile(`^[-|\+]{3}\s*$`)
 	MARKDOWN_MORE = regexp.MustCompile(`^\s*(?i:<!--more-->)\s*$`)
 	MARKDOWN_META = regexp.MustCompile(`^([^:]+):(\s+(.*)|$)`)
 )
@@ -32,7 +28,15 @@ type (
 	Option struct {
 		parser.MarkupOption
 		Unsafe          bool
+		HardWraps       bool
+		XHTML           bool
 		DirectiveBlocks bool
+		// 启用或禁用的goldmark扩展, 未配置时使用默认值
+		Extensions map[string]bool
+		// typographer扩展替换的字符, 例如left_double_quote: "「"
+		Typographer map[string]string
+		// templates/_markup中存在的渲染模版
+		RenderHooks map[string]string
 	}
 	Heading = parser.Heading
 )
@@ -40,23 +44,65 @@ type (
 type mdParser struct {
 	md  goldmark.Markdown
 	opt *Option
+	// 其它语言的配置
+	langs map[string]*Option
+	// 按照配置缓存的goldmark实例
+	mds sync.Map
 }
 
-func (m *mdParser) parse(data []byte) ([]*parser.Heading, string, error) {
+// option 返回当前语言, cascade和front matter覆盖后的配置, front matter优先
+func (m *mdParser) option(lang string, defaults map[string]any, frontMatter map[string]any) *Option {
+	if v := cast.ToString(frontMatter["lang"]); v != "" {
+		lang = v
+	}
+	opt := m.opt
+	if o, ok := m.langs[lang]; ok {
+		opt = o
+	}
+	for _, fm := range []map[string]any{defaults, frontMatter} {
+		if v, ok := fm[parserName]; ok {
+			opt = opt.apply(cast.ToStringMap(v))
+		}
+	}
+	return opt
+}
+
+func (m *mdParser) markdown(opt *Option) goldmark.Markdown {
+	if opt == m.opt {
+		return m.md
+	}
+	key := opt.key()
+	if md, ok := m.mds.Load(key); ok {
+		return md.(goldmark.Markdown)
+	}
+	md, _ := m.mds.LoadOrStore(key, newMarkdown(opt))
+	return md.(goldmark.Markdown)
+}
+
+func (m *mdParser) parse(md goldmark.Markdown, data []byte) ([]*parser.Heading, string, []string, error) {
 	var buf bytes.Buffer
 
 	ctx := goldmarkParser.NewContext()
-	doc := m.md.Parser().Parse(text.NewReader(data), goldmarkParser.WithContext(ctx))
-	if err := m.md.Renderer().Render(&buf, data, doc); err != nil {
-		return nil, "", err
+	doc := md.Parser().Parse(text.NewReader(data), goldmarkParser.WithContext(ctx))
+	if err := md.Renderer().Render(&buf, data, doc); err != nil {
+		return nil, "", nil, err
 	}
+	errs, _ := ctx.Get(mathErrorsKey).([]string)
 	if toc, ok := ctx.Get(tocKey).([]*parser.Heading); ok {
-		return toc, buf.String(), nil
+		return toc, buf.String(), errs, nil
 	}
-	return nil, buf.String(), nil
+	return nil, buf.String(), errs, nil
 }
 
 func (m *mdParser) Parse(r io.Reader) (*parser.Result, error) {
+	return m.ParseLang(r, "")
+}
+
+func (m *mdParser) ParseLang(r io.Reader, lang string) (*parser.Result, error) {
+	return m.ParseDefaults(r, lang, nil)
+}
+
+func (m *mdParser) ParseDefaults(r io.Reader, lang string, defaults map[string]any) (*parser.Result, error) {
 	var (
 		summary   bytes.Buffer
 		content   bytes.Buffer
@@ -73,37 +119,12 @@ func (m *mdParser) Parse(r io.Reader) (*parser.Result, error) {
 	scanner.Buffer(make([]byte, 1024), scannerMaxTokenSize)
 	for scanner.Scan() {
 		line := scanner.Text()
-		if isFormat && MARKDOWN_LINE.MatchString(line) {
-			var b bytes.Buffer
-			closed := false
-			fence := strings.TrimSpace(line)
-			for scanner.Scan() {
-				l := scanner.Text()
-				if strings.TrimSpace(l) == fence {
-					closed = true
-					break
-				}
-				b.WriteString(l)
-				b.WriteString("\n")
-			}
-			if err := scanner.Err(); err != nil {
-				return nil, fmt.Errorf("markdown parser scan: %w", err)
+		if isFormat && parser.FRONT_MATTER_LINE.MatchString(line) {
+			fm, err := parser.ReadFrontMatter(scanner, line)
+			if err != nil {
+				return nil, fmt.Errorf("markdown %w", err)
 			}
-			if !closed {
-				return nil, fmt.Errorf("markdown front matter is not closed: %s", fence)
-			}
-
-			cf := viper.New()
-			if fence == "---" {
-				cf.SetConfigType("yaml")
-			} else {
-				cf.SetConfigType("toml")
-			}
-
-			if err := cf.ReadConfig(&b); err != nil {
-				return nil, err
-			}
-			result.FrontMatter = cf.AllSettings()
+			result.FrontMatter = fm
 			isFormat = false
 			continue
 		}
@@ -127,17 +148,21 @@ func (m *mdParser) Parse(r io.Reader) (*parser.Result, error) {
 		return nil, fmt.Errorf("markdown parser scan: %w", err)
 	}
 
-	toc, res, err := m.parse(content.Bytes())
+	md := m.markdown(m.option(lang, defaults, result.FrontMatter))
+
+	toc, res, errs, err := m.parse(md, content.Bytes())
 	if err != nil {
 		return nil, err
 	}
 	result.Toc = toc
+	result.Errors = errs
 	result.Content = res
 	result.RawSummary = summary.String()
 	result.RawContent = content.String()
 
 	if summary.Len() > 0 {
-		_, res, err := m.parse(summary.Bytes())
+		// 摘要是内容的一部分, 不需要重复记录错误
+		_, res, _, err := m.parse(md, summary.Bytes())
 		if err != nil {
 			return nil, err
 		}
@@ -151,40 +176,20 @@ func (m *mdParser) SupportedExtensions() []string {
 }
 
 func New(opt *Option) *mdParser {
-	exts := []goldmark.Extender{
-		extension.GFM,
-	}
-	if opt.DirectiveBlocks {
-		exts = append(exts, NewDirectiveBlockExtension())
-	}
-	if opt.Style != "" && opt.Style != "none" {
-		exts = append(exts, NewHighlightExtension(opt))
-	}
-	if opt.ShowToc {
-		exts = append(exts, NewTocExtension(opt))
-	}
-
-	rers := make([]renderer.Option, 0)
-	if opt.Unsafe {
-		rers = append(rers, html.WithUnsafe())
-	}
-	md := goldmark.New(
-		goldmark.WithExtensions(exts...),
-		goldmark.WithParserOptions(goldmarkParser.WithAttribute()),
-		goldmark.WithRendererOptions(rers...),
-	)
-	return &mdParser{md: md, opt: opt}
+	return &mdParser{md: newMarkdown(opt), opt: opt}
 }
 
 const parserName = "markdown"
 
 func NewWithContext(ctx *core.Context) *mdParser {
-	opt := &Option{
-		MarkupOption:    parser.NewMarkupOption(ctx, parserName),
-		Unsafe:          ctx.GetMarkupConfig(parserName, "unsafe").Bool(),
-		DirectiveBlocks: ctx.GetMarkupConfig(parserName, "directive_blocks").Bool(),
+	hooks := parser.RenderHookTemplates(ctx)
+
+	m := New(newOption(ctx, hooks))
+	m.langs = make(map[string]*Option)
+	for lang, lctx := range ctx.OtherLanguages {
+		m.langs[lang] = newOption(lctx, hooks)
 	}
-	return New(opt)
+	return m
 }
 
 func init() {
@@ -395,6 +395,23 @@ func TestDirectiveBlocksDisabledByDefault(t *testing.T) {
 	assert.Contains(t, result.Content, `:::center`)
 }
 
+func TestWikiLinks(t *testing.T) {
+	opt := &Option{MarkupOption: parser.MarkupOption{WikiLinks: true}}
+	result := parseMarkdown(t, "see [[Hello World]], [[hello-world|alias <b>]] and [normal](./a.md)\n\n![[cat.png]] ![alt](dog.png)\n", opt)
+
+	assert.Contains(t, result.Content, `<a class="wikilink" href="" data-wikilink="Hello World">Hello World</a>`)
+	assert.Contains(t, result.Content, `<a class="wikilink" href="" data-wikilink="hello-world">alias &lt;b&gt;</a>`)
+	assert.Contains(t, result.Content, `<img class="wikilink" src="" data-wikilink="cat.png" alt="cat.png">`)
+	assert.Contains(t, result.Content, `<a href="./a.md">normal</a>`)
+	assert.Contains(t, result.Content, `<img src="dog.png" alt="alt">`)
+
+	result = parseMarkdown(t, "| a |\n|---|\n| [[Page\\|alias]] |\n", opt)
+	assert.Contains(t, result.Content, `<a class="wikilink" href="" data-wikilink="Page">alias</a>`)
+
+	result = parseMarkdown(t, "[[Hello World]]\n", nil)
+	assert.NotContains(t, result.Content, "data-wikilink")
+}
+
 func TestLongLineCanBeParsed(t *testing.T) {
 	longLine := strings.Repeat("a", 70*1024)
 	result := parseMarkdown(t, longLine+"\n", nil)
@@ -460,3 +477,173 @@ func TestReaderErrorIsReturned(t *testing.T) {
 	assert.ErrorIs(t, err, errMarkdownReader)
 	assert.Contains(t, err.Error(), "markdown parser scan")
 }
+
+func TestRenderHooks(t *testing.T) {
+	opt := &Option{MarkupOption: parser.MarkupOption{ShowToc: true}, RenderHooks: map[string]string{
+		"render-link":              "_markup/render-link.html",
+		"render-image":             "_markup/render-image.html",
+		"render-heading":           "_markup/render-heading.html",
+		"render-codeblock-mermaid": "_markup/render-codeblock-mermaid.html",
+	}}
+	result := parseMarkdown(t, "# Title\n\n[link](./a.md \"t\") ![alt](cat.png)\n\n```mermaid {title=x}\ngraph <TD>\n```\n\n```go\nfunc main() {}\n```\n", opt)
+
+	assert.Contains(t, result.Content, `<h1 id="title" data-render-hook="heading">Title</h1>`)
+	assert.Contains(t, result.Content, `<a href="./a.md" title="t" data-render-hook="link">link</a>`)
+	assert.Contains(t, result.Content, `<img src="cat.png" alt="alt" data-render-hook="image">`)
+	assert.Contains(t, result.Content, `<pre data-render-hook="codeblock" data-lang="mermaid" data-info="{title=x}"><code>graph &lt;TD&gt;`+"\n</code></pre>")
+	assert.NotContains(t, result.Content, `data-lang="go"`)
+	assert.Contains(t, result.Content, "func")
+
+	result = parseMarkdown(t, "# Title\n\n[link](./a.md)\n", nil)
+	assert.NotContains(t, result.Content, "data-render-hook")
+}
+
+func TestExtensions(t *testing.T) {
+	text := "| a |\n|---|\n| b |\n\n~~del~~ https://example.com\n\n\"quote\" :smile:\n\nTerm\n: Definition\n\nnote[^1]\n\n[^1]: footnote\n"
+
+	result := parseMarkdown(t, text, nil)
+	assert.Contains(t, result.Content, "<table>")
+	assert.Contains(t, result.Content, "<del>del</del>")
+	assert.Contains(t, result.Content, `<a href="https://example.com">`)
+	assert.Contains(t, result.Content, "&quot;quote&quot; :smile:")
+	assert.NotContains(t, result.Content, "<dl>")
+	assert.NotContains(t, result.Content, "footnote-ref")
+
+	result = parseMarkdown(t, text, &Option{
+		Extensions: map[string]bool{
+			ExtensionTable:          false,
+			ExtensionFootnote:       true,
+			ExtensionDefinitionList: true,
+			ExtensionTypographer:    true,
+			ExtensionEmoji:          true,
+		},
+		Typographer: map[string]string{
+			"left_double_quote":  "「",
+			"right_double_quote": "」",
+		},
+	})
+	assert.NotContains(t, result.Content, "<table>")
+	assert.Contains(t, result.Content, "<dl>")
+	assert.Contains(t, result.Content, "footnote-ref")
+	assert.Contains(t, result.Content, "「quote」")
+	assert.NotContains(t, result.Content, ":smile:")
+
+	result = parseMarkdown(t, "## Title {#custom .note}\n", &Option{Extensions: map[string]bool{ExtensionAttribute: false}})
+	assert.Contains(t, result.Content, "{#custom .note}")
+}
+
+func TestHardWrapsAndXHTML(t *testing.T) {
+	result := parseMarkdown(t, "a\nb\n\n---\n", nil)
+	assert.Contains(t, result.Content, "<p>a\nb</p>")
+	assert.Contains(t, result.Content, "<hr>")
+
+	result = parseMarkdown(t, "a\nb\n\n---\n", &Option{HardWraps: true, XHTML: true})
+	assert.Contains(t, result.Content, "<p>a<br />\nb</p>")
+	assert.Contains(t, result.Content, "<hr />")
+}
+
+func TestFrontMatterOption(t *testing.T) {
+	r := New(&Option{})
+
+	result, err := r.Parse(strings.NewReader("---\ntitle: a\nmarkdown:\n  hard_wraps: true\n  extensions:\n    footnote: true\n---\na\nb\n\nnote[^1]\n\n[^1]: footnote\n"))
+	require.NoError(t, err)
+	assert.Contains(t, result.Content, "<p>a<br>\nb</p>")
+	assert.Contains(t, result.Content, "footnote-ref")
+
+	// 其它页面不受影响
+	result, err = r.Parse(strings.NewReader("a\nb\n"))
+	require.NoError(t, err)
+	assert.Contains(t, result.Content, "<p>a\nb</p>")
+}
+
+func TestDefaultsOption(t *testing.T) {
+	r := New(&Option{})
+	defaults := map[string]any{"markdown": map[string]any{"hard_wraps": true}}
+
+	result, err := r.ParseDefaults(strings.NewReader("a\nb\n"), "", defaults)
+	require.NoError(t, err)
+	assert.Contains(t, result.Content, "<p>a<br>\nb</p>")
+
+	// 页面front matter优先于cascade
+	result, err = r.ParseDefaults(strings.NewReader("---\nmarkdown:\n  hard_wraps: false\n---\na\nb\n"), "", defaults)
+	require.NoError(t, err)
+	assert.Contains(t, result.Content, "<p>a\nb</p>")
+}
+
+func TestLangOption(t *testing.T) {
+	r := New(&Option{})
+	r.langs = map[string]*Option{
+		"zh": {
+			Extensions:  map[string]bool{ExtensionTypographer: true},
+			Typographer: map[string]string{"left_double_quote": "「", "right_double_quote": "」"},
+		},
+	}
+
+	result, err := r.ParseLang(strings.NewReader("\"quote\"\n"), "zh")
+	require.NoError(t, err)
+	assert.Contains(t, result.Content, "「quote」")
+
+	result, err = r.ParseLang(strings.NewReader("\"quote\"\n"), "en")
+	require.NoError(t, err)
+	assert.Contains(t, result.Content, "&quot;quote&quot;")
+
+	// front matter中的lang优先
+	result, err = r.ParseLang(strings.NewReader("lang: zh\n\n\"quote\"\n"), "")
+	require.NoError(t, err)
+	assert.Contains(t, result.Content, "「quote」")
+}
+
+func TestMath(t *testing.T) {
+	text := "inline $a^2$ and \\(b_1\\), costs $5 and $10\n\n$$\n\\frac{1}{2}\n$$\n\n\\[x=1\\]\n\n`$code$`\n\n```\n$x$\n```\n"
+
+	result := parseMarkdown(t, text, &Option{MarkupOption: parser.MarkupOption{Math: parser.MathSpan}})
+	assert.Contains(t, result.Content, `<span class="math inline">\(a^2\)</span>`)
+	assert.Contains(t, result.Content, `<span class="math inline">\(b_1\)</span>`)
+	assert.Contains(t, result.Content, "costs $5 and $10")
+	assert.Contains(t, result.Content, `<span class="math display">\[\frac{1}{2}\]</span>`)
+	assert.Contains(t, result.Content, `<span class="math display">\[x=1\]</span>`)
+	assert.Contains(t, result.Content, "<code>$code$</code>")
+	assert.Contains(t, result.Content, "<code>$x$\n</code>")
+
+	result = parseMarkdown(t, text, &Option{MarkupOption: parser.MarkupOption{Math: parser.MathMathML}})
+	assert.Contains(t, result.Content, `<math `)
+	assert.Contains(t, result.Content, `<mfrac><mn>1</mn><mn>2</mn></mfrac>`)
+	assert.Contains(t, result.Content, `<annotation encoding="application/x-tex">a^2</annotation>`)
+
+	result = parseMarkdown(t, text, nil)
+	assert.NotContains(t, result.Content, "math")
+
+	result = parseMarkdown(t, "bad $\\frac{1}{$ math\n\n<!--more-->\n", &Option{MarkupOption: parser.MarkupOption{Math: parser.MathMathML}})
+	assert.Contains(t, result.Content, `<span class="math inline">\(\frac{1}{\)</span>`)
+	assert.Equal(t, []string{`invalid math "\\frac{1}{": mismatched curly brace at position 4`}, result.Errors)
+}
+
+func TestHighlightClasses(t *testing.T) {
+	text := "```go\nfunc main() {}\n```\n"
+
+	result := parseMarkdown(t, text, &Option{MarkupOption: parser.MarkupOption{Style: "monokai", HighlightMode: parser.HighlightModeClasses}})
+	assert.Contains(t, result.Content, `<pre class="chroma">`)
+	assert.Contains(t, result.Content, `<span class="kd">func</span>`)
+	assert.NotContains(t, result.Content, "style=")
+
+	result = parseMarkdown(t, text, &Option{MarkupOption: parser.MarkupOption{Style: "monokai"}})
+	assert.Contains(t, result.Content, "style=")
+}
+
+func TestCodeAttributes(t *testing.T) {
+	text := "```go {hl_lines=\"2\" linenostart=10 title=\"main.go\"}\na := 1\nb := 2\n```\n"
+
+	opt := &Option{MarkupOption: parser.MarkupOption{Style: "monokai", ShowLineNumbers: true, HighlightMode: parser.HighlightModeClasses}}
+	result := parseMarkdown(t, text, opt)
+	assert.Contains(t, result.Content, "<figcaption>main.go</figcaption>")
+	assert.Contains(t, result.Content, `<span class="line hl"><span class="ln">11</span>`)
+
+	result = parseMarkdown(t, "```go {linenos=false}\na := 1\n```\n", opt)
+	assert.NotContains(t, result.Content, `class="ln"`)
+
+	result = parseMarkdown(t, "```go {highlight=false}\na := 1\n```\n", opt)
+	assert.Contains(t, result.Content, `<pre><code class="language-go">a := 1`)
+
+	result = parseMarkdown(t, text, &Option{MarkupOption: parser.MarkupOption{Style: "none"}})
+	assert.Contains(t, result.Content, "<figcaption>main.go</figcaption>\n<pre><code class=\"language-go\">a := 1")
+}
@@ -0,0 +1,302 @@
+package markdown
+
+import (
+	"bytes"
+
+	contentparser "github.com/honmaple/snow/internal/site/content/parser"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var (
+	kindMathInline = ast.NewNodeKind("MathInline")
+	kindMathBlock  = ast.NewNodeKind("MathBlock")
+	// 公式转换失败的错误, 内容仍然会使用原始公式输出
+	mathErrorsKey = parser.NewContextKey()
+)
+
+var mathDelimiters = [][2]string{
+	{"$$", "$$"},
+	{`\[`, `\]`},
+	{`\(`, `\)`},
+	{"$", "$"},
+}
+
+type (
+	mathInline struct {
+		ast.BaseInline
+		html     string
+		display  bool
+		segments *text.Segments
+	}
+	mathBlock struct {
+		ast.BaseBlock
+		html   string
+		closer []byte
+		closed bool
+	}
+)
+
+func (n *mathInline) Kind() ast.NodeKind {
+	return kindMathInline
+}
+
+func (n *mathInline) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+func (n *mathInline) tex(source []byte) string {
+	var buf bytes.Buffer
+	for i := 0; i < n.segments.Len(); i++ {
+		segment := n.segments.At(i)
+		buf.Write(segment.Value(source))
+	}
+	return string(bytes.TrimSpace(buf.Bytes()))
+}
+
+func (n *mathBlock) Kind() ast.NodeKind {
+	return kindMathBlock
+}
+
+func (n *mathBlock) IsRaw() bool {
+	return true
+}
+
+func (n *mathBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+func (n *mathBlock) tex(source []byte) string {
+	var buf bytes.Buffer
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		buf.Write(line.Value(source))
+	}
+	return string(bytes.TrimSpace(buf.Bytes()))
+}
+
+// mathOpener 返回行首的公式分隔符
+func mathOpener(line []byte) (string, string, bool) {
+	for _, d := range mathDelimiters {
+		if bytes.HasPrefix(line, []byte(d[0])) {
+			return d[0], d[1], d[0] == "$$" || d[0] == `\[`
+		}
+	}
+	return "", "", false
+}
+
+// mathCloser 查找结束分隔符, 单个$需要满足pandoc的规则, 避免误识别金额
+func mathCloser(line []byte, closer string) int {
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' && closer == "$" {
+			i++
+			continue
+		}
+		if !bytes.HasPrefix(line[i:], []byte(closer)) {
+			continue
+		}
+		if closer != "$" {
+			return i
+		}
+		if i == 0 || util.IsSpace(line[i-1]) {
+			continue
+		}
+		if next := i + 1; next < len(line) && line[next] >= '0' && line[next] <= '9' {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// mathInlineParser 解析段落中的$...$, $$...$$, \(...\)和\[...\]
+type mathInlineParser struct{}
+
+func (p *mathInlineParser) Trigger() []byte {
+	return []byte{'$', '\\'}
+}
+
+func (p *mathInlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	opener, closer, display := mathOpener(line)
+	if opener == "" {
+		return nil
+	}
+	single := opener == "$"
+	if single && (len(line) < 2 || util.IsSpace(line[1])) {
+		return nil
+	}
+
+	block.Advance(len(opener))
+	node := &mathInline{display: display, segments: text.NewSegments()}
+	for {
+		line, segment := block.PeekLine()
+		if line == nil {
+			return nil
+		}
+		if idx := mathCloser(line, closer); idx >= 0 {
+			if idx == 0 && node.segments.Len() == 0 {
+				return nil
+			}
+			node.segments.Append(segment.WithStop(segment.Start + idx))
+			block.Advance(idx + len(closer))
+			return node
+		}
+		// 单个$不允许跨行
+		if single {
+			return nil
+		}
+		node.segments.Append(segment)
+		block.AdvanceLine()
+	}
+}
+
+// mathBlockParser 解析单独成行的$$和\[公式块
+type mathBlockParser struct{}
+
+func (p *mathBlockParser) Trigger() []byte {
+	return []byte{'$', '\\'}
+}
+
+func (p *mathBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	if pc.BlockIndent() > 3 {
+		return nil, parser.NoChildren
+	}
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+	opener, closer, display := mathOpener(line[pos:])
+	if !display {
+		return nil, parser.NoChildren
+	}
+
+	node := &mathBlock{closer: []byte(closer)}
+	start := segment.Start + pos + len(opener)
+	rest := line[pos+len(opener):]
+	if idx := bytes.Index(rest, node.closer); idx >= 0 {
+		// 结束分隔符后还有其它内容时作为行内公式处理
+		if !util.IsBlank(rest[idx+len(closer):]) {
+			return nil, parser.NoChildren
+		}
+		node.Lines().Append(text.NewSegment(start, start+idx))
+		node.closed = true
+	} else if !util.IsBlank(rest) {
+		node.Lines().Append(text.NewSegment(start, segment.Stop))
+	}
+	reader.AdvanceToEOL()
+	return node, parser.NoChildren
+}
+
+func (p *mathBlockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	block := node.(*mathBlock)
+	if block.closed {
+		return parser.Close
+	}
+	line, segment := reader.PeekLine()
+	if idx := bytes.Index(line, block.closer); idx >= 0 {
+		if idx > 0 {
+			block.Lines().Append(text.NewSegment(segment.Start, segment.Start+idx))
+		}
+		reader.AdvanceToEOL()
+		return parser.Close
+	}
+	block.Lines().Append(segment)
+	reader.AdvanceToEOL()
+	return parser.Continue | parser.NoChildren
+}
+
+func (p *mathBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (p *mathBlockParser) CanInterruptParagraph() bool {
+	return false
+}
+
+func (p *mathBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// mathTransformer 解析完成后转换公式, 记录转换失败的错误
+type mathTransformer struct {
+	mode string
+}
+
+func (t *mathTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	errs := make([]string, 0)
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var err error
+		switch n := node.(type) {
+		case *mathInline:
+			n.html, err = contentparser.MathHTML(t.mode, n.tex(source), n.display)
+		case *mathBlock:
+			n.html, err = contentparser.MathHTML(t.mode, n.tex(source), true)
+		default:
+			return ast.WalkContinue, nil
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		return ast.WalkSkipChildren, nil
+	})
+	if len(errs) > 0 {
+		pc.Set(mathErrorsKey, errs)
+	}
+}
+
+type mathRenderer struct{}
+
+func (r *mathRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindMathInline, r.renderMathInline)
+	reg.Register(kindMathBlock, r.renderMathBlock)
+}
+
+func (r *mathRenderer) renderMathInline(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	_, err := w.WriteString(node.(*mathInline).html)
+	return ast.WalkSkipChildren, err
+}
+
+func (r *mathRenderer) renderMathBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	_, err := w.WriteString(node.(*mathBlock).html + "\n")
+	return ast.WalkSkipChildren, err
+}
+
+type mathExtension struct {
+	mode string
+}
+
+func (e *mathExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(
+			util.Prioritized(&mathBlockParser{}, 150),
+		),
+		parser.WithInlineParsers(
+			util.Prioritized(&mathInlineParser{}, 150),
+		),
+		parser.WithASTTransformers(
+			util.Prioritized(&mathTransformer{mode: e.mode}, 500),
+		),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&mathRenderer{}, 500),
+	))
+}
+
+// NewMathExtension 在构建时渲染latex公式, mode可选mathml和span
+func NewMathExtension(mode string) goldmark.Extender {
+	return &mathExtension{mode: mode}
+}
@@ -0,0 +1,196 @@
+package markdown
+
+import (
+	"encoding/json"
+	"maps"
+
+	"github.com/honmaple/snow/internal/site/content/parser"
+	"github.com/spf13/cast"
+	"github.com/yuin/goldmark"
+	emoji "github.com/yuin/goldmark-emoji"
+	"github.com/yuin/goldmark/extension"
+	goldmarkParser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+const (
+	ExtensionTable          = "table"
+	ExtensionStrikethrough  = "strikethrough"
+	ExtensionTaskList       = "tasklist"
+	ExtensionLinkify        = "linkify"
+	ExtensionFootnote       = "footnote"
+	ExtensionDefinitionList = "definition_list"
+	ExtensionTypographer    = "typographer"
+	ExtensionEmoji          = "emoji"
+	ExtensionAttribute      = "attribute"
+)
+
+// 默认启用GFM和属性, 与之前的行为保持一致
+var defaultExtensions = map[string]bool{
+	ExtensionTable:          true,
+	ExtensionStrikethrough:  true,
+	ExtensionTaskList:       true,
+	ExtensionLinkify:        true,
+	ExtensionAttribute:      true,
+	ExtensionFootnote:       false,
+	ExtensionDefinitionList: false,
+	ExtensionTypographer:    false,
+	ExtensionEmoji:          false,
+}
+
+var typographicPunctuations = map[string]extension.TypographicPunctuation{
+	"left_single_quote":  extension.LeftSingleQuote,
+	"right_single_quote": extension.RightSingleQuote,
+	"left_double_quote":  extension.LeftDoubleQuote,
+	"right_double_quote": extension.RightDoubleQuote,
+	"en_dash":            extension.EnDash,
+	"em_dash":            extension.EmDash,
+	"ellipsis":           extension.Ellipsis,
+	"left_angle_quote":   extension.LeftAngleQuote,
+	"right_angle_quote":  extension.RightAngleQuote,
+	"apostrophe":         extension.Apostrophe,
+}
+
+func (opt *Option) Extension(name string) bool {
+	if v, ok := opt.Extensions[name]; ok {
+		return v
+	}
+	return defaultExtensions[name]
+}
+
+// apply 使用front matter中的markdown配置覆盖当前配置
+//
+//	markdown:
+//	  hard_wraps: true
+//	  extensions:
+//	    footnote: true
+func (opt *Option) apply(values map[string]any) *Option {
+	newOpt := *opt
+	newOpt.Extensions = maps.Clone(opt.Extensions)
+	newOpt.Typographer = maps.Clone(opt.Typographer)
+	if newOpt.Extensions == nil {
+		newOpt.Extensions = make(map[string]bool)
+	}
+	if newOpt.Typographer == nil {
+		newOpt.Typographer = make(map[string]string)
+	}
+
+	for k, v := range values {
+		switch k {
+		case "unsafe":
+			newOpt.Unsafe = cast.ToBool(v)
+		case "hard_wraps":
+			newOpt.HardWraps = cast.ToBool(v)
+		case "xhtml":
+			newOpt.XHTML = cast.ToBool(v)
+		case "extensions":
+			for name, enabled := range cast.ToStringMap(v) {
+				newOpt.Extensions[name] = cast.ToBool(enabled)
+			}
+		case "typographer":
+			for name, value := range cast.ToStringMap(v) {
+				newOpt.Typographer[name] = cast.ToString(value)
+			}
+		}
+	}
+	return &newOpt
+}
+
+// key 相同配置的页面共用goldmark实例
+func (opt *Option) key() string {
+	buf, _ := json.Marshal(opt)
+	return string(buf)
+}
+
+func newOption(ctx parser.MarkupConfig, hooks map[string]string) *Option {
+	opt := &Option{
+		MarkupOption:    parser.NewMarkupOption(ctx, parserName),
+		Unsafe:          ctx.GetMarkupConfig(parserName, "unsafe").Bool(),
+		HardWraps:       ctx.GetMarkupConfig(parserName, "hard_wraps").Bool(),
+		XHTML:           ctx.GetMarkupConfig(parserName, "xhtml").Bool(),
+		DirectiveBlocks: ctx.GetMarkupConfig(parserName, "directive_blocks").Bool(),
+		Extensions:      make(map[string]bool),
+		Typographer:     make(map[string]string),
+		RenderHooks:     hooks,
+	}
+	for name, enabled := range ctx.GetMarkupConfig(parserName, "extensions").StringMap() {
+		opt.Extensions[name] = cast.ToBool(enabled)
+	}
+	for name, value := range ctx.GetMarkupConfig(parserName, "typographer").StringMap() {
+		opt.Typographer[name] = cast.ToString(value)
+	}
+	return opt
+}
+
+func newMarkdown(opt *Option) goldmark.Markdown {
+	exts := make([]goldmark.Extender, 0)
+	if opt.Extension(ExtensionTable) {
+		exts = append(exts, extension.Table)
+	}
+	if opt.Extension(ExtensionStrikethrough) {
+		exts = append(exts, extension.Strikethrough)
+	}
+	if opt.Extension(ExtensionTaskList) {
+		exts = append(exts, extension.TaskList)
+	}
+	if opt.Extension(ExtensionLinkify) {
+		exts = append(exts, extension.Linkify)
+	}
+	if opt.Extension(ExtensionFootnote) {
+		exts = append(exts, extension.Footnote)
+	}
+	if opt.Extension(ExtensionDefinitionList) {
+		exts = append(exts, extension.DefinitionList)
+	}
+	if opt.Extension(ExtensionTypographer) {
+		substitutions := make(extension.TypographicSubstitutions)
+		for name, value := range opt.Typographer {
+			if punctuation, ok := typographicPunctuations[name]; ok {
+				substitutions[punctuation] = []byte(value)
+			}
+		}
+		exts = append(exts, extension.NewTypographer(extension.WithTypographicSubstitutions(substitutions)))
+	}
+	if opt.Extension(ExtensionEmoji) {
+		exts = append(exts, emoji.Emoji)
+	}
+	if opt.DirectiveBlocks {
+		exts = append(exts, NewDirectiveBlockExtension())
+	}
+	if opt.WikiLinks {
+		exts = append(exts, NewWikiLinkExtension())
+	}
+	if opt.Math != "" {
+		exts = append(exts, NewMathExtension(opt.Math))
+	}
+	if len(opt.RenderHooks) > 0 {
+		exts = append(exts, NewRenderHookExtension(opt.RenderHooks))
+	}
+	// 没有开启语法高亮时也需要处理代码块属性
+	exts = append(exts, NewHighlightExtension(opt))
+	if opt.ShowToc {
+		exts = append(exts, NewTocExtension(opt))
+	}
+
+	parserOpts := make([]goldmarkParser.Option, 0)
+	if opt.Extension(ExtensionAttribute) {
+		parserOpts = append(parserOpts, goldmarkParser.WithAttribute())
+	}
+
+	rers := make([]renderer.Option, 0)
+	if opt.Unsafe {
+		rers = append(rers, html.WithUnsafe())
+	}
+	if opt.HardWraps {
+		rers = append(rers, html.WithHardWraps())
+	}
+	if opt.XHTML {
+		rers = append(rers, html.WithXHTML())
+	}
+	return goldmark.New(
+		goldmark.WithExtensions(exts...),
+		goldmark.WithParserOptions(parserOpts...),
+		goldmark.WithRendererOptions(rers...),
+	)
+}
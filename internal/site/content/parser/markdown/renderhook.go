@@ -0,0 +1,129 @@
+package markdown
+
+import (
+	"bytes"
+	stdhtml "html"
+
+	contentparser "github.com/honmaple/snow/internal/site/content/parser"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var kindRenderHookCodeBlock = ast.NewNodeKind("RenderHookCodeBlock")
+
+// renderHookCodeBlock 需要使用渲染模版的代码块
+type renderHookCodeBlock struct {
+	ast.BaseBlock
+	lang string
+	info string
+	code []byte
+}
+
+func (n *renderHookCodeBlock) Kind() ast.NodeKind {
+	return kindRenderHookCodeBlock
+}
+
+func (n *renderHookCodeBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Lang": n.lang,
+		"Info": n.info,
+	}, nil)
+}
+
+// renderHookExtension 给存在渲染模版的元素添加标记, 由shortcode hook在渲染页面时执行模版
+type renderHookExtension struct {
+	hooks map[string]string
+}
+
+func (e *renderHookExtension) has(kind string, lang string) bool {
+	if _, ok := e.hooks[contentparser.RenderHookName(kind, lang)]; ok {
+		return true
+	}
+	_, ok := e.hooks[contentparser.RenderHookName(kind, "")]
+	return ok
+}
+
+func (e *renderHookExtension) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+
+	blocks := make([]*ast.FencedCodeBlock, 0)
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var kind string
+		switch n.Kind() {
+		case ast.KindLink:
+			kind = contentparser.RenderHookLink
+		case ast.KindImage:
+			kind = contentparser.RenderHookImage
+		case ast.KindHeading:
+			kind = contentparser.RenderHookHeading
+		case ast.KindFencedCodeBlock:
+			if block := n.(*ast.FencedCodeBlock); e.has(contentparser.RenderHookCodeBlock, string(block.Language(source))) {
+				blocks = append(blocks, block)
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		if kind != "" && e.has(kind, "") {
+			n.SetAttributeString(contentparser.RenderHookAttr, []byte(kind))
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, block := range blocks {
+		var buf bytes.Buffer
+		for i := 0; i < block.Lines().Len(); i++ {
+			line := block.Lines().At(i)
+			buf.Write(line.Value(source))
+		}
+		lang := string(block.Language(source))
+
+		info := ""
+		if block.Info != nil {
+			info = string(bytes.TrimSpace(bytes.TrimPrefix(bytes.TrimSpace(block.Info.Segment.Value(source)), []byte(lang))))
+		}
+		newBlock := &renderHookCodeBlock{lang: lang, info: info, code: buf.Bytes()}
+		block.Parent().ReplaceChild(block.Parent(), block, newBlock)
+	}
+}
+
+func (e *renderHookExtension) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindRenderHookCodeBlock, e.renderCodeBlock)
+}
+
+func (e *renderHookExtension) renderCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	block := node.(*renderHookCodeBlock)
+
+	_, _ = w.WriteString(`<pre ` + contentparser.RenderHookAttr + `="` + contentparser.RenderHookCodeBlock + `"`)
+	if block.lang != "" {
+		_, _ = w.WriteString(` data-lang="` + stdhtml.EscapeString(block.lang) + `"`)
+	}
+	if block.info != "" {
+		_, _ = w.WriteString(` data-info="` + stdhtml.EscapeString(block.info) + `"`)
+	}
+	_, _ = w.WriteString("><code>")
+	_, _ = w.WriteString(stdhtml.EscapeString(string(block.code)))
+	_, err := w.WriteString("</code></pre>\n")
+	return ast.WalkSkipChildren, err
+}
+
+func (e *renderHookExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		util.Prioritized(e, 200),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(e, 500),
+	))
+}
+
+func NewRenderHookExtension(hooks map[string]string) goldmark.Extender {
+	return &renderHookExtension{hooks: hooks}
+}
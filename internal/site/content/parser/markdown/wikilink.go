@@ -0,0 +1,119 @@
+package markdown
+
+import (
+	"bytes"
+	stdhtml "html"
+
+	contentparser "github.com/honmaple/snow/internal/site/content/parser"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var kindWikiLink = ast.NewNodeKind("WikiLink")
+
+type wikiLink struct {
+	ast.BaseInline
+	target string
+	alias  string
+	embed  bool
+}
+
+func (n *wikiLink) Kind() ast.NodeKind {
+	return kindWikiLink
+}
+
+func (n *wikiLink) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Target": n.target,
+		"Alias":  n.alias,
+	}, nil)
+}
+
+// wikiLinkParser 解析[[Page]], [[Page|alias]]和![[image.png]]
+type wikiLinkParser struct{}
+
+func (p *wikiLinkParser) Trigger() []byte {
+	return []byte{'!', '['}
+}
+
+func (p *wikiLinkParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+
+	embed := false
+	if len(line) > 0 && line[0] == '!' {
+		embed = true
+		line = line[1:]
+	}
+	if !bytes.HasPrefix(line, []byte("[[")) {
+		return nil
+	}
+	end := bytes.Index(line[2:], []byte("]]"))
+	if end <= 0 {
+		return nil
+	}
+	inner := line[2 : end+2]
+	if bytes.ContainsAny(inner, "[]\n") {
+		return nil
+	}
+
+	target, alias, _ := bytes.Cut(inner, []byte("|"))
+	// 表格中需要使用\|分隔
+	target = bytes.TrimSuffix(target, []byte("\\"))
+	target = bytes.TrimSpace(target)
+	if len(target) == 0 {
+		return nil
+	}
+
+	consumed := end + 4
+	if embed {
+		consumed++
+	}
+	block.Advance(consumed)
+	return &wikiLink{
+		target: string(target),
+		alias:  string(bytes.TrimSpace(alias)),
+		embed:  embed,
+	}
+}
+
+type wikiLinkRenderer struct{}
+
+func (r *wikiLinkRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindWikiLink, r.renderWikiLink)
+}
+
+func (r *wikiLinkRenderer) renderWikiLink(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	link, ok := node.(*wikiLink)
+	if !ok {
+		return ast.WalkContinue, nil
+	}
+	text := link.alias
+	if text == "" {
+		text = link.target
+	}
+	_, err := w.WriteString(contentparser.WikiLinkHTML(link.target, stdhtml.EscapeString(text), link.embed))
+	return ast.WalkSkipChildren, err
+}
+
+type wikiLinkExtension struct{}
+
+func (e *wikiLinkExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(
+		// 需要在链接解析之前
+		util.Prioritized(&wikiLinkParser{}, 199),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&wikiLinkRenderer{}, 500),
+	))
+}
+
+func NewWikiLinkExtension() goldmark.Extender {
+	return &wikiLinkExtension{}
+}
@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/wyatt915/treeblood"
+)
+
+const (
+	// MathMathML 构建时把latex公式转换为MathML
+	MathMathML = "mathml"
+	// MathSpan 只使用<span class="math">包裹公式, 由客户端(KaTeX/MathJax)渲染
+	MathSpan = "span"
+)
+
+// IsMathMode 判断是否是支持的公式渲染方式
+func IsMathMode(mode string) bool {
+	return mode == MathMathML || mode == MathSpan
+}
+
+// MathHTML 渲染latex公式, tex不包含$或者\(等分隔符, MathML转换失败时使用span并返回错误
+func MathHTML(mode string, tex string, display bool) (string, error) {
+	var err error
+	if mode == MathMathML {
+		// Pitziil不是并发安全的, 每次转换时重新创建
+		pitz := treeblood.NewPitziil()
+		pitz.PrintOneLine = true
+
+		var result string
+		if display {
+			result, err = pitz.DisplayStyle(tex)
+		} else {
+			result, err = pitz.TextStyle(tex)
+		}
+		if err == nil {
+			return strings.TrimSpace(result), nil
+		}
+		// treeblood的错误信息包含用于显示位置的html
+		msg, _, _ := strings.Cut(err.Error(), "<pre>")
+		err = fmt.Errorf("invalid math %q: %s", tex, strings.TrimSpace(msg))
+	}
+	if display {
+		return `<span class="math display">\[` + html.EscapeString(tex) + `\]</span>`, err
+	}
+	return `<span class="math inline">\(` + html.EscapeString(tex) + `\)</span>`, err
+}
@@ -5,47 +5,34 @@ import (
 	"html"
 	"strings"
 
-	"github.com/alecthomas/chroma/v2"
-	"github.com/alecthomas/chroma/v2/lexers"
-	"github.com/alecthomas/chroma/v2/styles"
 	contentparser "github.com/honmaple/snow/internal/site/content/parser"
 )
 
 type Renderer struct {
-	opt       *Option
-	formatter *contentparser.HTMLFormatter
+	opt *Option
 }
 
 func (r *Renderer) highlightCodeBlock(source, lang string, inline bool, params map[string]string) string {
-	if r.opt.Style == "" || r.opt.Style == "none" {
-		return fmt.Sprintf("<pre>\n%s\n</pre>", html.EscapeString(source))
+	// go-org的参数带有":"前缀
+	attrs := make(map[string]string)
+	for k, v := range params {
+		if k, ok := strings.CutPrefix(k, ":"); ok && k != "lang" {
+			attrs[k] = v
+		}
 	}
+	codeAttrs := contentparser.NewCodeAttributes(attrs)
 
-	var w strings.Builder
-	var lexer chroma.Lexer
-
-	if lang == "" || lang == "text" || lang == "example" {
-		lexer = lexers.Analyse(source)
-	} else {
-		lexer = lexers.Get(lang)
-	}
-	if lexer == nil {
-		lexer = lexers.Fallback
+	if (r.opt.Style == "" || r.opt.Style == "none") && codeAttrs == nil {
+		return fmt.Sprintf("<pre>\n%s\n</pre>", html.EscapeString(source))
 	}
-
-	style := styles.Get(r.opt.Style)
-	if style == nil {
-		style = styles.Fallback
+	if lang == "text" || lang == "example" {
+		lang = ""
 	}
-
-	it, _ := lexer.Tokenise(nil, source)
-	r.formatter.Format(&w, style, it)
-	return w.String()
+	return contentparser.HighlightCode(r.opt.MarkupOption, lang, source, codeAttrs)
 }
 
 func NewRenderer(opt *Option) *Renderer {
 	return &Renderer{
-		opt:       opt,
-		formatter: contentparser.NewHTMLFormatter(opt.MarkupOption),
+		opt: opt,
 	}
 }
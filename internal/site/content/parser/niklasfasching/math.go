@@ -0,0 +1,46 @@
+package niklasfasching
+
+import (
+	"slices"
+	"strings"
+
+	contentparser "github.com/honmaple/snow/internal/site/content/parser"
+	org "github.com/niklasfasching/go-org/org"
+)
+
+func (w *htmlWriter) writeMath(tex string, display bool) {
+	s, err := contentparser.MathHTML(w.opt.Math, tex, display)
+	// 标题会在目录中重复渲染
+	if err != nil && !slices.Contains(w.errs, err.Error()) {
+		w.errs = append(w.errs, err.Error())
+	}
+	w.WriteString(s)
+}
+
+// WriteLatexFragment 渲染$...$, $$...$$, \(...\)和\[...\]
+func (w *htmlWriter) WriteLatexFragment(l org.LatexFragment) {
+	if w.opt.Math == "" {
+		w.HTMLWriter.WriteLatexFragment(l)
+		return
+	}
+	tex := strings.TrimSpace(org.String(l.Content...))
+	switch l.OpeningPair {
+	case "$$", `\[`:
+		w.writeMath(tex, true)
+	case "$", `\(`:
+		w.writeMath(tex, false)
+	default:
+		// \begin{env}...\end{env}
+		w.writeMath(l.OpeningPair+tex+l.ClosingPair, true)
+	}
+}
+
+// WriteLatexBlock 渲染单独成行的\begin{env}...\end{env}
+func (w *htmlWriter) WriteLatexBlock(b org.LatexBlock) {
+	if w.opt.Math == "" {
+		w.HTMLWriter.WriteLatexBlock(b)
+		return
+	}
+	w.writeMath(strings.TrimSpace(org.String(b.Content...)), true)
+	w.WriteString("\n")
+}
@@ -27,32 +27,56 @@ type Option struct {
 	parser.MarkupOption
 }
 
+// htmlWriter 扩展org.HTMLWriter, 支持wiki链接和公式
+type htmlWriter struct {
+	*org.HTMLWriter
+	opt *Option
+	// 公式转换失败的错误
+	errs []string
+}
+
+func newHTMLWriter(writer *org.HTMLWriter, opt *Option) *htmlWriter {
+	w := &htmlWriter{HTMLWriter: writer, opt: opt}
+	writer.ExtendingWriter = w
+	return w
+}
+
 type orgParser struct {
 	opt      *Option
 	renderer *Renderer
 }
 
-func (p *orgParser) parse(data []byte) ([]*parser.Heading, string, error) {
+func (p *orgParser) parse(data []byte) ([]*parser.Heading, map[string]string, string, []string, error) {
 	conf := org.New().Silent()
 	conf.DefaultSettings["OPTIONS"] = "toc:nil title:nil <:t e:t f:t pri:t todo:t tags:t ealb:nil"
 
 	doc := conf.Parse(bytes.NewReader(data), ".")
 	if doc.Error != nil {
-		return nil, "", doc.Error
+		return nil, nil, "", nil, doc.Error
 	}
 
+	var extending *htmlWriter
+
 	writer := org.NewHTMLWriter()
 	writer.HighlightCodeBlock = p.renderer.highlightCodeBlock
-	toc := p.toc(doc, writer)
+	if p.opt.WikiLinks || p.opt.Math != "" {
+		extending = newHTMLWriter(writer, p.opt)
+	}
+	toc, ids := p.toc(doc, writer)
 
 	out, err := doc.Write(writer)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, "", nil, err
 	}
-	return toc, out, nil
+	if extending != nil {
+		return toc, ids, out, extending.errs, nil
+	}
+	return toc, ids, out, nil, nil
 }
 
-func (p *orgParser) toc(doc *org.Document, writer *org.HTMLWriter) []*parser.Heading {
+func (p *orgParser) toc(doc *org.Document, writer *org.HTMLWriter) ([]*parser.Heading, map[string]string) {
+	ids := make(map[string]string)
+
 	var walk func([]*org.Section) []*parser.Heading
 	walk = func(sections []*org.Section) []*parser.Heading {
 		headings := make([]*parser.Heading, 0, len(sections))
@@ -66,11 +90,14 @@ func (p *orgParser) toc(doc *org.Document, writer *org.HTMLWriter) []*parser.Hea
 				Title:    writer.WriteNodesAsString(section.Headline.Title...),
 				Children: walk(section.Children),
 			}
+			if id, ok := section.Headline.Properties.Get("ID"); ok && id != "" {
+				ids[id] = heading.Id
+			}
 			headings = append(headings, heading)
 		}
 		return headings
 	}
-	return walk(doc.Outline.Children)
+	return walk(doc.Outline.Children), ids
 }
 
 func (p *orgParser) Parse(r io.Reader) (*parser.Result, error) {
@@ -149,17 +176,23 @@ func (p *orgParser) Parse(r io.Reader) (*parser.Result, error) {
 		return nil, fmt.Errorf("niklasfasching org parser scan: %w", err)
 	}
 
-	toc, res, err := p.parse(content.Bytes())
+	toc, ids, res, errs, err := p.parse(content.Bytes())
 	if err != nil {
 		return nil, err
 	}
+	result.Errors = errs
+	if id, ok := result.FrontMatter["id"].(string); ok && id != "" {
+		ids[id] = ""
+	}
 	result.Toc = toc
+	result.IDs = ids
 	result.Content = res
 	result.RawSummary = summary.String()
 	result.RawContent = content.String()
 
 	if summary.Len() > 0 {
-		_, res, err := p.parse(summary.Bytes())
+		// 摘要是内容的一部分, 不需要重复记录错误
+		_, _, res, _, err := p.parse(summary.Bytes())
 		if err != nil {
 			return nil, err
 		}
@@ -172,6 +205,11 @@ func (p *orgParser) SupportedExtensions() []string {
 	return []string{".org"}
 }
 
+// FrontMatterKeys #+OPTIONS和#+STARTUP用于控制解析
+func (p *orgParser) FrontMatterKeys(string) []string {
+	return []string{"options", "startup"}
+}
+
 func New(opt *Option) *orgParser {
 	return &orgParser{opt: opt, renderer: NewRenderer(opt)}
 }
@@ -225,3 +225,102 @@ func TestTemplateFilter(t *testing.T) {
 	assert.Contains(t, out, "One")
 	assert.Contains(t, out, "outline-container-headline-1")
 }
+
+func TestWikiLinks(t *testing.T) {
+	opt := &Option{MarkupOption: parser.MarkupOption{WikiLinks: true}}
+	result := parseOrg(t, "see [[Hello World]], [[hello-world][alias]], [[./a.org][file]] and [[https://example.com][site]]\n", opt)
+
+	assert.Contains(t, result.Content, `<a class="wikilink" href="" data-wikilink="Hello World">Hello World</a>`)
+	assert.Contains(t, result.Content, `<a class="wikilink" href="" data-wikilink="hello-world">alias</a>`)
+	assert.Contains(t, result.Content, `<a href="./a.html">file</a>`)
+	assert.Contains(t, result.Content, `<a href="https://example.com">site</a>`)
+
+	result = parseOrg(t, "[[Hello World]]\n", nil)
+	assert.NotContains(t, result.Content, "data-wikilink")
+}
+
+func TestOrgRoamIDs(t *testing.T) {
+	result := parseOrg(t, `:PROPERTIES:
+:ID:       8F2C-FILE
+:END:
+#+title: roam
+
+* First
+:PROPERTIES:
+:ID:       8F2C-FIRST
+:END:
+** Second
+:PROPERTIES:
+:ID:       8F2C-SECOND
+:CUSTOM_ID: custom
+:END:
+
+see [[id:8F2C-OTHER][other]]
+`, nil)
+
+	assert.Equal(t, map[string]string{
+		"8F2C-FILE":   "",
+		"8F2C-FIRST":  "headline-1",
+		"8F2C-SECOND": "custom",
+	}, result.IDs)
+	assert.Contains(t, result.Content, `<a href="id:8F2C-OTHER">other</a>`)
+}
+
+func TestMath(t *testing.T) {
+	text := "inline $a_2$ and \\(b_1\\)\n\n\\[x=1\\]\n\n\\begin{align}\na &= b\n\\end{align}\n"
+
+	result := parseOrg(t, text, &Option{MarkupOption: parser.MarkupOption{Math: parser.MathSpan}})
+	assert.Contains(t, result.Content, `<span class="math inline">\(a_2\)</span>`)
+	assert.Contains(t, result.Content, `<span class="math inline">\(b_1\)</span>`)
+	assert.Contains(t, result.Content, `<span class="math display">\[x=1\]</span>`)
+	assert.Contains(t, result.Content, `<span class="math display">\[\begin{align}`)
+
+	result = parseOrg(t, text, &Option{MarkupOption: parser.MarkupOption{Math: parser.MathMathML}})
+	assert.Contains(t, result.Content, `display="inline"`)
+	assert.Contains(t, result.Content, `display="block"`)
+	assert.Contains(t, result.Content, `<annotation encoding="application/x-tex">a_2</annotation>`)
+
+	result = parseOrg(t, text, nil)
+	assert.Contains(t, result.Content, `\(b_1\)`)
+	assert.NotContains(t, result.Content, "<math")
+	result = parseOrg(t, "* Title $x^{$\n\nbad $\\frac{1}{$ math\n", &Option{MarkupOption: parser.MarkupOption{Math: parser.MathMathML}})
+	assert.Contains(t, result.Content, `<span class="math inline">\(\frac{1}{\)</span>`)
+	assert.Equal(t, []string{
+		`invalid math "x^{": mismatched curly brace at position 2`,
+		`invalid math "\\frac{1}{": mismatched curly brace at position 4`,
+	}, result.Errors)
+}
+
+func TestHighlightClasses(t *testing.T) {
+	result := parseOrg(t, "#+begin_src go\nfunc main() {}\n#+end_src\n", &Option{
+		MarkupOption: parser.MarkupOption{
+			Style:         "monokai",
+			HighlightMode: parser.HighlightModeClasses,
+		},
+	})
+	assert.Contains(t, result.Content, `class="chroma"`)
+	assert.Contains(t, result.Content, `<span class="kd">func</span>`)
+	assert.NotContains(t, result.Content, "style=")
+}
+
+func TestCodeAttributes(t *testing.T) {
+	opt := &Option{
+		MarkupOption: parser.MarkupOption{
+			Style:           "monokai",
+			ShowLineNumbers: true,
+			HighlightMode:   parser.HighlightModeClasses,
+		},
+	}
+	result := parseOrg(t, "#+begin_src go :hl_lines 2 :linenostart 10 :title main.go\na := 1\nb := 2\n#+end_src\n", opt)
+	assert.Contains(t, result.Content, "<figcaption>main.go</figcaption>")
+	assert.Contains(t, result.Content, `<span class="line hl"><span class="ln">11</span>`)
+
+	result = parseOrg(t, "#+begin_src go :linenos no\na := 1\n#+end_src\n", opt)
+	assert.NotContains(t, result.Content, `class="ln"`)
+
+	result = parseOrg(t, "#+begin_src go :highlight no\na := 1\n#+end_src\n", opt)
+	assert.Contains(t, result.Content, `<pre><code class="language-go">a := 1`)
+
+	result = parseOrg(t, "#+begin_src go :title main.go\na := 1\n#+end_src\n", &Option{MarkupOption: parser.MarkupOption{Style: "none"}})
+	assert.Contains(t, result.Content, "<figcaption>main.go</figcaption>\n<pre><code class=\"language-go\">a := 1")
+}
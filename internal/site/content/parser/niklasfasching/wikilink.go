@@ -0,0 +1,22 @@
+package niklasfasching
+
+import (
+	"html"
+	"strings"
+
+	contentparser "github.com/honmaple/snow/internal/site/content/parser"
+	org "github.com/niklasfasching/go-org/org"
+)
+
+// WriteRegularLink 把没有协议和扩展名的链接渲染为wiki链接
+func (w *htmlWriter) WriteRegularLink(l org.RegularLink) {
+	if !w.opt.WikiLinks || l.Protocol != "" || !contentparser.IsWikiLinkTarget(l.URL) {
+		w.HTMLWriter.WriteRegularLink(l)
+		return
+	}
+	text := html.EscapeString(l.URL)
+	if l.Description != nil {
+		text = w.WriteNodesAsString(l.Description...)
+	}
+	w.WriteString(contentparser.WikiLinkHTML(strings.TrimSpace(l.URL), text, false))
+}
@@ -0,0 +1,353 @@
+package orgmode
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	stdpath "path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/honmaple/snow/internal/site/content/parser"
+)
+
+var (
+	ORGMODE_INCLUDE     = regexp.MustCompile(`(?i)^\s*#\+include:\s*(.*)$`)
+	ORGMODE_SETUPFILE   = regexp.MustCompile(`(?i)^\s*#\+setupfile:\s*(.*)$`)
+	ORGMODE_MACRO       = regexp.MustCompile(`(?i)^\s*#\+macro:\s+(\S+)\s*(.*)$`)
+	ORGMODE_MACRO_CALL  = regexp.MustCompile(`\{\{\{([^\s(}]+)(?:\((.*?)\))?\}\}\}`)
+	ORGMODE_BLOCK_BEGIN = regexp.MustCompile(`(?i)^\s*#\+begin_(\S+)`)
+	ORGMODE_BLOCK_END   = regexp.MustCompile(`(?i)^\s*#\+end_(\S+)`)
+)
+
+// includeFields 分割#+INCLUDE的参数, 双引号中的空格不会被分割
+func includeFields(s string) []string {
+	var (
+		fields = make([]string, 0)
+		field  strings.Builder
+		quoted bool
+	)
+	for _, c := range s {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ' ' && !quoted:
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteRune(c)
+		}
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+// includeLines 解析:lines参数, 与org-mode相同不包括结束的行, 例如"5-10"为第5到第9行, end为0表示到文件结尾
+func includeLines(value string) (int, int, error) {
+	from, to, found := strings.Cut(strings.TrimSpace(value), "-")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid lines %q", value)
+	}
+	start, end := 1, 0
+	var err error
+	if from = strings.TrimSpace(from); from != "" {
+		if start, err = strconv.Atoi(from); err != nil || start < 1 {
+			return 0, 0, fmt.Errorf("invalid lines %q", value)
+		}
+	}
+	if to = strings.TrimSpace(to); to != "" {
+		if end, err = strconv.Atoi(to); err != nil || end <= start {
+			return 0, 0, fmt.Errorf("invalid lines %q", value)
+		}
+	}
+	return start, end, nil
+}
+
+// orgInclude 处理#+INCLUDE和#+SETUPFILE, 路径相对于当前的文件
+type orgInclude struct {
+	fsys  fs.FS
+	files map[string]bool
+	// #+SETUPFILE中的关键字, 优先级低于当前文件
+	setup *parser.Result
+}
+
+func (inc *orgInclude) read(current string, file string, stack []string) (string, []byte, error) {
+	name := stdpath.Join(stdpath.Dir(current), file)
+	if strings.HasPrefix(file, "/") {
+		name = strings.TrimPrefix(stdpath.Clean(file), "/")
+	}
+	if !fs.ValidPath(name) {
+		return "", nil, fmt.Errorf("%s: invalid include file %q", current, file)
+	}
+	if slices.Contains(stack, name) {
+		return "", nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), name)
+	}
+	data, err := fs.ReadFile(inc.fsys, name)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: include %w", current, err)
+	}
+	inc.files[name] = true
+	return name, data, nil
+}
+
+// include 展开#+INCLUDE, src和example类型使用代码块包裹
+func (inc *orgInclude) include(b *bytes.Buffer, current string, value string, stack []string) (bool, error) {
+	fields := includeFields(value)
+	if len(fields) == 0 {
+		return false, nil
+	}
+
+	kind, params := "", fields[1:]
+	if len(params) > 0 && !strings.HasPrefix(params[0], ":") {
+		kind, params = strings.ToLower(params[0]), params[1:]
+	}
+	// 代码使用/开头的路径或者:region, :symbol参数时交给include shortcode处理
+	if kind == "src" || kind == "example" {
+		if strings.HasPrefix(fields[0], "/") || slices.Contains(params, ":region") || slices.Contains(params, ":symbol") {
+			return false, nil
+		}
+	} else if kind != "" {
+		return false, nil
+	}
+
+	start, end := 1, 0
+	header := make([]string, 0, len(params))
+	for i := 0; i < len(params); i++ {
+		if params[i] != ":lines" {
+			// 参数中的引号已经被删除, 包含空格时需要重新添加
+			if strings.Contains(params[i], " ") {
+				header = append(header, `"`+params[i]+`"`)
+			} else {
+				header = append(header, params[i])
+			}
+			continue
+		}
+		if i+1 >= len(params) {
+			return false, fmt.Errorf("%s: include %s: lines is required", current, fields[0])
+		}
+		i++
+		var err error
+		if start, end, err = includeLines(params[i]); err != nil {
+			return false, fmt.Errorf("%s: include %s: %w", current, fields[0], err)
+		}
+	}
+
+	name, data, err := inc.read(current, fields[0], stack)
+	if err != nil {
+		return false, err
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if start > 1 && start > len(lines) {
+		return false, fmt.Errorf("%s: include %s: lines out of range, file has %d lines", current, fields[0], len(lines))
+	}
+	if end == 0 || end-1 > len(lines) {
+		end = len(lines) + 1
+	}
+	text := strings.Join(lines[start-1:end-1], "")
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	if kind == "" {
+		expanded, err := inc.expand(name, []byte(text), append(stack, name))
+		if err != nil {
+			return false, err
+		}
+		b.Write(expanded)
+		return true, nil
+	}
+
+	if kind == "src" {
+		b.WriteString("#+begin_src")
+	} else {
+		b.WriteString("#+begin_example")
+	}
+	for _, h := range header {
+		b.WriteString(" ")
+		b.WriteString(h)
+	}
+	b.WriteString("\n")
+	b.WriteString(text)
+	b.WriteString("#+end_" + kind + "\n")
+	return true, nil
+}
+
+// setupFile 读取#+SETUPFILE中的关键字, #+MACRO保留在内容中
+func (inc *orgInclude) setupFile(b *bytes.Buffer, current string, value string, stack []string) error {
+	fields := includeFields(value)
+	if len(fields) == 0 {
+		return nil
+	}
+	name, data, err := inc.read(current, fields[0], stack)
+	if err != nil {
+		return err
+	}
+	expanded, err := inc.expand(name, data, append(stack, name))
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(string(expanded), "\n") {
+		if ORGMODE_MACRO.MatchString(line) {
+			b.WriteString(line)
+			b.WriteString("\n")
+			continue
+		}
+		if match := ORGMODE_KEYWORD.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			k, v := keywordFrontMatter(match)
+			if k == "options" {
+				if old, ok := inc.setup.FrontMatter[k].(string); ok {
+					v = mergeOptions(old, v)
+				}
+				inc.setup.FrontMatter[k] = v
+				continue
+			}
+			inc.setup.SetFrontMatter(k, v)
+		}
+	}
+	return nil
+}
+
+// eachLine 依次处理代码块外的每一行, 代码块中的内容保持不变
+func eachLine(data []byte, fn func(string) (string, error)) ([]byte, error) {
+	var (
+		b     bytes.Buffer
+		block string
+	)
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if block != "" {
+			if match := ORGMODE_BLOCK_END.FindStringSubmatch(line); match != nil && strings.EqualFold(match[1], block) {
+				block = ""
+			}
+			b.WriteString(line)
+			continue
+		}
+		if match := ORGMODE_BLOCK_BEGIN.FindStringSubmatch(line); match != nil {
+			block = match[1]
+			b.WriteString(line)
+			continue
+		}
+		newLine, err := fn(line)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(newLine)
+	}
+	return b.Bytes(), nil
+}
+
+// expand 展开内容中的#+INCLUDE和#+SETUPFILE
+func (inc *orgInclude) expand(current string, data []byte, stack []string) ([]byte, error) {
+	return eachLine(data, func(line string) (string, error) {
+		var b bytes.Buffer
+
+		text := strings.TrimRight(line, "\r\n")
+		if match := ORGMODE_INCLUDE.FindStringSubmatch(text); match != nil {
+			ok, err := inc.include(&b, current, match[1], stack)
+			if err != nil || ok {
+				return b.String(), err
+			}
+		} else if match := ORGMODE_SETUPFILE.FindStringSubmatch(text); match != nil {
+			err := inc.setupFile(&b, current, match[1], stack)
+			return b.String(), err
+		}
+		return line, nil
+	})
+}
+
+// Includes 返回展开时读取的所有文件
+func (inc *orgInclude) Includes() []string {
+	files := make([]string, 0, len(inc.files))
+	for file := range inc.files {
+		files = append(files, file)
+	}
+	slices.Sort(files)
+	return files
+}
+
+func newOrgInclude(fsys fs.FS) *orgInclude {
+	return &orgInclude{
+		fsys:  fsys,
+		files: make(map[string]bool),
+		setup: &parser.Result{FrontMatter: make(map[string]any)},
+	}
+}
+
+// mergeOptions 合并#+OPTIONS, 例如"toc:t num:nil"和"toc:nil"合并为"toc:nil num:nil"
+func mergeOptions(base string, override string) string {
+	options := strings.Fields(base)
+	for _, opt := range strings.Fields(override) {
+		key, _, _ := strings.Cut(opt, ":")
+		i := slices.IndexFunc(options, func(s string) bool {
+			k, _, _ := strings.Cut(s, ":")
+			return k == key
+		})
+		if i >= 0 {
+			options[i] = opt
+		} else {
+			options = append(options, opt)
+		}
+	}
+	return strings.Join(options, " ")
+}
+
+// orgMacros #+MACRO: name body, body中使用$1, $2作为参数
+type orgMacros map[string]string
+
+func (m orgMacros) expandLine(line string) string {
+	return ORGMODE_MACRO_CALL.ReplaceAllStringFunc(line, func(s string) string {
+		match := ORGMODE_MACRO_CALL.FindStringSubmatch(s)
+		body, ok := m[strings.ToLower(match[1])]
+		if !ok {
+			return s
+		}
+		args := make([]string, 0)
+		if match[2] != "" {
+			// 使用\,表示参数中的逗号
+			for _, arg := range strings.Split(strings.ReplaceAll(match[2], `\,`, "\x00"), ",") {
+				args = append(args, strings.TrimSpace(strings.ReplaceAll(arg, "\x00", ",")))
+			}
+		}
+		for i := 9; i >= 1; i-- {
+			arg := ""
+			if i <= len(args) {
+				arg = args[i-1]
+			}
+			body = strings.ReplaceAll(body, "$"+strconv.Itoa(i), arg)
+		}
+		return body
+	})
+}
+
+// expand 替换内容中的{{{name(args)}}}
+func (m orgMacros) expand(data []byte) []byte {
+	if len(m) == 0 {
+		return data
+	}
+	result, _ := eachLine(data, func(line string) (string, error) {
+		return m.expandLine(line), nil
+	})
+	return result
+}
+
+// newOrgMacros 收集内容中的#+MACRO, 并删除定义所在的行
+func newOrgMacros(data []byte) (orgMacros, []byte) {
+	m := make(orgMacros)
+	result, _ := eachLine(data, func(line string) (string, error) {
+		if match := ORGMODE_MACRO.FindStringSubmatch(strings.TrimRight(line, "\r\n")); match != nil {
+			m[strings.ToLower(match[1])] = match[2]
+			return "", nil
+		}
+		return line, nil
+	})
+	return m, result
+}
@@ -0,0 +1,153 @@
+package orgmode
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/honmaple/snow/internal/site/content/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseOrgFS(t *testing.T, fsys fstest.MapFS, file string) (*parser.Result, error) {
+	t.Helper()
+	return New(&Option{MarkupOption: parser.MarkupOption{Style: "monokai", HighlightMode: parser.HighlightModeClasses}}).ParseFS(fsys, file)
+}
+
+func TestIncludeFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"posts/hello.org": &fstest.MapFile{Data: []byte(`#+TITLE: Hello
+#+INCLUDE: "./shared/_intro.org"
+
+#+INCLUDE: "../examples/main.go" src go :lines "2-4" :title "main file.go"
+
+#+begin_example
+#+INCLUDE: "missing.org"
+#+end_example
+`)},
+		"posts/shared/_intro.org":  &fstest.MapFile{Data: []byte("intro text\n#+INCLUDE: \"_nested.org\" :lines \"2-\"\n")},
+		"posts/shared/_nested.org": &fstest.MapFile{Data: []byte("skipped\nnested text")},
+		"examples/main.go":         &fstest.MapFile{Data: []byte("package main\n\nfunc main() {\n}\n")},
+	}
+
+	result, err := parseOrgFS(t, fsys, "posts/hello.org")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hello", result.FrontMatter["title"])
+	assert.Contains(t, result.Content, "intro text")
+	assert.Contains(t, result.Content, "nested text")
+	assert.NotContains(t, result.Content, "skipped")
+	assert.Contains(t, result.Content, "<figcaption>main file.go</figcaption>")
+	assert.Contains(t, result.Content, `<span class="kd">func</span>`)
+	assert.NotContains(t, result.Content, "package")
+	assert.Contains(t, result.Content, `#+INCLUDE: &#34;missing.org&#34;`)
+	assert.Equal(t, []string{"examples/main.go", "posts/shared/_intro.org", "posts/shared/_nested.org"}, result.Includes)
+}
+
+func TestIncludeErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.org":       &fstest.MapFile{Data: []byte("#+INCLUDE: \"b.org\"\n")},
+		"b.org":       &fstest.MapFile{Data: []byte("#+INCLUDE: \"a.org\"\n")},
+		"missing.org": &fstest.MapFile{Data: []byte("text\n#+INCLUDE: \"none.org\"\n")},
+		"lines.org":   &fstest.MapFile{Data: []byte("#+INCLUDE: \"b.org\" :lines \"5-3\"\n")},
+		"escape.org":  &fstest.MapFile{Data: []byte("#+SETUPFILE: \"../setup.org\"\n")},
+	}
+
+	_, err := parseOrgFS(t, fsys, "a.org")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "include cycle: a.org -> b.org -> a.org")
+
+	var includeErr *parser.IncludeError
+	assert.ErrorAs(t, err, &includeErr)
+
+	_, err = parseOrgFS(t, fsys, "missing.org")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing.org: include open none.org")
+
+	_, err = parseOrgFS(t, fsys, "lines.org")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid lines "5-3"`)
+
+	_, err = parseOrgFS(t, fsys, "escape.org")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid include file")
+}
+
+func TestSetupFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"_setup.org": &fstest.MapFile{Data: []byte(`#+AUTHOR: snow
+#+TITLE: Setup Title
+#+OPTIONS: toc:t num:nil
+#+PROPERTY: category blog
+#+MACRO: kbd [$1]
+#+MACRO: greet Hello $1 and $2
+`)},
+		"hello.org": &fstest.MapFile{Data: []byte(`#+SETUPFILE: "_setup.org"
+#+TITLE: Hello
+#+OPTIONS: toc:nil
+
+Press {{{kbd(C-c)}}}, {{{greet(a\, b, c)}}} {{{unknown}}}
+
+#+begin_src text
+{{{greet(x)}}}
+#+end_src
+`)},
+	}
+
+	result, err := parseOrgFS(t, fsys, "hello.org")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hello", result.FrontMatter["title"])
+	assert.Equal(t, "snow", result.FrontMatter["author"])
+	assert.Equal(t, "blog", result.FrontMatter["category"])
+	assert.Equal(t, "toc:nil num:nil", result.FrontMatter["options"])
+	assert.NotContains(t, result.FrontMatter, "setupfile")
+	assert.NotContains(t, result.FrontMatter, "macro")
+
+	assert.Contains(t, result.Content, "Press [C-c], Hello a, b and c {{{unknown}}}")
+	assert.Contains(t, result.Content, "{{{greet(x)}}}")
+	assert.Equal(t, []string{"_setup.org"}, result.Includes)
+}
+
+func TestSetupFileOptions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"_setup.org": &fstest.MapFile{Data: []byte(`#+OPTIONS: num:t tags:nil toc:1
+#+TODO: NEXT | DONE
+`)},
+		"hello.org": &fstest.MapFile{Data: []byte(`#+SETUPFILE: "_setup.org"
+#+OPTIONS: pri:nil
+
+* NEXT [#A] First :work:
+** Child
+`)},
+		"plain.org": &fstest.MapFile{Data: []byte(`* NEXT [#A] First :work:
+** Child
+`)},
+	}
+
+	result, err := parseOrgFS(t, fsys, "hello.org")
+	require.NoError(t, err)
+	assert.Equal(t, "num:t tags:nil toc:1 pri:nil", result.FrontMatter["options"])
+	assert.Contains(t, result.Content, `<h1 id="first"><span class="section-number">1</span> <span class="todo">NEXT</span>First</h1>`)
+	assert.Contains(t, result.Content, `<h2 id="child"><span class="section-number">1.1</span> Child</h2>`)
+	require.Len(t, result.Toc, 1)
+	assert.Empty(t, result.Toc[0].Children)
+
+	result, err = parseOrgFS(t, fsys, "plain.org")
+	require.NoError(t, err)
+	assert.Contains(t, result.Content, `<h1 id="next-a-first">NEXT [#A] First<span class="tag">work</span></h1>`)
+	require.Len(t, result.Toc, 1)
+	assert.Len(t, result.Toc[0].Children, 1)
+}
+
+func TestMacros(t *testing.T) {
+	result := parseOrg(t, `#+TITLE: Macro
+#+MACRO: name snow
+
+Hello {{{name}}}
+`, nil)
+
+	assert.Equal(t, "Macro", result.FrontMatter["title"])
+	assert.NotContains(t, result.FrontMatter, "macro")
+	assert.Contains(t, result.Content, "Hello snow")
+}
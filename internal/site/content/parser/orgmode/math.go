@@ -0,0 +1,192 @@
+package orgmode
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	contentparser "github.com/honmaple/snow/internal/site/content/parser"
+)
+
+var (
+	mathSkipBeginRegexp   = regexp.MustCompile(`(?i)^\s*#\+begin_(src|example|export)\b`)
+	mathSkipEndRegexp     = regexp.MustCompile(`(?i)^\s*#\+end_(src|example|export)\b`)
+	mathSkipLineRegexp    = regexp.MustCompile(`^\s*(#\+|#\s|:\s|:$)`)
+	mathBeginEnvRegexp    = regexp.MustCompile(`^\s*\\begin\{([^}]+)\}`)
+	mathPlaceholderRegexp = regexp.MustCompile(mathPlaceholderStart + `\d+` + mathPlaceholderEnd)
+)
+
+const (
+	mathVerbatimMarkers = "=~"
+	// 使用私有区字符作为占位符, 不会被org-golang当作标记
+	mathPlaceholderStart = "\uE000"
+	mathPlaceholderEnd   = "\uE001"
+)
+
+// mathExtractor org-golang不支持latex公式, 解析前把公式替换为占位符, 渲染后再替换回来
+type mathExtractor struct {
+	mode      string
+	errs      []string
+	fragments []string
+}
+
+func (e *mathExtractor) placeholder(tex string, display bool) string {
+	fragment, err := contentparser.MathHTML(e.mode, tex, display)
+	if err != nil {
+		e.errs = append(e.errs, err.Error())
+	}
+	e.fragments = append(e.fragments, fragment)
+	return fmt.Sprintf("%s%d%s", mathPlaceholderStart, len(e.fragments)-1, mathPlaceholderEnd)
+}
+
+func (e *mathExtractor) Extract(data string) string {
+	var (
+		b       strings.Builder
+		text    []string
+		env     string
+		envText []string
+		skip    bool
+	)
+	flush := func() {
+		if len(text) > 0 {
+			b.WriteString(e.extractInline(strings.Join(text, "")))
+			text = text[:0]
+		}
+	}
+
+	for _, line := range strings.SplitAfter(data, "\n") {
+		switch {
+		case env != "":
+			envText = append(envText, line)
+			if strings.Contains(line, `\end{`+env+`}`) {
+				b.WriteString(e.placeholder(strings.TrimSpace(strings.Join(envText, "")), true))
+				b.WriteString("\n")
+				env, envText = "", nil
+			}
+		case skip:
+			b.WriteString(line)
+			skip = !mathSkipEndRegexp.MatchString(line)
+		case mathSkipBeginRegexp.MatchString(line):
+			flush()
+			b.WriteString(line)
+			skip = true
+		case mathSkipLineRegexp.MatchString(line):
+			flush()
+			b.WriteString(line)
+		default:
+			if m := mathBeginEnvRegexp.FindStringSubmatch(line); m != nil {
+				flush()
+				env, envText = m[1], []string{line}
+				if strings.Contains(line, `\end{`+env+`}`) {
+					b.WriteString(e.placeholder(strings.TrimSpace(line), true))
+					b.WriteString("\n")
+					env, envText = "", nil
+				}
+				continue
+			}
+			text = append(text, line)
+		}
+	}
+	flush()
+	// 没有结束的\begin{env}保持原样
+	for _, line := range envText {
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+func isMathBorder(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return true
+	}
+	r := rune(s[i])
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '$'
+}
+
+// singleDollarEnd 返回结束的$位置, 单个$不允许跨行, 结束的$前不能是空格, 后面不能是数字或字母
+func singleDollarEnd(s string) int {
+	for i := 1; i < len(s) && s[i] != '\n'; i++ {
+		if s[i] == '$' && !unicode.IsSpace(rune(s[i-1])) && isMathBorder(s, i+1) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (e *mathExtractor) extractInline(s string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case strings.IndexByte(mathVerbatimMarkers, c) >= 0 && isMathBorder(s, i-1):
+			// =verbatim=和~code~中的内容不处理
+			line := s[i+1:]
+			if end := strings.IndexByte(line, '\n'); end >= 0 {
+				line = line[:end]
+			}
+			if end := strings.IndexByte(line, c); end > 0 && isMathBorder(s, i+end+2) {
+				b.WriteString(s[i : i+end+2])
+				i += end + 1
+				continue
+			}
+		case c == '\\' && i+1 < len(s) && (s[i+1] == '(' || s[i+1] == '['):
+			closer := `\)`
+			if s[i+1] == '[' {
+				closer = `\]`
+			}
+			if end := strings.Index(s[i+2:], closer); end >= 0 {
+				b.WriteString(e.placeholder(strings.TrimSpace(s[i+2:i+2+end]), s[i+1] == '['))
+				i += end + 3
+				continue
+			}
+		case c == '$' && strings.HasPrefix(s[i:], "$$"):
+			if end := strings.Index(s[i+2:], "$$"); end > 0 {
+				b.WriteString(e.placeholder(strings.TrimSpace(s[i+2:i+2+end]), true))
+				i += end + 3
+				continue
+			}
+		case c == '$' && isMathBorder(s, i-1) && i+1 < len(s) && !unicode.IsSpace(rune(s[i+1])):
+			if end := singleDollarEnd(s[i+1:]); end > 0 {
+				b.WriteString(e.placeholder(s[i+1:i+1+end], false))
+				i += end + 1
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// Strip 删除占位符, 用于生成标题的锚点
+func (e *mathExtractor) Strip(s string) string {
+	if e == nil || !strings.Contains(s, mathPlaceholderStart) {
+		return s
+	}
+	return mathPlaceholderRegexp.ReplaceAllString(s, "")
+}
+
+// Errors 返回公式转换失败的错误
+func (e *mathExtractor) Errors() []string {
+	if e == nil {
+		return nil
+	}
+	return e.errs
+}
+
+// Restore 把占位符替换为渲染后的公式
+func (e *mathExtractor) Restore(s string) string {
+	if e == nil || len(e.fragments) == 0 || !strings.Contains(s, mathPlaceholderStart) {
+		return s
+	}
+	pairs := make([]string, 0, len(e.fragments)*2)
+	for i, fragment := range e.fragments {
+		pairs = append(pairs, fmt.Sprintf("%s%d%s", mathPlaceholderStart, i, mathPlaceholderEnd), fragment)
+	}
+	return strings.NewReplacer(pairs...).Replace(s)
+}
+
+func newMathExtractor(mode string) *mathExtractor {
+	return &mathExtractor{mode: mode}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"regexp"
 	"strings"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/honmaple/org-golang/render"
 	"github.com/honmaple/snow/internal/core"
 	"github.com/honmaple/snow/internal/site/content/parser"
+	"github.com/spf13/cast"
 )
 
 var (
@@ -29,23 +31,85 @@ type Option struct {
 	parser.MarkupOption
 }
 
+// drawerProperties org-golang不会解析drawer中的属性, 需要从内容中读取
+func drawerProperties(drawer *orgmodeParser.Drawer) {
+	var b strings.Builder
+	for _, child := range drawer.Children {
+		paragraph, ok := child.(*orgmodeParser.Paragragh)
+		if !ok {
+			continue
+		}
+		for _, node := range paragraph.Children {
+			switch n := node.(type) {
+			case *orgmodeParser.InlineText:
+				b.WriteString(n.Content)
+			case *orgmodeParser.InlineLineBreak:
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+	for _, line := range strings.Split(b.String(), "\n") {
+		match := ORGMODE_META.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		if _, ok := drawer.Properties[match[1]]; !ok {
+			drawer.Properties[match[1]] = strings.TrimSpace(match[3])
+		}
+	}
+}
+
+// keywordFrontMatter 返回#+KEY: value对应的front matter, #+PROPERTY: key value使用第一个单词作为key
+func keywordFrontMatter(match []string) (string, string) {
+	if match[1] != "PROPERTY" {
+		return strings.ToLower(match[1]), strings.TrimSpace(match[3])
+	}
+	s := strings.SplitN(match[3], " ", 2)
+	v := ""
+	if len(s) > 1 {
+		v = strings.TrimSpace(s[1])
+	}
+	return strings.ToLower(s[0]), v
+}
+
 type orgParser struct {
 	opt      *Option
 	renderer *Renderer
 }
 
-func (m *orgParser) parse(data []byte) ([]*parser.Heading, string, error) {
+// parse 渲染内容, #+OPTIONS和#+TODO在front matter中, 需要在渲染前传给解析器
+func (m *orgParser) parse(data []byte, frontMatter map[string]any) ([]*parser.Heading, map[string]string, string, []string, error) {
+	renderer := *m.renderer
+	renderer.options = newExportOptions(cast.ToString(frontMatter["options"]))
+
+	var maths *mathExtractor
+	if m.opt.Math != "" && renderer.options.tex {
+		maths = newMathExtractor(m.opt.Math)
+		data = []byte(maths.Extract(string(data)))
+	}
+
+	todo := cast.ToString(frontMatter["todo"])
 	rd := render.HTML{
-		Toc:            false,
-		Document:       org.New(bytes.NewBuffer(data)),
-		RenderNodeFunc: m.renderer.RenderNode,
+		Toc: false,
+		Document: org.New(bytes.NewBuffer(data), func(d *orgmodeParser.Document) {
+			if todo != "" {
+				d.Set("TODO", todo)
+			}
+		}),
+		RenderNodeFunc: renderer.RenderNode,
 	}
 
+	ids := make(map[string]string)
+
 	var ch func([]*orgmodeParser.Section) []*parser.Heading
 
 	ch = func(children []*orgmodeParser.Section) []*parser.Heading {
 		headings := make([]*parser.Heading, 0)
 		for _, child := range children {
+			if child.Properties != nil {
+				drawerProperties(child.Properties)
+			}
 			heading := &parser.Heading{
 				Id:       child.Id(),
 				Title:    rd.RenderNodes(child.Title, ""),
@@ -54,7 +118,7 @@ func (m *orgParser) parse(data []byte) ([]*parser.Heading, string, error) {
 			}
 			if child.Properties == nil || child.Properties.Get("CUSTOM_ID") == "" {
 				fallback := heading.Id
-				heading.Id = m.opt.HeadingID(heading.Title, fallback)
+				heading.Id = m.opt.HeadingID(maths.Strip(heading.Title), fallback)
 				if heading.Id != fallback {
 					if child.Properties == nil {
 						child.Properties = &orgmodeParser.Drawer{
@@ -65,15 +129,26 @@ func (m *orgParser) parse(data []byte) ([]*parser.Heading, string, error) {
 					child.Properties.Properties["CUSTOM_ID"] = heading.Id
 				}
 			}
+			if child.Properties != nil {
+				if id := child.Properties.Get("ID"); id != "" {
+					ids[id] = heading.Id
+				}
+			}
+			heading.Title = maths.Restore(heading.Title)
 			headings = append(headings, heading)
 		}
 		return headings
 	}
-	toc := ch(rd.Document.Sections.Children)
-	return toc, rd.String(), nil
+	toc := renderer.options.tocHeadings(ch(rd.Document.Sections.Children), 1)
+	return toc, ids, maths.Restore(rd.String()), maths.Errors(), nil
 }
 
 func (m *orgParser) Parse(r io.Reader) (*parser.Result, error) {
+	return m.parseReader(r, nil)
+}
+
+// parseReader 解析内容, setup为#+SETUPFILE中的关键字, 当前文件的关键字优先, #+OPTIONS按照选项合并
+func (m *orgParser) parseReader(r io.Reader, setup map[string]any) (*parser.Result, error) {
 	var (
 		content   bytes.Buffer
 		summary   bytes.Buffer
@@ -127,17 +202,13 @@ func (m *orgParser) Parse(r io.Reader) (*parser.Result, error) {
 		}
 		if isMeta {
 			if match := ORGMODE_KEYWORD.FindStringSubmatch(line); match != nil {
-				if match[1] == "PROPERTY" {
-					s := strings.SplitN(match[3], " ", 2)
-					k := strings.ToLower(s[0])
-					v := ""
-					if len(s) > 1 {
-						v = strings.TrimSpace(s[1])
-					}
-					result.SetFrontMatter(k, v)
-				} else {
-					result.SetFrontMatter(strings.ToLower(match[1]), strings.TrimSpace(match[3]))
+				// 宏定义和没有展开的#+INCLUDE保留在内容中
+				if strings.EqualFold(match[1], "MACRO") || strings.EqualFold(match[1], "INCLUDE") {
+					content.WriteString(line)
+					content.WriteString("\n")
+					continue
 				}
+				result.SetFrontMatter(keywordFrontMatter(match))
 				continue
 			}
 		}
@@ -149,17 +220,37 @@ func (m *orgParser) Parse(r io.Reader) (*parser.Result, error) {
 		return nil, fmt.Errorf("org parser scan: %w", err)
 	}
 
-	toc, res, err := m.parse(content.Bytes())
+	for k, v := range setup {
+		old, ok := result.FrontMatter[k]
+		if !ok {
+			result.FrontMatter[k] = v
+			continue
+		}
+		if k == "options" {
+			result.FrontMatter[k] = mergeOptions(cast.ToString(v), cast.ToString(old))
+		}
+	}
+
+	macros, body := newOrgMacros(content.Bytes())
+
+	toc, ids, res, errs, err := m.parse(macros.expand(body), result.FrontMatter)
 	if err != nil {
 		return nil, err
 	}
+	result.Errors = errs
+	if id, ok := result.FrontMatter["id"].(string); ok && id != "" {
+		ids[id] = ""
+	}
 	result.Toc = toc
+	result.IDs = ids
 	result.Content = res
 	result.RawSummary = summary.String()
 	result.RawContent = content.String()
 
 	if summary.Len() > 0 {
-		_, res, err := m.parse(summary.Bytes())
+		_, summaryBody := newOrgMacros(summary.Bytes())
+		// 摘要是内容的一部分, 不需要重复记录错误
+		_, _, res, _, err := m.parse(macros.expand(summaryBody), result.FrontMatter)
 		if err != nil {
 			return nil, err
 		}
@@ -168,10 +259,35 @@ func (m *orgParser) Parse(r io.Reader) (*parser.Result, error) {
 	return result, nil
 }
 
+// ParseFS 展开#+INCLUDE和#+SETUPFILE后再解析, 路径相对于当前文件
+func (m *orgParser) ParseFS(fsys fs.FS, file string) (*parser.Result, error) {
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+	inc := newOrgInclude(fsys)
+	data, err = inc.expand(file, data, []string{file})
+	if err != nil {
+		return nil, &parser.IncludeError{Err: err}
+	}
+
+	result, err := m.parseReader(bytes.NewReader(data), inc.setup.FrontMatter)
+	if err != nil {
+		return nil, err
+	}
+	result.Includes = inc.Includes()
+	return result, nil
+}
+
 func (m *orgParser) SupportedExtensions() []string {
 	return []string{".org"}
 }
 
+// FrontMatterKeys #+OPTIONS, #+STARTUP和#+TODO用于控制解析
+func (m *orgParser) FrontMatterKeys(string) []string {
+	return []string{"options", "startup", "todo"}
+}
+
 func New(opt *Option) *orgParser {
 	return &orgParser{opt: opt, renderer: NewRenderer(opt)}
 }
@@ -267,3 +267,126 @@ func TestReaderErrorIsReturned(t *testing.T) {
 	assert.ErrorIs(t, err, errOrgReader)
 	assert.Contains(t, err.Error(), "org parser scan")
 }
+
+func TestWikiLinks(t *testing.T) {
+	opt := &Option{MarkupOption: parser.MarkupOption{WikiLinks: true}}
+	result := parseOrg(t, "see [[Hello World]], [[hello-world][alias]], [[./a.org][file]] and [[https://example.com][site]]\n", opt)
+
+	assert.Contains(t, result.Content, `<a class="wikilink" href="" data-wikilink="Hello World">Hello World</a>`)
+	assert.Contains(t, result.Content, `<a class="wikilink" href="" data-wikilink="hello-world">alias</a>`)
+	assert.Contains(t, result.Content, `<a href="./a.org">file</a>`)
+	assert.Contains(t, result.Content, `<a href="https://example.com">site</a>`)
+
+	result = parseOrg(t, "[[Go 1.21]], [[v2.0 release notes]] and [[image.PNG]]\n", opt)
+	assert.Contains(t, result.Content, `<a class="wikilink" href="" data-wikilink="Go 1.21">Go 1.21</a>`)
+	assert.Contains(t, result.Content, `data-wikilink="v2.0 release notes"`)
+	assert.NotContains(t, result.Content, `data-wikilink="image.PNG"`)
+
+	result = parseOrg(t, "[[Hello World]]\n", nil)
+	assert.NotContains(t, result.Content, "data-wikilink")
+}
+
+func TestOrgRoamIDs(t *testing.T) {
+	result := parseOrg(t, `:PROPERTIES:
+:ID:       8F2C-FILE
+:END:
+#+title: roam
+
+* First
+:PROPERTIES:
+:ID:       8F2C-FIRST
+:END:
+** Second
+:PROPERTIES:
+:ID:       8F2C-SECOND
+:CUSTOM_ID: custom
+:END:
+
+see [[id:8F2C-OTHER][other]]
+`, nil)
+
+	assert.Equal(t, map[string]string{
+		"8F2C-FILE":   "",
+		"8F2C-FIRST":  "first",
+		"8F2C-SECOND": "custom",
+	}, result.IDs)
+	assert.Contains(t, result.Content, `<a href="id:8F2C-OTHER">other</a>`)
+}
+
+func TestMath(t *testing.T) {
+	text := "* Euler $e^{i\\pi}$\n\ninline $a_2$ and \\(b_1\\), costs $5 and $10\n\n\\[x=1\\]\n\n\\begin{align}\na &= b\n\\end{align}\n\n=$code$=\n\n#+begin_src python\n$x_1$\n#+end_src\n"
+
+	result := parseOrg(t, text, &Option{MarkupOption: parser.MarkupOption{Math: parser.MathSpan, ShowToc: true}})
+	assert.Contains(t, result.Content, `<span class="math inline">\(a_2\)</span>`)
+	assert.Contains(t, result.Content, `<span class="math inline">\(b_1\)</span>`)
+	assert.Contains(t, result.Content, "costs $5 and $10")
+	assert.Contains(t, result.Content, `<span class="math display">\[x=1\]</span>`)
+	assert.Contains(t, result.Content, `<span class="math display">\[\begin{align}`)
+	assert.Contains(t, result.Content, "<code>$code$</code>")
+	assert.Contains(t, result.Content, "$x_1$")
+	require.Len(t, result.Toc, 1)
+	assert.Equal(t, "euler", result.Toc[0].Id)
+	assert.Equal(t, `Euler <span class="math inline">\(e^{i\pi}\)</span>`, result.Toc[0].Title)
+
+	result = parseOrg(t, text, &Option{MarkupOption: parser.MarkupOption{Math: parser.MathMathML}})
+	assert.Contains(t, result.Content, `<math `)
+	assert.Contains(t, result.Content, `<annotation encoding="application/x-tex">a_2</annotation>`)
+
+	result = parseOrg(t, text, nil)
+	assert.NotContains(t, result.Content, "math")
+	result = parseOrg(t, "* Title $x^{$\n\nbad $\\frac{1}{$ math\n", &Option{MarkupOption: parser.MarkupOption{Math: parser.MathMathML}})
+	assert.Contains(t, result.Content, `<span class="math inline">\(\frac{1}{\)</span>`)
+	assert.Equal(t, []string{
+		`invalid math "x^{": mismatched curly brace at position 2`,
+		`invalid math "\\frac{1}{": mismatched curly brace at position 4`,
+	}, result.Errors)
+}
+
+func TestHighlightClasses(t *testing.T) {
+	result := parseOrg(t, "#+begin_src go\nfunc main() {}\n#+end_src\n", &Option{
+		MarkupOption: parser.MarkupOption{
+			Style:         "monokai",
+			HighlightMode: parser.HighlightModeClasses,
+		},
+	})
+	assert.Contains(t, result.Content, `class="chroma"`)
+	assert.Contains(t, result.Content, `<span class="kd">func</span>`)
+	assert.NotContains(t, result.Content, "style=")
+}
+
+func TestCodeAttributes(t *testing.T) {
+	opt := &Option{
+		MarkupOption: parser.MarkupOption{
+			Style:           "monokai",
+			ShowLineNumbers: true,
+			HighlightMode:   parser.HighlightModeClasses,
+		},
+	}
+	result := parseOrg(t, "#+begin_src go :hl_lines 2 :linenostart 10 :title main.go\na := 1\nb := 2\n#+end_src\n", opt)
+	assert.Contains(t, result.Content, "<figcaption>main.go</figcaption>")
+	assert.Contains(t, result.Content, `<span class="line hl"><span class="ln">11</span>`)
+
+	result = parseOrg(t, "#+begin_src go :linenos no\na := 1\n#+end_src\n", opt)
+	assert.NotContains(t, result.Content, `class="ln"`)
+
+	result = parseOrg(t, "#+begin_src go :highlight no\na := 1\n#+end_src\n", opt)
+	assert.Contains(t, result.Content, `<pre><code class="language-go">a := 1`)
+
+	result = parseOrg(t, "#+begin_src go :title main.go\na := 1\n#+end_src\n", &Option{MarkupOption: parser.MarkupOption{Style: "none"}})
+	assert.Contains(t, result.Content, "<figcaption>main.go</figcaption>\n<pre><code class=\"language-go\">a := 1")
+}
+
+func TestInclude(t *testing.T) {
+	result := parseOrg(t, `#+TITLE: Include
+#+INCLUDE: "/examples/main.go" src go :lines "3-5" :title "main file.go"
+
+#+INCLUDE: "./code.py" example :region foo
+
+#+INCLUDE: "./common.org"
+`, &Option{MarkupOption: parser.MarkupOption{Style: "monokai"}})
+
+	assert.Equal(t, "Include", result.FrontMatter["title"])
+	assert.Contains(t, result.Content, `<shortcode include file="/examples/main.go" lang="go" lines="3-4" title="main file.go" />`)
+	assert.Contains(t, result.Content, `<shortcode include file="./code.py" region="foo" />`)
+	assert.NotContains(t, result.Content, "common.org")
+}
@@ -2,47 +2,129 @@ package orgmode
 
 import (
 	"fmt"
+	"html"
+	"strconv"
 	"strings"
 
-	"github.com/alecthomas/chroma/v2"
-	"github.com/alecthomas/chroma/v2/lexers"
-	"github.com/alecthomas/chroma/v2/styles"
 	"github.com/honmaple/org-golang/parser"
 	"github.com/honmaple/org-golang/render"
 	contentparser "github.com/honmaple/snow/internal/site/content/parser"
 )
 
-type Renderer struct {
-	opt       *Option
-	formatter *contentparser.HTMLFormatter
+// exportOptions #+OPTIONS中影响渲染的选项, 没有设置的选项保持默认的渲染结果
+type exportOptions struct {
+	// toc为0时不生成目录, 大于0时只保留对应级别的标题
+	toc int
+	// num为0时标题没有编号, 小于0时所有级别的标题都有编号
+	num  int
+	todo bool
+	pri  bool
+	tags bool
+	tex  bool
 }
 
-func (e *Renderer) highlightCodeBlock(source, lang string) string {
-	var w strings.Builder
-	var lexer chroma.Lexer
+// exportLevel 解析t, nil或者数字, t返回-1
+func exportLevel(v string, def int) int {
+	switch v {
+	case "t":
+		return -1
+	case "nil":
+		return 0
+	}
+	if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+		return n
+	}
+	return def
+}
 
-	if lang == "" || lang == "example" {
-		lexer = lexers.Analyse(source)
-	} else {
-		lexer = lexers.Get(lang)
+func newExportOptions(options string) exportOptions {
+	opts := exportOptions{toc: -1, todo: true, pri: true, tags: true, tex: true}
+	for _, field := range strings.Fields(options) {
+		key, value, _ := strings.Cut(field, ":")
+		switch key {
+		case "toc":
+			opts.toc = exportLevel(value, opts.toc)
+		case "num":
+			opts.num = exportLevel(value, opts.num)
+		case "todo":
+			opts.todo = value != "nil"
+		case "pri":
+			opts.pri = value != "nil"
+		case "tags":
+			opts.tags = value != "nil"
+		case "tex":
+			opts.tex = value != "nil" && value != "verbatim"
+		}
+	}
+	return opts
+}
+
+// tocHeadings 按照toc:N移除目录中超过N级的标题
+func (opts exportOptions) tocHeadings(headings []*contentparser.Heading, level int) []*contentparser.Heading {
+	if opts.toc < 0 {
+		return headings
+	}
+	if level > opts.toc {
+		return nil
 	}
-	if lexer == nil {
-		lexer = lexers.Fallback
+	for _, heading := range headings {
+		heading.Children = opts.tocHeadings(heading.Children, level+1)
 	}
+	return headings
+}
 
-	style := styles.Get(e.opt.Style)
-	if style == nil {
-		style = styles.Fallback
+type Renderer struct {
+	opt     *Option
+	options exportOptions
+}
+
+// renderInclude 把#+INCLUDE: "file" src go :lines "3-5"转换为include shortcode
+func (e *Renderer) renderInclude(value string) (string, bool) {
+	fields := includeFields(value)
+	if len(fields) < 2 || (fields[1] != "src" && fields[1] != "example") {
+		return "", false
 	}
 
-	it, _ := lexer.Tokenise(nil, source)
-	e.formatter.Format(&w, style, it)
-	return w.String()
+	attrs := []string{fmt.Sprintf(`file="%s"`, html.EscapeString(fields[0]))}
+
+	params := fields[2:]
+	if fields[1] == "src" && len(params) > 0 && !strings.HasPrefix(params[0], ":") {
+		attrs = append(attrs, fmt.Sprintf(`lang="%s"`, html.EscapeString(params[0])))
+		params = params[1:]
+	}
+	for i := 0; i < len(params); i++ {
+		key, ok := strings.CutPrefix(params[i], ":")
+		if !ok || key == "" {
+			continue
+		}
+		if i+1 < len(params) && !strings.HasPrefix(params[i+1], ":") {
+			i++
+			value := params[i]
+			// org-mode的:lines不包括结束的行
+			if key == "lines" {
+				if start, end, err := includeLines(value); err == nil {
+					value = strconv.Itoa(start) + "-"
+					if end > 0 {
+						value += strconv.Itoa(end - 1)
+					}
+				}
+			}
+			attrs = append(attrs, fmt.Sprintf(`%s="%s"`, html.EscapeString(key), html.EscapeString(value)))
+		} else {
+			attrs = append(attrs, html.EscapeString(key))
+		}
+	}
+	return fmt.Sprintf("<shortcode include %s />", strings.Join(attrs, " ")), true
 }
 
 func (e *Renderer) RenderKeyword(r render.Renderer, n *parser.Keyword) string {
-	if strings.ToUpper(n.Key) == "HTML" {
+	switch strings.ToUpper(n.Key) {
+	case "HTML":
 		return n.Value
+	case "INCLUDE":
+		if s, ok := e.renderInclude(n.Value); ok {
+			return s
+		}
 	}
 	return r.RenderKeyword(n)
 }
@@ -50,14 +132,15 @@ func (e *Renderer) RenderKeyword(r render.Renderer, n *parser.Keyword) string {
 func (e *Renderer) RenderBlock(r render.Renderer, n *parser.Block) string {
 	switch n.Type {
 	case "SRC", "EXAMPLE":
-		if e.opt.Style != "" && e.opt.Style != "none" {
-			lang := ""
-			if len(n.Parameters) > 0 {
-				lang = n.Parameters[0]
-			}
-			text := render.DedentString(r.RenderNodes(n.Children, "\n"))
-			return e.highlightCodeBlock(text, lang)
+		lang, attrs := contentparser.ParseCodeParams(n.Parameters)
+		if (e.opt.Style == "" || e.opt.Style == "none") && attrs == nil {
+			break
 		}
+		if lang == "example" {
+			lang = ""
+		}
+		text := render.DedentString(r.RenderNodes(n.Children, "\n"))
+		return contentparser.HighlightCode(e.opt.MarkupOption, lang, text, attrs)
 	case "SHORTCODE":
 		if len(n.Parameters) > 0 {
 			return fmt.Sprintf("<shortcode %[1]s>\n%[2]s\n</shortcode>", strings.Join(n.Parameters, " "), r.RenderNodes(n.Children, "\n"))
@@ -66,12 +149,55 @@ func (e *Renderer) RenderBlock(r render.Renderer, n *parser.Block) string {
 	return r.RenderBlock(n)
 }
 
+func (e *Renderer) RenderInlineLink(r render.Renderer, n *parser.InlineLink) string {
+	if e.opt.WikiLinks && n.Protocol == "" && contentparser.IsWikiLinkTarget(n.URL) {
+		text := n.Desc
+		if text == "" {
+			text = n.URL
+		}
+		return contentparser.WikiLinkHTML(strings.TrimSpace(n.URL), html.EscapeString(text), false)
+	}
+	return r.RenderInlineLink(n)
+}
+
+// RenderHeading 按照#+OPTIONS中的num, todo, pri, tags渲染标题
+func (e *Renderer) RenderHeading(r render.Renderer, n *parser.Heading) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "<h%d id=\"%s\">", n.Stars, n.Id())
+	if e.options.num < 0 || n.Stars <= e.options.num {
+		fmt.Fprintf(&b, "<span class=\"section-number\">%s</span> ", n.Index)
+	}
+	if e.options.todo && n.Keyword != "" {
+		fmt.Fprintf(&b, "<span class=\"todo\">%s</span>", n.Keyword)
+	}
+	if e.options.pri && n.Priority != "" {
+		fmt.Fprintf(&b, "<span class=\"priority\">%s</span>", n.Priority)
+	}
+	b.WriteString(r.RenderNodes(n.Title, ""))
+	if e.options.tags {
+		for _, tag := range n.Tags {
+			fmt.Fprintf(&b, "<span class=\"tag\">%s</span>", tag)
+		}
+	}
+	fmt.Fprintf(&b, "</h%d>", n.Stars)
+	if len(n.Children) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString(r.RenderNodes(n.Children, "\n"))
+	return b.String()
+}
+
 func (e *Renderer) RenderNode(r render.Renderer, n parser.Node) string {
 	switch node := n.(type) {
+	case *parser.Heading:
+		return e.RenderHeading(r, node)
 	case *parser.Block:
 		return e.RenderBlock(r, node)
 	case *parser.Keyword:
 		return e.RenderKeyword(r, node)
+	case *parser.InlineLink:
+		return e.RenderInlineLink(r, node)
 	default:
 		return r.RenderNode(n, true)
 	}
@@ -79,8 +205,7 @@ func (e *Renderer) RenderNode(r render.Renderer, n parser.Node) string {
 
 func NewRenderer(opt *Option) *Renderer {
 	r := &Renderer{
-		opt:       opt,
-		formatter: contentparser.NewHTMLFormatter(opt.MarkupOption),
+		opt: opt,
 	}
 	return r
 }
@@ -21,15 +21,50 @@ type (
 		Parse(io.Reader) (*Result, error)
 		SupportedExtensions() []string
 	}
+	// LangMarkupParser 可以按照内容的语言使用不同配置的解析器
+	LangMarkupParser interface {
+		ParseLang(io.Reader, string) (*Result, error)
+	}
+	// FSMarkupParser 解析时需要读取其它的文件, 例如org-mode的#+INCLUDE
+	FSMarkupParser interface {
+		ParseFS(fs.FS, string) (*Result, error)
+	}
+	// DefaultsParser 解析时使用上级section中cascade的配置, 文件中的front matter优先
+	DefaultsParser interface {
+		ParseDefaults(fs.FS, string, map[string]any) (*Result, error)
+	}
+	// DefaultsMarkupParser 解析时使用默认的front matter, 例如cascade中markdown的配置
+	DefaultsMarkupParser interface {
+		ParseDefaults(io.Reader, string, map[string]any) (*Result, error)
+	}
+	// FrontMatterKeysParser 返回解析器生成或者用于控制解析的字段, 例如asciidoc的revdate, 检查schema时不需要声明
+	FrontMatterKeysParser interface {
+		FrontMatterKeys(string) []string
+	}
 	MarkupOption struct {
 		Style           string
 		ShowToc         bool
 		TocId           string
 		ShowLineNumbers bool
 		PreventPreCode  bool
+		// 代码高亮使用内联样式或者css class, 可选inline和classes
+		HighlightMode string
+		// 解析[[Page]]格式的wiki链接
+		WikiLinks bool
+		// latex公式的渲染方式, 可选mathml和span, 为空时不处理
+		Math string
 	}
 )
 
+// IncludeError 解析时引用的文件不存在或者无法读取, 不管是否开启严格模式都会使构建失败
+type IncludeError struct {
+	Err error
+}
+
+func (e *IncludeError) Error() string { return e.Err.Error() }
+
+func (e *IncludeError) Unwrap() error { return e.Err }
+
 type parserImpl struct {
 	exts      []string
 	extMap    map[string]MarkupParser
@@ -41,23 +76,79 @@ func (d *parserImpl) Parse(fsys fs.FS, file string) (*Result, error) {
 	if !ok {
 		return nil, fmt.Errorf("no parser for %s", file)
 	}
+	if p, ok := markup.(FSMarkupParser); ok {
+		result, err := p.ParseFS(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("Read file %s err: %w", file, err)
+		}
+		return result, nil
+	}
+
+	f, err := fsys.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var result *Result
+	if p, ok := markup.(LangMarkupParser); ok {
+		result, err = p.ParseLang(f, fileLang(file))
+	} else {
+		result, err = markup.Parse(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Read file %s err: %s", file, err.Error())
+	}
+	return result, nil
+}
+
+func (d *parserImpl) ParseDefaults(fsys fs.FS, file string, defaults map[string]any) (*Result, error) {
+	p, ok := d.extMap[stdpath.Ext(file)].(DefaultsMarkupParser)
+	if !ok || len(defaults) == 0 {
+		return d.Parse(fsys, file)
+	}
+
 	f, err := fsys.Open(file)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
-	result, err := markup.Parse(f)
+	result, err := p.ParseDefaults(f, fileLang(file), defaults)
 	if err != nil {
 		return nil, fmt.Errorf("Read file %s err: %s", file, err.Error())
 	}
 	return result, nil
 }
 
+// fileLang 返回文件名中的语言, 例如hello.en.md返回en
+func fileLang(file string) string {
+	name := strings.TrimSuffix(stdpath.Base(file), stdpath.Ext(file))
+	return strings.TrimPrefix(stdpath.Ext(name), ".")
+}
+
+func (d *parserImpl) add(name string, p MarkupParser) {
+	for _, ext := range p.SupportedExtensions() {
+		if _, ok := d.extMap[ext]; ok {
+			continue
+		}
+		d.exts = append(d.exts, ext)
+		d.extMap[ext] = p
+	}
+	d.formatMap[name] = p
+}
+
 func (d *parserImpl) SupportedExtensions() []string {
 	return d.exts
 }
 
+func (d *parserImpl) FrontMatterKeys(file string) []string {
+	if p, ok := d.extMap[stdpath.Ext(file)].(FrontMatterKeysParser); ok {
+		return p.FrontMatterKeys(file)
+	}
+	return nil
+}
+
 func New(ctx *core.Context) Parser {
 	d := &parserImpl{
 		exts:      make([]string, 0),
@@ -73,15 +164,18 @@ func New(ctx *core.Context) Parser {
 		if !ctx.Config.GetBool(fmt.Sprintf("markups.%s.enabled", name)) {
 			continue
 		}
-		p := factories[name](ctx)
-		for _, ext := range p.SupportedExtensions() {
-			if _, ok := d.extMap[ext]; ok {
-				continue
-			}
-			d.exts = append(d.exts, ext)
-			d.extMap[ext] = p
-		}
-		d.formatMap[name] = p
+		d.add(name, factories[name](ctx))
+	}
+
+	// 内置解析器优先, 外部命令不能覆盖已有的扩展名
+	commands := newCommandParsers(ctx)
+	names = names[:0]
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d.add(name, commands[name])
 	}
 	return d
 }
@@ -91,17 +185,31 @@ const (
 	TocIdTitle = "title"
 )
 
-func NewMarkupOption(ctx *core.Context, name string) MarkupOption {
+// MarkupConfig 解析器配置, core.Context和core.LocaleContext都可以使用
+type MarkupConfig interface {
+	GetMarkupConfig(string, string) core.Result
+}
+
+func NewMarkupOption(ctx MarkupConfig, name string) MarkupOption {
 	opt := MarkupOption{
 		Style:           ctx.GetMarkupConfig(name, "style").String(),
+		HighlightMode:   ctx.GetMarkupConfig(name, "highlight_mode").String(),
 		TocId:           ctx.GetMarkupConfig(name, "toc_id").String(),
 		ShowToc:         ctx.GetMarkupConfig(name, "show_toc").Bool(),
 		ShowLineNumbers: ctx.GetMarkupConfig(name, "show_line_numbers").Bool(),
 		PreventPreCode:  ctx.GetMarkupConfig(name, "prevent_pre_code").Bool(),
+		WikiLinks:       ctx.GetMarkupConfig(name, "wikilinks").Bool(),
+		Math:            ctx.GetMarkupConfig(name, "math").String(),
+	}
+	if !IsMathMode(opt.Math) {
+		opt.Math = ""
 	}
 	if opt.Style == "" {
 		opt.Style = "monokai"
 	}
+	if opt.HighlightMode != HighlightModeClasses {
+		opt.HighlightMode = HighlightModeInline
+	}
 	return opt
 }
 
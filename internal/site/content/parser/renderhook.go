@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"io/fs"
+	stdpath "path"
+	"slices"
+	"strings"
+
+	"github.com/honmaple/snow/internal/core"
+)
+
+const (
+	// RenderHookDir 渲染模版所在的目录, 例如templates/_markup/render-link.html
+	RenderHookDir = "_markup"
+	// RenderHookAttr 需要使用渲染模版的元素属性, 由shortcode hook在渲染页面时替换
+	RenderHookAttr = "data-render-hook"
+
+	RenderHookLink      = "link"
+	RenderHookImage     = "image"
+	RenderHookHeading   = "heading"
+	RenderHookCodeBlock = "codeblock"
+)
+
+var renderHookExts = []string{".html", ".tpl"}
+
+// RenderHookName 返回渲染模版的名称, 代码块优先使用对应语言的模版
+func RenderHookName(kind string, lang string) string {
+	if kind == RenderHookCodeBlock && lang != "" {
+		return "render-" + kind + "-" + lang
+	}
+	return "render-" + kind
+}
+
+// RenderHookTemplates 返回templates/_markup中的渲染模版, key为不包含扩展名的模版名称
+func RenderHookTemplates(ctx *core.Context) map[string]string {
+	results := make(map[string]string)
+	// 渲染模版由shortcode hook执行
+	if !ctx.Config.GetBool("hooks.shortcode.enabled") {
+		return results
+	}
+	fsys, err := ctx.GetFS(core.MountTemplates, true, true)
+	if err != nil {
+		return results
+	}
+	files, err := fs.ReadDir(fsys, RenderHookDir)
+	if err != nil {
+		return results
+	}
+	for _, file := range files {
+		name := file.Name()
+		ext := stdpath.Ext(name)
+		if file.IsDir() || !strings.HasPrefix(name, "render-") || !slices.Contains(renderHookExts, ext) {
+			continue
+		}
+		basename := strings.TrimSuffix(name, ext)
+		if _, ok := results[basename]; !ok {
+			results[basename] = stdpath.Join(RenderHookDir, name)
+		}
+	}
+	return results
+}
@@ -21,6 +21,14 @@ type (
 		Content     string
 		RawSummary  string
 		RawContent  string
+		// org-roam的:ID:属性, value为对应的标题锚点, 文件级别的ID为空
+		IDs map[string]string
+		// 解析时生成的附件, 例如notebook输出的图片, key为相对于内容的文件名
+		Assets map[string][]byte
+		// 解析时读取的其它文件, 例如org-mode的#+INCLUDE, 路径相对于内容目录
+		Includes []string
+		// 不影响输出的错误, 例如latex公式转换失败时使用原始公式
+		Errors []string
 	}
 )
 
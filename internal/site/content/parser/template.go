@@ -44,11 +44,31 @@ func (d *parserImpl) parseString(in *pongo2.Value, param *pongo2.Value) (*pongo2
 	return pongo2.AsValue(result.Content), nil
 }
 
+// {{ chroma_css() }}
+// {{ chroma_css("github", "dracula") }}
+func chromaCSS(ctx *core.Context) func(...string) (string, error) {
+	return func(args ...string) (string, error) {
+		style := ctx.Config.GetString("markups._default.style")
+		if len(args) > 0 && args[0] != "" {
+			style = args[0]
+		}
+		if style == "" {
+			style = "monokai"
+		}
+		darkStyle := ""
+		if len(args) > 1 {
+			darkStyle = args[1]
+		}
+		return ChromaCSS(style, darkStyle)
+	}
+}
+
 func init() {
 	template.Register("parser", func(ctx *core.Context, set template.TemplateSet) error {
 		parser := New(ctx).(*parserImpl)
 
 		set.RegisterFilter("parser", parser.parseString)
+		set.Register("chroma_css", chromaCSS(ctx))
 		return nil
 	})
 }
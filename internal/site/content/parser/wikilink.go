@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"fmt"
+	"html"
+	stdpath "path"
+	"slices"
+	"strings"
+)
+
+// WikiLinkAttr wiki链接占位元素的属性, 由links hook在生成ContentStore后解析为实际链接
+const WikiLinkAttr = "data-wikilink"
+
+// WikiLinkHTML 返回wiki链接的占位HTML, text需要已经转义
+//
+//	[[Page Title]]  -> <a class="wikilink" href="" data-wikilink="Page Title">Page Title</a>
+//	[[page|alias]]  -> <a class="wikilink" href="" data-wikilink="page">alias</a>
+//	![[image.png]]  -> <img class="wikilink" src="" data-wikilink="image.png" alt="image.png">
+func WikiLinkHTML(target string, text string, embed bool) string {
+	if embed {
+		return fmt.Sprintf(`<img class="wikilink" src="" %s="%s" alt="%s">`, WikiLinkAttr, html.EscapeString(target), text)
+	}
+	return fmt.Sprintf(`<a class="wikilink" href="" %s="%s">%s</a>`, WikiLinkAttr, html.EscapeString(target), text)
+}
+
+// 文件链接常用的扩展名, 标题中的其它点号(例如Go 1.21)不影响wiki链接
+var wikiLinkFileExts = []string{
+	".md", ".markdown", ".org", ".adoc", ".asciidoc", ".ipynb", ".html", ".htm", ".txt", ".pdf",
+	".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".avif", ".ico", ".mp3", ".mp4", ".webm",
+	".css", ".js", ".json", ".xml", ".yaml", ".yml", ".toml", ".csv", ".zip", ".tar", ".gz",
+}
+
+// IsWikiLinkTarget 判断org中没有协议的链接是否为wiki链接, 文件路径, 锚点和标题搜索不属于wiki链接
+func IsWikiLinkTarget(link string) bool {
+	link = strings.TrimSpace(link)
+	if link == "" || slices.Contains(wikiLinkFileExts, strings.ToLower(stdpath.Ext(link))) {
+		return false
+	}
+	for _, prefix := range []string{"/", "./", "../", "~", "#", "*", "@/"} {
+		if strings.HasPrefix(link, prefix) {
+			return false
+		}
+	}
+	return !strings.Contains(link, ":")
+}
@@ -0,0 +1,343 @@
+package content
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	stdpath "path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/content/parser"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	UnknownAllow = "allow"
+	UnknownWarn  = "warn"
+	UnknownError = "error"
+)
+
+// 不需要在schema中声明的内置字段
+var builtinFrontMatterKeys = []string{
+	"id", "title", "slug", "date", "modified", "publish_date", "expiry_date",
+	"description", "summary", "lang", "translation_key", "weight",
+	"draft", "hidden", "render", "path", "path_style", "template", "aliases", "assets", "formats", "markdown",
+	"sort_by", "paginate", "paginate_path", "paginate_filter_by", "cascade", "params",
+	"author", "authors", "sitemap", "search", "password",
+}
+
+type (
+	// Schema front matter的字段定义
+	//
+	//	schemas:
+	//	  posts:
+	//	    unknown: error
+	//	    fields:
+	//	      title: {type: string, required: true}
+	//	      status: {type: enum, values: [draft, published]}
+	//	    section:
+	//	      fields: ...
+	Schema struct {
+		Unknown string                  `json:"unknown" yaml:"unknown"`
+		Fields  map[string]*SchemaField `json:"fields" yaml:"fields"`
+		Section *Schema                 `json:"section" yaml:"section"`
+	}
+	SchemaField struct {
+		Type     string `json:"type" yaml:"type"`
+		Items    string `json:"items" yaml:"items"`
+		Values   []any  `json:"values" yaml:"values"`
+		Required bool   `json:"required" yaml:"required"`
+	}
+	// jsonSchema 支持的JSON Schema子集
+	jsonSchema struct {
+		Type                 string                 `json:"type"`
+		Format               string                 `json:"format"`
+		Enum                 []any                  `json:"enum"`
+		Items                *jsonSchema            `json:"items"`
+		Required             []string               `json:"required"`
+		Properties           map[string]*jsonSchema `json:"properties"`
+		AdditionalProperties *bool                  `json:"additionalProperties"`
+	}
+)
+
+func (s *jsonSchema) fieldType() string {
+	switch {
+	case len(s.Enum) > 0:
+		return "enum"
+	case s.Format == "date" || s.Format == "date-time":
+		return "date"
+	}
+	switch s.Type {
+	case "integer":
+		return "int"
+	case "number":
+		return "float"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "list"
+	case "object":
+		return "map"
+	}
+	return s.Type
+}
+
+func (s *jsonSchema) toSchema() *Schema {
+	schema := &Schema{
+		Unknown: UnknownAllow,
+		Fields:  make(map[string]*SchemaField),
+	}
+	if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+		schema.Unknown = UnknownError
+	}
+	for name, prop := range s.Properties {
+		field := &SchemaField{
+			Type:   prop.fieldType(),
+			Values: prop.Enum,
+		}
+		if prop.Items != nil {
+			field.Items = prop.Items.fieldType()
+		}
+		schema.Fields[name] = field
+	}
+	for _, name := range s.Required {
+		if field, ok := schema.Fields[name]; ok {
+			field.Required = true
+		} else {
+			schema.Fields[name] = &SchemaField{Required: true}
+		}
+	}
+	return schema
+}
+
+func checkType(typ string, value any, values []any) error {
+	switch typ {
+	case "":
+		return nil
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("expected string, got %T", value)
+		}
+	case "int":
+		switch v := value.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		case float64:
+			if v != float64(int64(v)) {
+				return fmt.Errorf("expected int, got %v", v)
+			}
+		default:
+			return fmt.Errorf("expected int, got %T", value)
+		}
+	case "float":
+		if _, err := cast.ToFloat64E(value); err != nil {
+			return fmt.Errorf("expected float, got %T", value)
+		}
+		if _, ok := value.(string); ok {
+			return fmt.Errorf("expected float, got string")
+		}
+	case "bool":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected bool, got %T", value)
+		}
+	case "date":
+		// 兼容toml时间格式
+		switch v := value.(type) {
+		case time.Time, toml.LocalDate, toml.LocalDateTime:
+		case string:
+			if _, err := cast.ToTimeE(v); err != nil {
+				return fmt.Errorf("expected date, got %q", v)
+			}
+		default:
+			return fmt.Errorf("expected date, got %T", value)
+		}
+	case "list":
+		switch value.(type) {
+		case []any, []string:
+		default:
+			return fmt.Errorf("expected list, got %T", value)
+		}
+	case "map":
+		if _, err := cast.ToStringMapE(value); err != nil {
+			return fmt.Errorf("expected map, got %T", value)
+		}
+	case "enum":
+		for _, v := range values {
+			if cast.ToString(v) == cast.ToString(value) {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected one of %v, got %v", values, value)
+	default:
+		return fmt.Errorf("unknown type %q", typ)
+	}
+	return nil
+}
+
+// Validate 检查front matter, 返回不符合schema的字段错误和未定义的字段
+func (s *Schema) Validate(fm *FrontMatter, raw map[string]any) ([]error, []string) {
+	errs := make([]error, 0)
+
+	names := make([]string, 0, len(s.Fields))
+	for name := range s.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		field := s.Fields[name]
+
+		value := fm.Get(name)
+		if value == nil {
+			if field.Required {
+				errs = append(errs, fmt.Errorf("%s: required", name))
+			}
+			continue
+		}
+		if err := checkType(field.Type, value, field.Values); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		if field.Type == "list" && field.Items != "" {
+			for i, item := range cast.ToSlice(value) {
+				if err := checkType(field.Items, item, field.Values); err != nil {
+					errs = append(errs, fmt.Errorf("%s[%d]: %w", name, i, err))
+				}
+			}
+		}
+	}
+
+	unknown := make([]string, 0)
+	if s.Unknown == UnknownAllow || s.Unknown == "" {
+		return errs, unknown
+	}
+	for key := range raw {
+		key = strings.ToLower(key)
+		if _, ok := s.Fields[key]; ok || slices.Contains(builtinFrontMatterKeys, key) {
+			continue
+		}
+		unknown = append(unknown, key)
+	}
+	sort.Strings(unknown)
+	return errs, unknown
+}
+
+func (d *Processor) loadSchema(value any) (*Schema, error) {
+	if file, ok := value.(string); ok {
+		buf, err := fs.ReadFile(d.ctx.FS, stdpath.Join("schemas", file))
+		if err != nil {
+			return nil, err
+		}
+		if stdpath.Ext(file) == ".json" {
+			var s jsonSchema
+			if err := json.Unmarshal(buf, &s); err != nil {
+				return nil, err
+			}
+			return s.toSchema(), nil
+		}
+		var schema Schema
+		if err := yaml.Unmarshal(buf, &schema); err != nil {
+			return nil, err
+		}
+		return &schema, nil
+	}
+
+	buf, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var schema Schema
+	if err := yaml.Unmarshal(buf, &schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// findSchema 按照schemas.{目录路径} -> 父目录 -> schemas._default查找schema
+func (d *Processor) findSchema(dir string, lang string) (*Schema, error) {
+	lctx := d.ctx.For(lang)
+	for {
+		if dir == "" || dir == "." {
+			dir = "_default"
+		}
+		if key := "schemas." + dir; lctx.Config.IsSet(key) {
+			// 不同语言可以使用不同的schema
+			cacheKey := lang + ":" + key
+			if v, ok := d.schemas.Load(cacheKey); ok {
+				return v.(*Schema), nil
+			}
+			schema, err := d.loadSchema(lctx.Config.Get(key))
+			if err != nil {
+				return nil, fmt.Errorf("load %s: %w", key, err)
+			}
+			for name, field := range schema.Fields {
+				if field == nil {
+					schema.Fields[name] = &SchemaField{}
+				}
+			}
+			d.schemas.Store(cacheKey, schema)
+			return schema, nil
+		}
+		if dir == "_default" {
+			return nil, nil
+		}
+		dir = stdpath.Dir(dir)
+	}
+}
+
+// validateFrontMatter 检查page和section的front matter, 错误只记录到Reporter, 内容仍然会被渲染
+func (d *Processor) validateFrontMatter(node *Node, raw map[string]any, kind string) {
+	report := func(err error) {
+		e := &core.Error{Op: "validate front matter", Err: err, Path: node.File.Path}
+		d.ctx.Logger.Error(e.Error())
+		d.ctx.Reporter.Error(e)
+	}
+
+	schema, err := d.findSchema(node.File.Dir, node.Lang)
+	if err != nil {
+		report(err)
+		return
+	}
+	// _index文件使用section中的配置
+	if schema != nil && kind == "section" {
+		schema = schema.Section
+	}
+	if schema == nil {
+		return
+	}
+
+	errs, unknown := schema.Validate(node.FrontMatter, raw)
+	// 分类字段, front_matter中配置的时间字段和解析器生成的字段不需要在schema中声明
+	lctx := d.ctx.For(node.Lang)
+	taxonomies := lctx.Config.GetStringMap("taxonomies")
+	ignoreKeys := append(lctx.Config.GetStringSlice("front_matter.publish_date"), lctx.Config.GetStringSlice("front_matter.expiry_date")...)
+	if p, ok := d.parser.(parser.FrontMatterKeysParser); ok {
+		ignoreKeys = append(ignoreKeys, p.FrontMatterKeys(node.File.Path)...)
+	}
+	unknown = slices.DeleteFunc(unknown, func(key string) bool {
+		_, ok := taxonomies[key]
+		return ok || slices.Contains(ignoreKeys, key)
+	})
+	if len(unknown) > 0 {
+		unknownErr := fmt.Errorf("unknown keys: %s", strings.Join(unknown, ", "))
+		if schema.Unknown == UnknownError {
+			errs = append(errs, unknownErr)
+		} else {
+			d.ctx.Logger.Warnf("validate front matter %s: %s", node.File.Path, unknownErr)
+			d.ctx.Reporter.Warn(&core.Error{Op: "validate front matter", Err: unknownErr, Path: node.File.Path})
+		}
+	}
+	if len(errs) > 0 {
+		msgs := make([]string, len(errs))
+		for i, err := range errs {
+			msgs[i] = err.Error()
+		}
+		report(errors.New(strings.Join(msgs, "; ")))
+	}
+}
@@ -0,0 +1,175 @@
+package content
+
+import (
+	"encoding/json"
+	"path"
+	"testing"
+	"testing/fstest"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFrontMatter(t *testing.T) {
+	p := cascadeTestParser{
+		"posts/_index.md": {"title": "Posts"},
+		"posts/a.md": {
+			"title":  "A",
+			"date":   "2024-01-02",
+			"status": "published",
+			"tags":   []any{"go", "snow"},
+		},
+		"posts/b.md": {
+			"date":   "yesterday",
+			"status": "archived",
+			"tags":   []any{"go", 1},
+		},
+		"posts/c.md":      {"title": "C", "status": "draft", "extra": true},
+		"posts/e.md":      {"title": "E", "date": toml.LocalDate{Year: 2024, Month: 1, Day: 2}},
+		"posts/f.md":      {"title": "F", "date": toml.LocalDateTime{LocalDate: toml.LocalDate{Year: 2024, Month: 1, Day: 2}}},
+		"posts/2024/d.md": {"title": "D", "status": "draft"},
+		"about.md":        {"anything": 1},
+	}
+	fsys := fstest.MapFS{}
+	for file := range p {
+		fsys[file] = &fstest.MapFile{}
+	}
+
+	conf := core.DefaultConfig()
+	conf.Set("schemas.posts", map[string]any{
+		"unknown": "warn",
+		"fields": map[string]any{
+			"title":  map[string]any{"type": "string", "required": true},
+			"date":   map[string]any{"type": "date"},
+			"status": map[string]any{"type": "enum", "values": []any{"draft", "published"}},
+			"tags":   map[string]any{"type": "list", "items": "string"},
+		},
+		"section": map[string]any{
+			"fields": map[string]any{
+				"title": map[string]any{"type": "string", "required": true},
+			},
+		},
+	})
+	ctx, err := core.NewContext(conf)
+	require.NoError(t, err)
+
+	processor := NewProcessor(ctx, fsys, WithParser(p))
+
+	_, err = processor.ParseSection("posts/_index.md")
+	require.NoError(t, err)
+
+	_, err = processor.ParsePage("posts/a.md", false)
+	require.NoError(t, err)
+
+	// 检查失败时记录错误, 页面仍然会被解析
+	page, err := processor.ParsePage("posts/b.md", false)
+	require.NoError(t, err)
+	assert.NotNil(t, page)
+	errs := ctx.Reporter.Errors()
+	require.Len(t, errs, 1)
+	assert.Equal(t, `validate front matter posts/b.md: date: expected date, got "yesterday"; status: expected one of [draft published], got archived; tags[1]: expected string, got int; title: required`, errs[0].Error())
+
+	// toml的时间格式
+	_, err = processor.ParsePage("posts/e.md", false)
+	require.NoError(t, err)
+	_, err = processor.ParsePage("posts/f.md", false)
+	require.NoError(t, err)
+	assert.Len(t, ctx.Reporter.Errors(), 1)
+
+	// 未定义的字段只警告
+	_, err = processor.ParsePage("posts/c.md", false)
+	require.NoError(t, err)
+	warnings := ctx.Reporter.Warnings()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "posts/c.md", warnings[0].Path)
+	assert.EqualError(t, warnings[0].Err, "unknown keys: extra")
+
+	// 子目录使用上级目录的schema
+	_, err = processor.ParsePage("posts/2024/d.md", false)
+	require.NoError(t, err)
+
+	// 没有schema的页面不检查
+	_, err = processor.ParsePage("about.md", false)
+	require.NoError(t, err)
+}
+
+func TestJSONSchema(t *testing.T) {
+	var s jsonSchema
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "type": "object",
+  "required": ["title"],
+  "additionalProperties": false,
+  "properties": {
+    "title": {"type": "string"},
+    "count": {"type": "integer"},
+    "date": {"type": "string", "format": "date"},
+    "status": {"enum": ["draft", "published"]},
+    "tags": {"type": "array", "items": {"type": "string"}}
+  }
+}`), &s))
+
+	schema := s.toSchema()
+	assert.Equal(t, UnknownError, schema.Unknown)
+	assert.Equal(t, &SchemaField{Type: "string", Required: true}, schema.Fields["title"])
+	assert.Equal(t, "int", schema.Fields["count"].Type)
+	assert.Equal(t, "date", schema.Fields["date"].Type)
+	assert.Equal(t, "enum", schema.Fields["status"].Type)
+	assert.Equal(t, &SchemaField{Type: "list", Items: "string"}, schema.Fields["tags"])
+
+	errs, unknown := schema.Validate(NewFrontMatter(map[string]any{"count": 1.5, "other": 1}), map[string]any{"count": 1.5, "other": 1})
+	assert.Len(t, errs, 2)
+	assert.Equal(t, []string{"other"}, unknown)
+}
+
+type schemaTestParser struct {
+	cascadeTestParser
+}
+
+func (p schemaTestParser) SupportedExtensions() []string {
+	return []string{".md", ".adoc"}
+}
+
+func (p schemaTestParser) FrontMatterKeys(file string) []string {
+	if path.Ext(file) == ".adoc" {
+		return []string{"revdate"}
+	}
+	return nil
+}
+
+func TestValidateFrontMatterLanguagesAndParserKeys(t *testing.T) {
+	p := schemaTestParser{cascadeTestParser{
+		"posts/a.md":    {"title": "A", "extra": true},
+		"posts/a.fr.md": {"title": "A", "extra": true},
+		"posts/b.adoc":  {"title": "B", "revdate": "2024-01-02"},
+		"posts/c.adoc":  {"title": "C", "custom": true},
+	}}
+	fsys := fstest.MapFS{}
+	for file := range p.cascadeTestParser {
+		fsys[file] = &fstest.MapFile{}
+	}
+
+	conf := core.DefaultConfig()
+	conf.Set("schemas.posts", map[string]any{
+		"unknown": "error",
+		"fields": map[string]any{
+			"title": map[string]any{"type": "string", "required": true},
+		},
+	})
+	conf.Set("languages.fr.schemas.posts.unknown", "allow")
+	ctx, err := core.NewContext(conf)
+	require.NoError(t, err)
+
+	processor := NewProcessor(ctx, fsys, WithParser(p))
+	for _, file := range []string{"posts/a.fr.md", "posts/a.md", "posts/b.adoc", "posts/c.adoc"} {
+		_, err := processor.ParsePage(file, false)
+		require.NoError(t, err)
+	}
+
+	// 不同语言的schema分别缓存, 解析器生成的字段不需要声明
+	errs := ctx.Reporter.Errors()
+	require.Len(t, errs, 2)
+	assert.Equal(t, "validate front matter posts/a.md: unknown keys: extra", errs[0].Error())
+	assert.Equal(t, "validate front matter posts/c.adoc: unknown keys: custom", errs[1].Error())
+}
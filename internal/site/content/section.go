@@ -26,6 +26,10 @@ type (
 
 		Parent   *Section
 		Children Sections
+		// 其它语言的版本, key为语言
+		Translations map[string]*Section
+		// 链接到当前栏目的内容, 由links hook解析
+		Backlinks Backlinks
 	}
 	Sections []*Section
 )
@@ -212,11 +216,20 @@ func (d *Processor) ParseSection(fullpath string) (*Section, error) {
 	section.Path = d.resolveSectionPath(section, section.FrontMatter.GetString("path"))
 	section.Permalink = lctx.GetURL(section.Path)
 
+	// 没有cascade时也需要保存, 翻译的_index存在时不再使用默认语言的cascade
+	d.cascades.Store(cascadeKey(section.File.Dir, section.Lang), parseCascades(section.FrontMatter))
+
 	assets, err := d.ParseSectionAssets(section)
 	if err != nil {
 		return nil, err
 	}
 	section.Assets = assets
+
+	generated, err := d.parseGeneratedAssets(section.Node, section.Path)
+	if err != nil {
+		return nil, err
+	}
+	section.Assets = append(section.Assets, generated...)
 	section.Formats = d.ParseSectionFormats(section)
 	return section, nil
 }
@@ -272,6 +285,15 @@ func (d *Processor) ParseSectionFormats(section *Section) Formats {
 	return formats
 }
 
+func (d *Processor) sectionPagers(section *Section) Pagers {
+	return d.PaginateBy(section.Pages.FilterBy(section.FrontMatter.GetString("paginate_filter_by")),
+		section.FrontMatter.GetInt("paginate"),
+		section.Path,
+		section.FrontMatter.GetString("paginate_path"),
+		section.Lang,
+	)
+}
+
 func (d *Processor) RenderSection(section *Section, tplset template.TemplateSet, writer core.Writer) error {
 	d.ctx.Logger.Debugf("write section [%s] -> %s", section.File.Path, section.Path)
 
@@ -292,12 +314,7 @@ func (d *Processor) RenderSection(section *Section, tplset template.TemplateSet,
 	}
 
 	if tpl := tplset.Lookup(lookups...); tpl != nil {
-		pagers := d.PaginateBy(section.Pages.FilterBy(section.FrontMatter.GetString("paginate_filter_by")),
-			section.FrontMatter.GetInt("paginate"),
-			section.Path,
-			section.FrontMatter.GetString("paginate_path"),
-			section.Lang,
-		)
+		pagers := d.sectionPagers(section)
 		for _, pager := range pagers {
 			if err := d.RenderTemplate(pager.Path, tpl, map[string]any{
 				"paginator":     NewPaginator(pager, pagers),
@@ -312,23 +329,19 @@ func (d *Processor) RenderSection(section *Section, tplset template.TemplateSet,
 
 	if tpl := tplset.Lookup("alias.html", "partials/alias.html"); tpl != nil {
 		for _, alias := range section.FrontMatter.GetStringSlice("aliases") {
-			if alias == "" || alias == "." || stdpath.Clean(alias) != alias {
+			aliasPath, ok := d.resolveAliasPath(alias, section.Path)
+			if !ok {
 				d.ctx.Logger.Warnf("invalid alias '%s' for %s", alias, section.File.Path)
+				d.ctx.Reporter.Warn(&core.Error{Op: "render alias", Err: fmt.Errorf("invalid alias '%s'", alias), Path: section.File.Path})
 				continue
 			}
-			if !strings.HasPrefix(alias, "/") {
-				if strings.HasSuffix(section.Path, "/") {
-					alias = stdpath.Join(section.Path, alias)
-				} else {
-					alias = stdpath.Join(stdpath.Dir(section.Path), alias)
-				}
-			}
 			// alias可以使用变量，方便重构url
-			alias = d.resolveSectionPath(section, alias)
+			alias = d.resolveSectionPath(section, aliasPath)
 
 			d.ctx.Logger.Debugf("write section alias [%s] -> %s", alias, section.Path)
 			if err := d.RenderTemplate(alias, tpl, map[string]any{
 				"url":          section.Permalink,
+				"section":      section,
 				"current_lang": section.Lang,
 			}, writer); err != nil {
 				return err
@@ -73,6 +73,19 @@ func (d *Processor) ParseTaxonomies(pages Pages, lang string) Taxonomies {
 }
 
 func (d *Processor) RenderTaxonomy(taxonomy *Taxonomy, tplset template.TemplateSet, writer core.Writer) error {
+	if err := d.RenderTaxonomyList(taxonomy, tplset, writer); err != nil {
+		return err
+	}
+	for _, term := range taxonomy.Terms {
+		if err := d.RenderTaxonomyTerm(term, tplset, writer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderTaxonomyList 只渲染分类列表页, 不包括分类下的term
+func (d *Processor) RenderTaxonomyList(taxonomy *Taxonomy, tplset template.TemplateSet, writer core.Writer) error {
 	lctx := d.ctx.For(taxonomy.Lang)
 
 	lookups := []string{
@@ -91,11 +104,5 @@ func (d *Processor) RenderTaxonomy(taxonomy *Taxonomy, tplset template.TemplateS
 			return err
 		}
 	}
-
-	for _, term := range taxonomy.Terms {
-		if err := d.RenderTaxonomyTerm(term, tplset, writer); err != nil {
-			return err
-		}
-	}
 	return nil
 }
@@ -187,6 +187,17 @@ func (d *Processor) ParseTaxonomyTermFormats(term *TaxonomyTerm, lang string) Fo
 	return formats
 }
 
+func (d *Processor) taxonomyTermPagers(term *TaxonomyTerm) Pagers {
+	lctx := d.ctx.For(term.Taxonomy.Lang)
+
+	return d.PaginateBy(term.Pages.FilterBy(lctx.GetTaxonomyConfig(term.Taxonomy.Name, "term.paginate_filter_by").String()),
+		lctx.GetTaxonomyConfig(term.Taxonomy.Name, "term.paginate").Int(),
+		term.Path,
+		lctx.GetTaxonomyConfig(term.Taxonomy.Name, "term.paginate_path").String(),
+		term.Taxonomy.Lang,
+	)
+}
+
 func (d *Processor) RenderTaxonomyTerm(term *TaxonomyTerm, tplset template.TemplateSet, writer core.Writer) error {
 	lctx := d.ctx.For(term.Taxonomy.Lang)
 
@@ -198,12 +209,7 @@ func (d *Processor) RenderTaxonomyTerm(term *TaxonomyTerm, tplset template.Templ
 	if tpl := tplset.Lookup(lookups...); tpl != nil {
 		d.ctx.Logger.Debugf("write taxonomy term [%s:%s] -> %s", term.Taxonomy.Name, term.GetFullName(), term.Path)
 
-		pagers := d.PaginateBy(term.Pages.FilterBy(lctx.GetTaxonomyConfig(term.Taxonomy.Name, "term.paginate_filter_by").String()),
-			lctx.GetTaxonomyConfig(term.Taxonomy.Name, "term.paginate").Int(),
-			term.Path,
-			lctx.GetTaxonomyConfig(term.Taxonomy.Name, "term.paginate_path").String(),
-			term.Taxonomy.Lang,
-		)
+		pagers := d.taxonomyTermPagers(term)
 		for _, pager := range pagers {
 			if err := d.RenderTemplate(pager.Path, tpl, map[string]any{
 				"paginator":     NewPaginator(pager, pagers),
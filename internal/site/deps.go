@@ -0,0 +1,385 @@
+package site
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/honmaple/snow/internal/site/content"
+)
+
+type (
+	// Dependencies 记录每个page, section, taxonomy和taxonomy term渲染时依赖的源文件和模版,
+	// 以及输出的文件, 用于开发服务器只重新渲染受影响的内容
+	Dependencies struct {
+		mu    sync.RWMutex
+		items map[string]*dependency
+		// 解析内容时hook读取的其它文件, 例如include shortcode引用的文件, key为内容的源文件
+		includes map[string]map[string]bool
+	}
+	dependency struct {
+		files   map[string]bool
+		outputs map[string]bool
+		// 模版中读取的pages, sections, taxonomies等内容列表, 例如pages:zh
+		collections map[string]bool
+		templates   map[string]bool
+	}
+)
+
+func newDependency() *dependency {
+	return &dependency{
+		files:       make(map[string]bool),
+		outputs:     make(map[string]bool),
+		collections: make(map[string]bool),
+		templates:   make(map[string]bool),
+	}
+}
+
+// collectionKey 返回内容列表的名称, 例如pages:zh, sections:en
+func collectionKey(name string, lang string) string {
+	return name + ":" + lang
+}
+
+func (d *Dependencies) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.items = make(map[string]*dependency)
+}
+
+func (d *Dependencies) get(key string) *dependency {
+	item, ok := d.items[key]
+	if !ok {
+		item = newDependency()
+		d.items[key] = item
+	}
+	return item
+}
+
+// RecordFile 记录解析path时读取的其它文件, 渲染path对应的内容时会作为依赖的文件
+func (d *Dependencies) RecordFile(path string, file string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.includes == nil {
+		d.includes = make(map[string]map[string]bool)
+	}
+	files, ok := d.includes[path]
+	if !ok {
+		files = make(map[string]bool)
+		d.includes[path] = files
+	}
+	files[file] = true
+}
+
+// Includes 返回解析path时读取的其它文件
+func (d *Dependencies) Includes(path string) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return slices.Sorted(maps.Keys(d.includes[path]))
+}
+
+// IncludedBy 返回解析时读取了指定文件的内容
+func (d *Dependencies) IncludedBy(files map[string]bool) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	paths := make([]string, 0)
+	for path, includes := range d.includes {
+		for file := range includes {
+			if files[file] {
+				paths = append(paths, path)
+				break
+			}
+		}
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// ResetIncludes 重新解析内容之前清除记录的文件, 没有指定path时清除所有的记录
+func (d *Dependencies) ResetIncludes(paths ...string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(paths) == 0 {
+		d.includes = nil
+		return
+	}
+	for _, path := range paths {
+		delete(d.includes, path)
+	}
+}
+
+// Track 开始重新记录某个内容的依赖, 返回之前记录的输出, 用于渲染后删除不再输出的文件
+func (d *Dependencies) Track(key string, files []string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var outputs []string
+	if item, ok := d.items[key]; ok {
+		outputs = slices.Sorted(maps.Keys(item.outputs))
+	}
+
+	item := newDependency()
+	for _, file := range files {
+		item.files[file] = true
+	}
+	d.items[key] = item
+	return outputs
+}
+
+// StaleOutputs 返回重新渲染后不再输出的文件, 例如减少的分页
+func (d *Dependencies) StaleOutputs(key string, outputs []string) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	item, ok := d.items[key]
+	if !ok {
+		return nil
+	}
+	results := make([]string, 0)
+	for _, output := range outputs {
+		if !item.outputs[output] {
+			results = append(results, output)
+		}
+	}
+	return results
+}
+
+func (d *Dependencies) Record(key string, output string, template string) {
+	if key == "" {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	item := d.get(key)
+	if output != "" {
+		item.outputs[output] = true
+	}
+	if template != "" {
+		item.templates[template] = true
+	}
+}
+
+// RecordSource 记录模版中通过get_page, get_section等读取的内容源文件
+func (d *Dependencies) RecordSource(key string, files ...string) {
+	if key == "" {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	item := d.get(key)
+	for _, file := range files {
+		item.files[file] = true
+	}
+}
+
+// RecordCollection 记录模版中读取的内容列表, 列表中的内容增加, 删除或者修改后需要重新渲染
+func (d *Dependencies) RecordCollection(key string, collection string) {
+	if key == "" {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.get(key).collections[collection] = true
+}
+
+func (d *Dependencies) Remove(key string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	item, ok := d.items[key]
+	if !ok {
+		return nil
+	}
+	delete(d.items, key)
+	return slices.Sorted(maps.Keys(item.outputs))
+}
+
+func (d *Dependencies) Keys() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return slices.Collect(maps.Keys(d.items))
+}
+
+// DependsOnFiles 返回内容是否依赖于修改的文件或者内容列表
+func (d *Dependencies) DependsOnFiles(key string, files map[string]bool, collections map[string]bool) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	item, ok := d.items[key]
+	if !ok {
+		return false
+	}
+	for file := range files {
+		if item.files[file] {
+			return true
+		}
+	}
+	for collection := range collections {
+		if item.collections[collection] {
+			return true
+		}
+	}
+	return false
+}
+
+// DependsOn 返回是否有内容依赖于指定的文件
+func (d *Dependencies) DependsOn(file string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, item := range d.items {
+		if item.files[file] {
+			return true
+		}
+	}
+	return false
+}
+
+// ExternalFiles 返回内容目录之外的依赖文件, 例如include shortcode中/开头的路径, 路径相对于项目根目录并以/开头
+func (d *Dependencies) ExternalFiles() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	files := make(map[string]bool)
+	for _, item := range d.items {
+		for file := range item.files {
+			if strings.HasPrefix(file, "/") {
+				files[file] = true
+			}
+		}
+	}
+	return slices.Sorted(maps.Keys(files))
+}
+
+func (d *Dependencies) Contains(key string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	_, ok := d.items[key]
+	return ok
+}
+
+// FindByTemplates 返回使用了指定模版渲染的内容, 如果有模版没有被任何内容直接使用则返回false
+func (d *Dependencies) FindByTemplates(templates []string) (map[string]bool, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	results := make(map[string]bool)
+	for _, template := range templates {
+		found := false
+		for key, item := range d.items {
+			if item.templates[template] {
+				results[key] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return results, true
+}
+
+func dependencyKey(v any) string {
+	switch v := v.(type) {
+	case *content.Page:
+		return fmt.Sprintf("page:%s:%s", v.Lang, v.File.Path)
+	case *content.Section:
+		return fmt.Sprintf("section:%s:%s", v.Lang, v.File.Path)
+	case *content.Taxonomy:
+		return fmt.Sprintf("taxonomy:%s:%s", v.Lang, v.Name)
+	case *content.TaxonomyTerm:
+		return fmt.Sprintf("term:%s:%s:%s", v.Taxonomy.Lang, v.Taxonomy.Name, v.GetFullName())
+	}
+	return ""
+}
+
+func dependencyKeyFromVars(vars map[string]any) string {
+	for _, name := range []string{"term", "taxonomy", "page", "section"} {
+		if v, ok := vars[name]; ok {
+			if key := dependencyKey(v); key != "" {
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+func dependencyOutput(path string) string {
+	if path == "" {
+		return ""
+	}
+	if strings.HasSuffix(path, "/") {
+		path = path + "index.html"
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
+
+// dependencyFiles 返回渲染时会使用到的内容源文件
+func dependencyFiles(v any) []string {
+	files := make([]string, 0)
+	addPages := func(pages content.Pages) {
+		for _, page := range pages {
+			files = append(files, page.File.Path)
+		}
+	}
+
+	switch v := v.(type) {
+	case *content.Page:
+		files = append(files, v.File.Path)
+		files = append(files, v.Includes...)
+		for _, translation := range v.Translations {
+			files = append(files, translation.File.Path)
+		}
+		// 上级section的cascade配置
+		for _, section := range v.Ancestors() {
+			files = append(files, section.File.Path)
+		}
+		for _, link := range v.Backlinks {
+			files = append(files, link.File.Path)
+		}
+		if v.Section != nil {
+			// 上一篇和下一篇的标题等信息
+			related := v.Section.Pages.Related(v)
+			if prev := related.Prev(); prev != nil {
+				files = append(files, prev.File.Path)
+			}
+			if next := related.Next(); next != nil {
+				files = append(files, next.File.Path)
+			}
+		}
+	case *content.Section:
+		files = append(files, v.File.Path)
+		files = append(files, v.Includes...)
+		for _, section := range v.Ancestors() {
+			files = append(files, section.File.Path)
+		}
+		for _, translation := range v.Translations {
+			files = append(files, translation.File.Path)
+		}
+		for _, link := range v.Backlinks {
+			files = append(files, link.File.Path)
+		}
+		addPages(v.AllPages())
+		addPages(v.AllHiddenPages())
+		for _, child := range v.Children {
+			files = append(files, child.File.Path)
+		}
+	case *content.Taxonomy:
+		for _, term := range v.Terms {
+			addPages(term.Pages)
+		}
+	case *content.TaxonomyTerm:
+		addPages(v.Pages)
+	}
+	return files
+}
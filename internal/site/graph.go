@@ -0,0 +1,113 @@
+package site
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/honmaple/snow/internal/site/content"
+)
+
+type (
+	GraphNode struct {
+		// 内容文件路径
+		ID        string `json:"id"`
+		Kind      string `json:"kind"`
+		Lang      string `json:"lang"`
+		Title     string `json:"title"`
+		Permalink string `json:"permalink"`
+	}
+	GraphEdge struct {
+		Source string `json:"source"`
+		Target string `json:"target"`
+	}
+	// Graph 内容之间的链接关系, 由links hook解析的backlinks生成
+	Graph struct {
+		Nodes []*GraphNode `json:"nodes"`
+		Edges []*GraphEdge `json:"edges"`
+	}
+)
+
+func (g *Graph) addNode(node *content.Node, kind string, permalink string, backlinks content.Backlinks) {
+	g.Nodes = append(g.Nodes, &GraphNode{
+		ID:        node.File.Path,
+		Kind:      kind,
+		Lang:      node.Lang,
+		Title:     node.Title,
+		Permalink: permalink,
+	})
+	for _, link := range backlinks {
+		g.Edges = append(g.Edges, &GraphEdge{
+			Source: link.File.Path,
+			Target: node.File.Path,
+		})
+	}
+}
+
+// WriteJSON 输出JSON格式的链接关系
+func (g *Graph) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(g)
+}
+
+// WriteDOT 输出Graphviz DOT格式的链接关系
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph snow {\n")
+	for _, node := range g.Nodes {
+		label := node.Title
+		if label == "" {
+			label = node.ID
+		}
+		shape := "ellipse"
+		if node.Kind == "section" {
+			shape = "box"
+		}
+		fmt.Fprintf(&b, "  %s [label=%s, shape=%s];\n", strconv.Quote(node.ID), strconv.Quote(label), shape)
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s;\n", strconv.Quote(edge.Source), strconv.Quote(edge.Target))
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Graph 解析内容并返回所有页面和栏目之间的链接关系
+func (site *Site) Graph() (*Graph, error) {
+	_, store, err := site.parseContent()
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Graph{
+		Nodes: make([]*GraphNode, 0),
+		Edges: make([]*GraphEdge, 0),
+	}
+	for _, lang := range site.ctx.GetAllLanguages() {
+		for _, section := range store.Sections(lang) {
+			g.addNode(section.Node, "section", section.Permalink, section.Backlinks)
+		}
+		for _, page := range store.Pages(lang) {
+			g.addNode(page.Node, "page", page.Permalink, page.Backlinks)
+		}
+		for _, page := range store.HiddenPages(lang) {
+			g.addNode(page.Node, "page", page.Permalink, page.Backlinks)
+		}
+	}
+	slices.SortFunc(g.Nodes, func(a, b *GraphNode) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	slices.SortFunc(g.Edges, func(a, b *GraphEdge) int {
+		if c := strings.Compare(a.Source, b.Source); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Target, b.Target)
+	})
+	return g, nil
+}
@@ -14,6 +14,8 @@ type (
 		BeforeBuild() error
 		HandleWriter(core.Writer) (core.Writer, error)
 		HandleTemplateSet(template.TemplateSet) (template.TemplateSet, error)
+		// HandleDependencies 在创建模版之前调用, 用于记录内容读取的其它文件
+		HandleDependencies(DependencyRecorder)
 	}
 	ContentHook interface {
 		HandlePage(*content.Page) *content.Page
@@ -28,6 +30,12 @@ type (
 		Pages(string) content.Pages
 		HiddenPages(string) content.Pages
 		Sections(string) content.Sections
+		Taxonomies(string) content.Taxonomies
+	}
+	// DependencyRecorder 记录内容读取的其它文件, path为内容的源文件,
+	// 开发服务器中file修改后会重新解析和渲染对应的内容
+	DependencyRecorder interface {
+		RecordFile(path string, file string)
 	}
 	Hook interface {
 		BuildHook
@@ -41,6 +49,7 @@ func (HookImpl) AfterBuild(context.Context, core.Writer) error          { return
 func (HookImpl) BeforeBuild() error                                     { return nil }
 func (HookImpl) HandlePage(result *content.Page) *content.Page          { return result }
 func (HookImpl) HandleContent(ContentStore, string)                     {}
+func (HookImpl) HandleDependencies(DependencyRecorder)                  {}
 func (HookImpl) HandleSection(result *content.Section) *content.Section { return result }
 func (HookImpl) HandleWriter(writer core.Writer) (core.Writer, error) {
 	return writer, nil
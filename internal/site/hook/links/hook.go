@@ -2,6 +2,7 @@ package links
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"net/url"
 	stdpath "path"
@@ -11,21 +12,33 @@ import (
 
 	"github.com/honmaple/snow/internal/core"
 	"github.com/honmaple/snow/internal/site/content"
+	"github.com/honmaple/snow/internal/site/content/parser"
 	"github.com/honmaple/snow/internal/site/hook"
 )
 
-type LinksHook struct {
-	hook.HookImpl
+type (
+	Option struct {
+		// 未找到目标的wiki链接添加的class
+		UnresolvedClass string `json:"unresolved_class"`
+	}
+	LinksHook struct {
+		hook.HookImpl
 
-	ctx *core.Context
-}
+		ctx *core.Context
+		opt Option
+	}
+)
 
 func init() {
 	hook.Register("links", New)
 }
 
 func New(ctx *core.Context) (hook.Hook, error) {
-	return &LinksHook{ctx: ctx}, nil
+	var opt Option
+	if err := hook.Unmarshal(ctx.Config.Get("hooks.links.option"), &opt); err != nil {
+		return nil, err
+	}
+	return &LinksHook{ctx: ctx, opt: opt}, nil
 }
 
 func (h *LinksHook) HandleContent(store hook.ContentStore, lang string) {
@@ -34,6 +47,9 @@ func (h *LinksHook) HandleContent(store hook.ContentStore, lang string) {
 	sections := store.Sections(lang)
 
 	rewriter := newContentLinkRewriter(h.ctx, pages, hidden, sections)
+	if h.opt.UnresolvedClass != "" {
+		rewriter.unresolvedClass = h.opt.UnresolvedClass
+	}
 	rewriter.Rewrite(pages)
 	rewriter.Rewrite(hidden)
 	rewriter.RewriteSections(sections)
@@ -44,6 +60,16 @@ type contentLinkRewriter struct {
 	targets    map[string]string
 	outputs    map[string]struct{}
 	extensions map[string]struct{}
+
+	// 用于记录backlinks, key为内容文件路径
+	files    map[string]string
+	pages    map[string]*content.Page
+	sections map[string]*content.Section
+	sources  map[string]*content.Backlink
+
+	wikis           *wikiIndex
+	orgIDs          map[string]*orgIDTarget
+	unresolvedClass string
 }
 
 func newContentLinkRewriter(ctx *core.Context, pages content.Pages, hidden content.Pages, sections content.Sections) *contentLinkRewriter {
@@ -52,6 +78,14 @@ func newContentLinkRewriter(ctx *core.Context, pages content.Pages, hidden conte
 		targets:    make(map[string]string),
 		outputs:    make(map[string]struct{}),
 		extensions: make(map[string]struct{}),
+		files:      make(map[string]string),
+		pages:      make(map[string]*content.Page),
+		sections:   make(map[string]*content.Section),
+		sources:    make(map[string]*content.Backlink),
+
+		wikis:           newWikiIndex(),
+		orgIDs:          make(map[string]*orgIDTarget),
+		unresolvedClass: defaultUnresolvedClass,
 	}
 	rewriter.addPages(pages)
 	rewriter.addPages(hidden)
@@ -65,6 +99,10 @@ func (r *contentLinkRewriter) addPages(pages content.Pages) {
 			continue
 		}
 		r.addTarget(page.File, page.Path)
+		r.pages[page.File.Path] = page
+		r.sources[page.File.Path] = content.NewPageBacklink(page)
+		r.wikis.add(page.Node, page.Path, page.Assets)
+		r.addOrgIDs(page.Node, page.Path)
 	}
 }
 
@@ -74,6 +112,10 @@ func (r *contentLinkRewriter) addSections(sections content.Sections) {
 			continue
 		}
 		r.addTarget(section.File, section.Path)
+		r.sections[section.File.Path] = section
+		r.sources[section.File.Path] = content.NewSectionBacklink(section)
+		r.wikis.add(section.Node, section.Path, section.Assets)
+		r.addOrgIDs(section.Node, section.Path)
 	}
 }
 
@@ -83,6 +125,7 @@ func (r *contentLinkRewriter) addTarget(file *content.File, outputPath string) {
 	}
 	if outputPath != "" {
 		r.outputs[outputPath] = struct{}{}
+		r.files[outputPath] = file.Path
 	}
 	if file.Ext != "" {
 		r.extensions[normalizeExtension(file.Ext)] = struct{}{}
@@ -126,6 +169,11 @@ func (r *contentLinkRewriter) rewriteHTML(node *content.Node, data string) strin
 			return buf.String()
 		case html.StartTagToken, html.SelfClosingTagToken:
 			token := tokenizer.Token()
+			if link, ok := tokenAttr(&token, parser.WikiLinkAttr); ok {
+				r.rewriteWikiLink(node, &token, link)
+				writeToken(&buf, token, tokenType == html.SelfClosingTagToken)
+				continue
+			}
 			if token.Data != "a" {
 				buf.Write(tokenizer.Raw())
 				continue
@@ -146,6 +194,15 @@ func (r *contentLinkRewriter) rewriteAnchorHref(node *content.Node, token *html.
 		if attr.Key != "href" {
 			continue
 		}
+		if id, ok := strings.CutPrefix(attr.Val, "id:"); ok {
+			if href, ok := r.resolveOrgID(node, id); ok {
+				token.Attr[i].Val = href
+				return true
+			}
+			return false
+		}
+		r.recordLink(node, attr.Val)
+
 		if href, ok := r.resolveHref(node, attr.Val); ok {
 			token.Attr[i].Val = href
 			return true
@@ -155,6 +212,46 @@ func (r *contentLinkRewriter) rewriteAnchorHref(node *content.Node, token *html.
 	return false
 }
 
+// recordLink 记录内容之间的链接, 支持内容文件路径和输出路径
+func (r *contentLinkRewriter) recordLink(node *content.Node, href string) {
+	if node == nil || node.File == nil || href == "" || strings.HasPrefix(href, "#") || hasURLScheme(href) {
+		return
+	}
+	u, err := url.Parse(href)
+	if err != nil || u.Path == "" {
+		return
+	}
+
+	source, ok := r.sources[node.File.Path]
+	if !ok {
+		return
+	}
+
+	target, ok := r.files[u.Path]
+	if !ok && !strings.HasPrefix(u.Path, "/") {
+		// 相对于内容文件或者输出路径的链接
+		target = normalizeContentPath(node, u.Path)
+		if _, ok := r.targets[target]; !ok {
+			base := &url.URL{Path: source.Path}
+			target = r.files[base.ResolveReference(&url.URL{Path: u.Path}).Path]
+		}
+	}
+	r.addBacklink(node, target)
+}
+
+// addBacklink 记录node到target内容文件的链接
+func (r *contentLinkRewriter) addBacklink(node *content.Node, target string) {
+	source, ok := r.sources[node.File.Path]
+	if !ok || target == "" || target == node.File.Path {
+		return
+	}
+	if page, ok := r.pages[target]; ok {
+		page.Backlinks = page.Backlinks.Add(source)
+	} else if section, ok := r.sections[target]; ok {
+		section.Backlinks = section.Backlinks.Add(source)
+	}
+}
+
 func writeToken(buf *bytes.Buffer, token html.Token, selfClosing bool) {
 	buf.WriteByte('<')
 	buf.WriteString(token.Data)
@@ -202,6 +299,7 @@ func (r *contentLinkRewriter) resolveHref(node *content.Node, href string) (stri
 				source = node.File.Path
 			}
 			r.ctx.Logger.Warnf("content link not found: page=%s href=%s target=%s", source, href, targetPath)
+			r.ctx.Reporter.Warn(&core.Error{Op: "resolve link", Err: fmt.Errorf("content link not found: href=%s target=%s", href, targetPath), Path: source})
 		}
 		return "", false
 	}
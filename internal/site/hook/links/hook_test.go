@@ -59,6 +59,10 @@ func (s testContentStore) Sections(string) content.Sections {
 	return s.sections
 }
 
+func (s testContentStore) Taxonomies(string) content.Taxonomies {
+	return nil
+}
+
 func testLinkContext() (*core.Context, *bytes.Buffer) {
 	var buf bytes.Buffer
 	logger := logrus.New()
@@ -203,3 +207,95 @@ func TestLinksHookRewritesSectionContent(t *testing.T) {
 	assert.Contains(t, source.Content, `<a href="/posts/hello/">hello</a>`)
 	assert.Contains(t, source.Summary, `<a href="/posts/hello/">hello</a>`)
 }
+
+func TestLinksHookRecordsBacklinks(t *testing.T) {
+	target := testLinkPage("posts/hello.md", "posts", "/posts/hello/", `<a href="/posts/">posts</a><a href="#self">self</a>`)
+	section := testLinkSection("posts/_index.md", "posts", "/posts/", `<a href="hello.md">hello</a>`)
+	source := testLinkPage("posts/source.md", "posts", "/posts/source/", `<a href="hello.md">a</a><a href="/posts/hello/">b</a><a href="https://example.com/posts/hello/">c</a><a href="../hello/">d</a>`)
+
+	h := &LinksHook{}
+	h.HandleContent(testContentStore{pages: content.Pages{source, target}, sections: content.Sections{section}}, "zh")
+
+	assert.Len(t, target.Backlinks, 2)
+	assert.Equal(t, "posts/source.md", target.Backlinks[0].File.Path)
+	assert.Equal(t, "page", target.Backlinks[0].Kind)
+	assert.Equal(t, "/posts/source/", target.Backlinks[0].Path)
+	assert.Equal(t, "posts/_index.md", target.Backlinks[1].File.Path)
+	assert.Equal(t, "section", target.Backlinks[1].Kind)
+
+	assert.Len(t, section.Backlinks, 1)
+	assert.Equal(t, "posts/hello.md", section.Backlinks[0].File.Path)
+	assert.Empty(t, source.Backlinks)
+}
+
+func TestLinksHookResolvesWikiLinks(t *testing.T) {
+	target := testLinkPage("posts/hello-world.md", "posts", "/posts/hello/", "")
+	target.Title = "Hello World"
+	target.Slug = "hello"
+	target.File.BaseName = "hello-world"
+	target.Toc = []*content.Heading{{Id: "getting-started", Title: "Getting Started"}}
+	target.Assets = content.Assets{{File: &content.File{Name: "Cat.png"}, Path: "/posts/hello/cat.png"}}
+
+	source := testLinkPage("posts/source.md", "posts", "/posts/source/", `<a class="wikilink" href="" data-wikilink="hello world">a</a>`+
+		`<a class="wikilink" href="" data-wikilink="HELLO">b</a>`+
+		`<a class="wikilink" href="" data-wikilink="hello-world#getting started">c</a>`+
+		`<img class="wikilink" src="" data-wikilink="cat.png" alt="cat.png">`)
+
+	h := &LinksHook{}
+	h.HandleContent(testContentStore{pages: content.Pages{source, target}}, "zh")
+
+	assert.Equal(t, `<a class="wikilink" href="/posts/hello/">a</a>`+
+		`<a class="wikilink" href="/posts/hello/">b</a>`+
+		`<a class="wikilink" href="/posts/hello/#getting-started">c</a>`+
+		`<img class="wikilink" src="/posts/hello/cat.png" alt="cat.png">`, source.Content)
+	assert.Len(t, target.Backlinks, 1)
+	assert.Equal(t, "posts/source.md", target.Backlinks[0].File.Path)
+}
+
+func TestLinksHookWarnsUnresolvedWikiLinks(t *testing.T) {
+	ctx, buf := testLinkContext()
+	source := testLinkPage("posts/source.md", "posts", "/posts/source/", `<a class="wikilink" href="" data-wikilink="Missing">missing</a><img class="wikilink" src="" data-wikilink="dog.png" alt="dog.png">`)
+
+	h := &LinksHook{ctx: ctx, opt: Option{UnresolvedClass: "broken"}}
+	h.HandleContent(testContentStore{pages: content.Pages{source}}, "zh")
+
+	assert.Equal(t, `<a class="wikilink broken" href="Missing">missing</a><img class="wikilink broken" src="dog.png" alt="dog.png">`, source.Content)
+	assert.Contains(t, buf.String(), "wikilink not found")
+	assert.Contains(t, buf.String(), "target=Missing")
+}
+
+func TestLinksHookResolvesOrgIDs(t *testing.T) {
+	ctx, buf := testLinkContext()
+	target := testLinkPage("notes/roam.org", "notes", "/notes/roam/", "")
+	target.IDs = map[string]string{"8F2C-FILE": "", "8F2C-HEADING": "first"}
+	source := testLinkPage("notes/source.org", "notes", "/notes/source/", `<a href="id:8F2C-FILE">a</a><a href="id:8F2C-HEADING">b</a><a href="id:8F2C-MISSING">c</a>`)
+
+	h := &LinksHook{ctx: ctx}
+	h.HandleContent(testContentStore{pages: content.Pages{source, target}}, "zh")
+
+	assert.Equal(t, `<a href="/notes/roam/">a</a><a href="/notes/roam/#first">b</a><a href="id:8F2C-MISSING">c</a>`, source.Content)
+	assert.Len(t, target.Backlinks, 1)
+	assert.Contains(t, buf.String(), "org id not found")
+	assert.Contains(t, buf.String(), "id=8F2C-MISSING")
+}
+
+func TestLinksHookReportsDuplicateOrgIDs(t *testing.T) {
+	ctx, buf := testLinkContext()
+	ctx.Reporter = &core.Reporter{}
+	first := testLinkPage("notes/a.org", "notes", "/notes/a/", "")
+	first.IDs = map[string]string{"8F2C-FILE": ""}
+	second := testLinkPage("notes/b.org", "notes", "/notes/b/", "")
+	second.IDs = map[string]string{"8F2C-FILE": ""}
+	source := testLinkPage("notes/source.org", "notes", "/notes/source/", `<a href="id:8F2C-FILE">a</a>`)
+
+	h := &LinksHook{ctx: ctx}
+	h.HandleContent(testContentStore{pages: content.Pages{source, second, first}}, "zh")
+
+	assert.Equal(t, `<a href="/notes/a/">a</a>`, source.Content)
+	assert.Contains(t, buf.String(), "duplicate org id 8F2C-FILE: notes/a.org and notes/b.org")
+
+	errs := ctx.Reporter.Errors()
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, "notes/b.org", errs[0].Path)
+	}
+}
@@ -0,0 +1,67 @@
+package links
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/content"
+)
+
+// orgIDTarget org-roam中:ID:属性所在的内容和标题锚点
+type orgIDTarget struct {
+	node   *content.Node
+	path   string
+	anchor string
+}
+
+func (r *contentLinkRewriter) addOrgIDs(node *content.Node, outputPath string) {
+	if node == nil || outputPath == "" {
+		return
+	}
+	for id, anchor := range node.IDs {
+		target := &orgIDTarget{node: node, path: outputPath, anchor: anchor}
+		if exist, ok := r.orgIDs[id]; ok {
+			// 重复的ID使用文件路径排序靠前的内容, 避免结果依赖于内容的顺序
+			first, second := exist, target
+			if second.node.File.Path < first.node.File.Path {
+				first, second = second, first
+			}
+			r.orgIDs[id] = first
+			if r.ctx != nil {
+				err := fmt.Errorf("duplicate org id %s: %s and %s", id, first.node.File.Path, second.node.File.Path)
+				if r.ctx.Logger != nil {
+					r.ctx.Logger.Errorf("resolve org id: %s", err)
+				}
+				r.ctx.Reporter.Error(&core.Error{Op: "resolve org id", Err: err, Path: second.node.File.Path})
+			}
+			continue
+		}
+		r.orgIDs[id] = target
+	}
+}
+
+// resolveOrgID 把[[id:xxx]]解析为目标内容的地址和标题锚点
+func (r *contentLinkRewriter) resolveOrgID(node *content.Node, id string) (string, bool) {
+	id = strings.TrimSpace(id)
+
+	target, ok := r.orgIDs[id]
+	if !ok {
+		if r.ctx != nil && r.ctx.Logger != nil {
+			source := ""
+			if node != nil && node.File != nil {
+				source = node.File.Path
+			}
+			r.ctx.Logger.Warnf("org id not found: page=%s id=%s", source, id)
+			r.ctx.Reporter.Warn(&core.Error{Op: "resolve org id", Err: fmt.Errorf("org id not found: %s", id), Path: source})
+		}
+		return "", false
+	}
+	if node != nil && node.File != nil && target.node.File != nil {
+		r.addBacklink(node, target.node.File.Path)
+	}
+	if target.anchor != "" {
+		return target.path + "#" + target.anchor, true
+	}
+	return target.path, true
+}
@@ -0,0 +1,188 @@
+package links
+
+import (
+	"fmt"
+	stdhtml "html"
+	stdpath "path"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/content"
+	"github.com/honmaple/snow/internal/site/content/parser"
+)
+
+const defaultUnresolvedClass = "wikilink-unresolved"
+
+type (
+	wikiTarget struct {
+		node *content.Node
+		path string
+	}
+	// wikiIndex 按照标题, slug和文件名查找wiki链接的目标, 不区分大小写
+	wikiIndex struct {
+		titles    map[string]*wikiTarget
+		slugs     map[string]*wikiTarget
+		basenames map[string]*wikiTarget
+		assets    map[string]string
+	}
+)
+
+func newWikiIndex() *wikiIndex {
+	return &wikiIndex{
+		titles:    make(map[string]*wikiTarget),
+		slugs:     make(map[string]*wikiTarget),
+		basenames: make(map[string]*wikiTarget),
+		assets:    make(map[string]string),
+	}
+}
+
+func wikiKey(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+func addWikiKey(m map[string]*wikiTarget, key string, target *wikiTarget) {
+	if key = wikiKey(key); key == "" {
+		return
+	}
+	// 重名时使用第一个
+	if _, ok := m[key]; !ok {
+		m[key] = target
+	}
+}
+
+func (idx *wikiIndex) add(node *content.Node, outputPath string, assets content.Assets) {
+	if node == nil || node.File == nil || outputPath == "" {
+		return
+	}
+	target := &wikiTarget{node: node, path: outputPath}
+	addWikiKey(idx.titles, node.Title, target)
+	addWikiKey(idx.slugs, node.Slug, target)
+
+	basename := node.File.BaseName
+	if basename == "index" || basename == "_index" {
+		basename = stdpath.Base(node.File.Dir)
+	}
+	addWikiKey(idx.basenames, basename, target)
+	addWikiKey(idx.basenames, strings.TrimSuffix(node.File.Path, "."+node.File.Ext), target)
+
+	for _, asset := range assets {
+		if asset == nil || asset.File == nil {
+			continue
+		}
+		if key := wikiKey(asset.File.Name); key != "" && idx.assets[key] == "" {
+			idx.assets[key] = asset.Path
+		}
+	}
+}
+
+func (idx *wikiIndex) find(name string) (*wikiTarget, bool) {
+	key := wikiKey(name)
+	for _, m := range []map[string]*wikiTarget{idx.titles, idx.slugs, idx.basenames} {
+		if target, ok := m[key]; ok {
+			return target, true
+		}
+	}
+	return nil, false
+}
+
+// headingID 按照标题查找锚点, 找不到时使用原始值
+func headingID(headings []*parser.Heading, name string) string {
+	var find func([]*parser.Heading) string
+	find = func(headings []*parser.Heading) string {
+		for _, heading := range headings {
+			if strings.EqualFold(stdhtml.UnescapeString(heading.Title), name) || heading.Id == name {
+				return heading.Id
+			}
+			if id := find(heading.Children); id != "" {
+				return id
+			}
+		}
+		return ""
+	}
+	if id := find(headings); id != "" {
+		return id
+	}
+	return name
+}
+
+func tokenAttr(token *html.Token, key string) (string, bool) {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+func setTokenAttr(token *html.Token, key string, value string) {
+	for i, attr := range token.Attr {
+		if attr.Key == key {
+			token.Attr[i].Val = value
+			return
+		}
+	}
+	token.Attr = append(token.Attr, html.Attribute{Key: key, Val: value})
+}
+
+// resolveWikiLink 返回wiki链接对应的地址和目标内容
+func (r *contentLinkRewriter) resolveWikiLink(node *content.Node, link string, embed bool) (string, *content.Node, bool) {
+	if embed {
+		path, ok := r.wikis.assets[wikiKey(stdpath.Base(link))]
+		return path, nil, ok
+	}
+
+	name, fragment, _ := strings.Cut(link, "#")
+	if name == "" {
+		if node == nil || fragment == "" {
+			return "", nil, false
+		}
+		return "#" + headingID(node.Toc, fragment), node, true
+	}
+
+	target, ok := r.wikis.find(name)
+	if !ok {
+		return "", nil, false
+	}
+	if fragment != "" {
+		return target.path + "#" + headingID(target.node.Toc, fragment), target.node, true
+	}
+	return target.path, target.node, true
+}
+
+// rewriteWikiLink 把parser生成的wiki链接占位元素替换为实际链接
+func (r *contentLinkRewriter) rewriteWikiLink(node *content.Node, token *html.Token, link string) {
+	token.Attr = slices.DeleteFunc(token.Attr, func(attr html.Attribute) bool {
+		return attr.Key == parser.WikiLinkAttr
+	})
+
+	attr, embed := "href", token.Data == "img"
+	if embed {
+		attr = "src"
+	}
+
+	path, target, ok := r.resolveWikiLink(node, link, embed)
+	if ok {
+		setTokenAttr(token, attr, path)
+		if target != nil && node != nil && node.File != nil && target.File != nil {
+			r.addBacklink(node, target.File.Path)
+		}
+		return
+	}
+
+	// 未找到时保留原始链接, 嵌入的图片可能是相对于当前内容的文件
+	setTokenAttr(token, attr, link)
+	class, _ := tokenAttr(token, "class")
+	setTokenAttr(token, "class", strings.TrimSpace(class+" "+r.unresolvedClass))
+
+	if r.ctx != nil && r.ctx.Logger != nil {
+		source := ""
+		if node != nil && node.File != nil {
+			source = node.File.Path
+		}
+		r.ctx.Logger.Warnf("wikilink not found: page=%s target=%s", source, link)
+		r.ctx.Reporter.Warn(&core.Error{Op: "resolve wikilink", Err: fmt.Errorf("wikilink not found: %s", link), Path: source})
+	}
+}
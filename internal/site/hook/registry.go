@@ -57,6 +57,12 @@ func (r *Registry) HandleTemplateSet(set template.TemplateSet) (template.Templat
 	return set, nil
 }
 
+func (r *Registry) HandleDependencies(deps DependencyRecorder) {
+	for _, hook := range r.hooks {
+		hook.HandleDependencies(deps)
+	}
+}
+
 func (r *Registry) HandlePage(result *content.Page) *content.Page {
 	for _, hook := range r.hooks {
 		result = hook.HandlePage(result)
@@ -0,0 +1,195 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	stdpath "path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/content"
+	"github.com/honmaple/snow/internal/site/hook"
+	"github.com/honmaple/snow/internal/utils"
+	"github.com/spf13/cast"
+)
+
+var defaultFields = []string{"title", "permalink", "summary", "section", "taxonomies", "tokens"}
+
+type (
+	Option struct {
+		Path      string   `json:"path"`
+		Fields    []string `json:"fields"`
+		ShardSize int      `json:"shard_size"`
+		MaxTokens int      `json:"max_tokens"`
+	}
+	SearchHook struct {
+		hook.HookImpl
+		ctx    *core.Context
+		opt    Option
+		mu     sync.Mutex
+		stores map[string]hook.ContentStore
+	}
+	// Manifest 每个语言的索引入口, 记录所有分片的路径
+	Manifest struct {
+		Lang   string   `json:"lang"`
+		Total  int      `json:"total"`
+		Fields []string `json:"fields"`
+		Shards []string `json:"shards"`
+	}
+)
+
+// isEnabled 页面设置search: false或者所属section设置search: false时不加入索引
+func isEnabled(page *content.Page) bool {
+	if page.FrontMatter.IsSet("search") {
+		return cast.ToBool(page.FrontMatter.Get("search"))
+	}
+	for section := page.Section; section != nil; section = section.Parent {
+		if section.FrontMatter.IsSet("search") {
+			return cast.ToBool(section.FrontMatter.Get("search"))
+		}
+	}
+	return true
+}
+
+func (h *SearchHook) newDocument(page *content.Page, taxonomies content.Taxonomies) map[string]any {
+	doc := make(map[string]any)
+	for _, field := range h.opt.Fields {
+		switch field {
+		case "title":
+			doc["title"] = page.Title
+		case "permalink":
+			doc["permalink"] = page.Permalink
+		case "summary":
+			doc["summary"] = utils.PlainText(page.Summary)
+		case "section":
+			if page.Section != nil {
+				doc["section"] = page.Section.Title
+			}
+		case "taxonomies":
+			terms := make(map[string][]string)
+			for _, taxonomy := range taxonomies {
+				if values := page.FrontMatter.GetStringSlice(taxonomy.Name); len(values) > 0 {
+					terms[taxonomy.Name] = values
+				}
+			}
+			doc["taxonomies"] = terms
+		case "tokens":
+			tokens := make([]string, 0)
+			seen := make(map[string]bool)
+			for _, token := range Tokenize(page.Title + " " + utils.PlainText(page.Content)) {
+				if seen[token] {
+					continue
+				}
+				seen[token] = true
+				tokens = append(tokens, token)
+				if h.opt.MaxTokens > 0 && len(tokens) >= h.opt.MaxTokens {
+					break
+				}
+			}
+			doc["tokens"] = tokens
+		default:
+			// 其它字段从front matter中读取
+			if v := page.FrontMatter.Get(field); v != nil {
+				doc[field] = v
+			}
+		}
+	}
+	return doc
+}
+
+func (h *SearchHook) write(ctx context.Context, writer core.Writer, path string, v any) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return &core.Error{Op: "write search index", Err: err, Path: path}
+	}
+	if err := writer.WriteFile(ctx, path, bytes.NewReader(buf)); err != nil {
+		return &core.Error{Op: "write search index", Err: err, Path: path}
+	}
+	return nil
+}
+
+func (h *SearchHook) build(ctx context.Context, writer core.Writer, store hook.ContentStore, lang string) error {
+	taxonomies := store.Taxonomies(lang)
+
+	docs := make([]map[string]any, 0)
+	for _, page := range store.Pages(lang) {
+		if !isEnabled(page) {
+			continue
+		}
+		doc := h.newDocument(page, taxonomies)
+		doc["id"] = len(docs)
+		docs = append(docs, doc)
+	}
+
+	root := "/" + h.opt.Path
+	if lang != h.ctx.GetDefaultLanguage() {
+		root = stdpath.Join("/", lang, h.opt.Path)
+	}
+	manifest := &Manifest{
+		Lang:   lang,
+		Total:  len(docs),
+		Fields: h.opt.Fields,
+		Shards: make([]string, 0),
+	}
+	for i, chunk := range slices.Collect(slices.Chunk(docs, h.opt.ShardSize)) {
+		file := stdpath.Join(root, fmt.Sprintf("index-%d.json", i+1))
+		if err := h.write(ctx, writer, file, chunk); err != nil {
+			return err
+		}
+		manifest.Shards = append(manifest.Shards, file)
+	}
+	h.ctx.Logger.Debugf("write %s search index with %d pages in %d shards", lang, len(docs), len(manifest.Shards))
+	return h.write(ctx, writer, stdpath.Join(root, "index.json"), manifest)
+}
+
+func (h *SearchHook) AfterBuild(ctx context.Context, writer core.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, lang := range h.ctx.GetAllLanguages() {
+		store, ok := h.stores[lang]
+		if !ok {
+			continue
+		}
+		if err := h.build(ctx, writer, store, lang); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *SearchHook) HandleContent(store hook.ContentStore, lang string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stores[lang] = store
+}
+
+func New(ctx *core.Context) (hook.Hook, error) {
+	var opt Option
+	if err := hook.Unmarshal(ctx.Config.Get("hooks.search.option"), &opt); err != nil {
+		return nil, err
+	}
+	opt.Path = strings.Trim(opt.Path, "/")
+	if opt.Path == "" {
+		opt.Path = "search"
+	}
+	if len(opt.Fields) == 0 {
+		opt.Fields = defaultFields
+	}
+	if opt.ShardSize <= 0 {
+		opt.ShardSize = 500
+	}
+	return &SearchHook{
+		ctx:    ctx,
+		opt:    opt,
+		stores: make(map[string]hook.ContentStore),
+	}, nil
+}
+
+func init() {
+	hook.Register("search", New)
+}
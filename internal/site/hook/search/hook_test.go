@@ -0,0 +1,132 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/content"
+	"github.com/honmaple/snow/internal/writer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testContentStore struct {
+	pages      map[string]content.Pages
+	taxonomies content.Taxonomies
+}
+
+func (s testContentStore) Pages(lang string) content.Pages {
+	return s.pages[lang]
+}
+
+func (s testContentStore) HiddenPages(string) content.Pages {
+	return nil
+}
+
+func (s testContentStore) Sections(string) content.Sections {
+	return nil
+}
+
+func (s testContentStore) Taxonomies(string) content.Taxonomies {
+	return s.taxonomies
+}
+
+func readJSON(t *testing.T, w *writer.MemoryWriter, file string, v any) {
+	t.Helper()
+
+	f, err := w.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+
+	b, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(b, v))
+}
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"hello", "go", "1", "24"}, Tokenize("Hello, Go 1.24!"))
+	assert.Equal(t, []string{"静态", "态网", "网站", "snow", "生成"}, Tokenize("静态网站 snow 生成"))
+	assert.Equal(t, []string{"中"}, Tokenize("中"))
+}
+
+func TestSearchHookWritesShards(t *testing.T) {
+	conf := core.DefaultConfig()
+	conf.Set("languages.zh", map[string]any{})
+	conf.Set("hooks.search.option", map[string]any{
+		"shard_size": 1,
+		"max_tokens": 3,
+	})
+	ctx, err := core.NewContext(conf)
+	require.NoError(t, err)
+
+	h, err := New(ctx)
+	require.NoError(t, err)
+
+	section := &content.Section{
+		Node: &content.Node{Title: "Docs", FrontMatter: content.NewFrontMatter(nil)},
+	}
+	private := &content.Section{
+		Node: &content.Node{Title: "Private", FrontMatter: content.NewFrontMatter(map[string]any{"search": false})},
+	}
+	store := testContentStore{
+		pages: map[string]content.Pages{
+			"en": {
+				{
+					Node: &content.Node{
+						Title:       "Hello",
+						Summary:     "<p>Say <b>hello</b></p>",
+						Content:     "<p>hello world again and again</p><script>var x</script>",
+						FrontMatter: content.NewFrontMatter(map[string]any{"tags": []string{"go"}}),
+					},
+					Permalink: "http://127.0.0.1:8000/hello/",
+					Section:   section,
+				},
+				{
+					Node:    &content.Node{Title: "Excluded", FrontMatter: content.NewFrontMatter(map[string]any{"search": false})},
+					Section: section,
+				},
+				{
+					Node:    &content.Node{Title: "Secret", FrontMatter: content.NewFrontMatter(nil)},
+					Section: private,
+				},
+				{
+					Node:      &content.Node{Title: "World", FrontMatter: content.NewFrontMatter(nil)},
+					Permalink: "http://127.0.0.1:8000/world/",
+				},
+			},
+			"zh": {
+				{
+					Node:      &content.Node{Title: "你好世界", FrontMatter: content.NewFrontMatter(nil)},
+					Permalink: "http://127.0.0.1:8000/zh/hello/",
+				},
+			},
+		},
+		taxonomies: content.Taxonomies{{Name: "tags"}},
+	}
+	h.HandleContent(store, "en")
+	h.HandleContent(store, "zh")
+
+	w := writer.NewMemoryWriter()
+	require.NoError(t, h.AfterBuild(context.Background(), w))
+
+	var manifest Manifest
+	readJSON(t, w, "/search/index.json", &manifest)
+	assert.Equal(t, 2, manifest.Total)
+	assert.Equal(t, []string{"/search/index-1.json", "/search/index-2.json"}, manifest.Shards)
+
+	var docs []map[string]any
+	readJSON(t, w, "/search/index-1.json", &docs)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "Hello", docs[0]["title"])
+	assert.Equal(t, "Say hello", docs[0]["summary"])
+	assert.Equal(t, "Docs", docs[0]["section"])
+	assert.Equal(t, map[string]any{"tags": []any{"go"}}, docs[0]["taxonomies"])
+	assert.Equal(t, []any{"hello", "world", "again"}, docs[0]["tokens"])
+
+	readJSON(t, w, "/zh/search/index-1.json", &docs)
+	require.Len(t, docs, 1)
+	assert.Equal(t, []any{"你好", "好世", "世界"}, docs[0]["tokens"])
+}
@@ -9,8 +9,9 @@ import (
 
 type ShortcodeHook struct {
 	hook.HookImpl
-	ctx *core.Context
-	set *ShortcodeSet
+	ctx  *core.Context
+	set  *ShortcodeSet
+	deps hook.DependencyRecorder
 }
 
 func (h *ShortcodeHook) HandlePage(page *content.Page) *content.Page {
@@ -40,7 +41,7 @@ func (h *ShortcodeHook) HandleSection(section *content.Section) *content.Section
 }
 
 func (h *ShortcodeHook) HandleTemplateSet(set template.TemplateSet) (template.TemplateSet, error) {
-	sc, err := NewShortcodeSet(h.ctx, set)
+	sc, err := NewShortcodeSet(h.ctx, set, h.deps)
 	if err != nil {
 		return nil, err
 	}
@@ -48,6 +49,10 @@ func (h *ShortcodeHook) HandleTemplateSet(set template.TemplateSet) (template.Te
 	return set, nil
 }
 
+func (h *ShortcodeHook) HandleDependencies(deps hook.DependencyRecorder) {
+	h.deps = deps
+}
+
 func New(ctx *core.Context) (hook.Hook, error) {
 	h := &ShortcodeHook{
 		ctx: ctx,
@@ -0,0 +1,333 @@
+package shortcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	stdpath "path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/content"
+	contentparser "github.com/honmaple/snow/internal/site/content/parser"
+	"github.com/honmaple/snow/internal/site/hook"
+	"github.com/spf13/cast"
+)
+
+const includeName = "include"
+
+// 代码中的区域标记, 例如: // snippet:start foo
+var INCLUDE_REGION = regexp.MustCompile(`snippet:(start|end)\s+(\S+)`)
+
+// includeShortcode 内置的shortcode, 读取文件中的代码并高亮
+//
+//	<shortcode include file="/examples/main.go" lines="3-5" />
+//	<shortcode include file="main.go" region="foo" />
+//	<shortcode include file="main.go" symbol="Server.Run" />
+type includeShortcode struct {
+	ctx *core.Context
+	// 以/开头的文件从项目根目录读取, 否则从当前内容所在的目录读取
+	rootFS    fs.FS
+	contentFS fs.FS
+	deps      hook.DependencyRecorder
+}
+
+// includeLines 选中的代码和第一行的行号
+type includeLines struct {
+	lines []string
+	start int
+}
+
+func splitLines(data []byte) []string {
+	text := strings.TrimSuffix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// dedentLines 删除公共的缩进
+func dedentLines(lines []string) []string {
+	prefix, found := "", false
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if !found {
+			prefix, found = indent, true
+			continue
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if prefix == "" {
+		return lines
+	}
+	results := make([]string, len(lines))
+	for i, line := range lines {
+		results[i] = strings.TrimPrefix(line, prefix)
+	}
+	return results
+}
+
+// selectLines 选择指定的行, 例如3-5, 3-, -5, 7
+func selectLines(lines []string, value string) (*includeLines, error) {
+	start, end := 1, len(lines)
+
+	from, to, found := strings.Cut(strings.TrimSpace(value), "-")
+	if !found {
+		to = from
+	}
+	var err error
+	if from = strings.TrimSpace(from); from != "" {
+		if start, err = strconv.Atoi(from); err != nil {
+			return nil, fmt.Errorf("invalid lines %q", value)
+		}
+	}
+	if to = strings.TrimSpace(to); to != "" {
+		if end, err = strconv.Atoi(to); err != nil {
+			return nil, fmt.Errorf("invalid lines %q", value)
+		}
+	}
+	if start < 1 || start > end || start > len(lines) {
+		return nil, fmt.Errorf("lines %q out of range, file has %d lines", value, len(lines))
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	return &includeLines{lines: lines[start-1 : end], start: start}, nil
+}
+
+// selectRegion 选择snippet:start和snippet:end之间的行, 其它的区域标记会被删除
+func selectRegion(lines []string, name string) (*includeLines, error) {
+	var (
+		result *includeLines
+		closed bool
+	)
+	for i, line := range lines {
+		match := INCLUDE_REGION.FindStringSubmatch(line)
+		if match == nil {
+			if result != nil && !closed {
+				result.lines = append(result.lines, line)
+			}
+			continue
+		}
+		if match[2] != name {
+			continue
+		}
+		if match[1] == "start" && result == nil {
+			result = &includeLines{lines: make([]string, 0), start: i + 2}
+		} else if match[1] == "end" && result != nil {
+			closed = true
+		}
+	}
+	if result == nil {
+		return nil, fmt.Errorf("region %q not found", name)
+	}
+	if !closed {
+		return nil, fmt.Errorf("region %q is not closed", name)
+	}
+	return result, nil
+}
+
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// selectSymbol 选择go文件中的函数, 方法(Type.Method), 类型, 变量或者常量, 包括注释
+func selectSymbol(lines []string, data []byte, name string) (*includeLines, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", data, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var node ast.Node
+	var doc *ast.CommentGroup
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			fn := d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				fn = receiverName(d.Recv.List[0].Type) + "." + fn
+			}
+			if fn == name {
+				node, doc = d, d.Doc
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				names := make([]string, 0)
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					names = append(names, s.Name.Name)
+				case *ast.ValueSpec:
+					for _, ident := range s.Names {
+						names = append(names, ident.Name)
+					}
+				}
+				for _, n := range names {
+					if n != name {
+						continue
+					}
+					if d.Lparen.IsValid() {
+						node, doc = spec, specDoc(spec)
+					} else {
+						node, doc = d, d.Doc
+					}
+				}
+			}
+		}
+		if node != nil {
+			break
+		}
+	}
+	if node == nil {
+		return nil, fmt.Errorf("symbol %q not found", name)
+	}
+
+	pos := node.Pos()
+	if doc != nil {
+		pos = doc.Pos()
+	}
+	start, end := fset.Position(pos).Line, fset.Position(node.End()).Line
+	return &includeLines{lines: lines[start-1 : end], start: start}, nil
+}
+
+func specDoc(spec ast.Spec) *ast.CommentGroup {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		return s.Doc
+	case *ast.ValueSpec:
+		return s.Doc
+	}
+	return nil
+}
+
+func (s *includeShortcode) Name() string {
+	return includeName
+}
+
+func (s *includeShortcode) readFile(node *content.Node, file string) ([]byte, error) {
+	fsys, name := s.contentFS, stdpath.Join(node.File.Dir, file)
+	dep := name
+	if strings.HasPrefix(file, "/") {
+		fsys, name = s.rootFS, strings.TrimPrefix(stdpath.Clean(file), "/")
+		dep = "/" + name
+	}
+	if fsys == nil || !fs.ValidPath(name) {
+		return nil, fmt.Errorf("invalid include file %q", file)
+	}
+	// 记录引用的文件, 开发服务器中文件修改后重新渲染, 项目根目录下的文件使用/开头
+	if s.deps != nil {
+		s.deps.RecordFile(node.File.Path, dep)
+	}
+	return fs.ReadFile(fsys, name)
+}
+
+func (s *includeShortcode) include(node *content.Node, params Params) (string, error) {
+	file := cast.ToString(params.Get("file"))
+	if file == "" {
+		return "", errors.New("include file is required")
+	}
+	data, err := s.readFile(node, file)
+	if err != nil {
+		return "", err
+	}
+	lines := splitLines(data)
+
+	selected := &includeLines{lines: lines, start: 1}
+	switch {
+	case params.Get("symbol") != nil:
+		if stdpath.Ext(file) != ".go" {
+			return "", fmt.Errorf("symbol is only supported for go files: %s", file)
+		}
+		selected, err = selectSymbol(lines, data, cast.ToString(params.Get("symbol")))
+	case params.Get("region") != nil:
+		selected, err = selectRegion(lines, cast.ToString(params.Get("region")))
+	case params.Get("lines") != nil:
+		selected, err = selectLines(lines, cast.ToString(params.Get("lines")))
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", file, err)
+	}
+	if selected.start > 1 || len(selected.lines) < len(lines) {
+		selected.lines = dedentLines(selected.lines)
+	}
+
+	attrs := make(map[string]string)
+	for _, key := range []string{"hl_lines", "linenostart", "title", "linenos", "highlight"} {
+		if value := params.Get(key); value != nil {
+			attrs[key] = cast.ToString(value)
+		}
+	}
+	codeAttrs := contentparser.NewCodeAttributes(attrs)
+	if codeAttrs == nil {
+		codeAttrs = &contentparser.CodeAttributes{}
+	}
+	// 行号默认从选中的第一行开始
+	if codeAttrs.LineNoStart == 0 {
+		codeAttrs.LineNoStart = selected.start
+	}
+
+	lang := cast.ToString(params.Get("lang"))
+	if lang == "" {
+		lang = strings.TrimPrefix(stdpath.Ext(file), ".")
+	}
+
+	var b bytes.Buffer
+	for _, line := range selected.lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	opt := contentparser.NewMarkupOption(s.ctx.For(node.Lang), "_default")
+	return contentparser.HighlightCode(opt, lang, b.String(), codeAttrs), nil
+}
+
+func (s *includeShortcode) Execute(vars map[string]any) (string, error) {
+	var node *content.Node
+	switch v := vars["page"].(type) {
+	case *content.Page:
+		node = v.Node
+	}
+	if section, ok := vars["section"].(*content.Section); ok && node == nil {
+		node = section.Node
+	}
+	if node == nil || node.File == nil {
+		return "", errors.New("include can only be used in page or section content")
+	}
+
+	params, _ := vars["params"].(Params)
+	result, err := s.include(node, params)
+	if err != nil {
+		// 引用的文件或者区域不存在时构建失败, 避免发布内容错误的页面
+		s.ctx.Reporter.Fatal(&core.Error{Op: "include code", Err: err, Path: node.File.Path})
+		return "", err
+	}
+	return result, nil
+}
+
+func newIncludeShortcode(ctx *core.Context, deps hook.DependencyRecorder) *includeShortcode {
+	s := &includeShortcode{ctx: ctx, rootFS: ctx.FS, deps: deps}
+	if contentFS, err := ctx.GetFS(core.MountContent, false, false); err == nil {
+		s.contentFS = contentFS
+	}
+	return s
+}
@@ -0,0 +1,147 @@
+package shortcode
+
+import (
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/content"
+	"github.com/honmaple/snow/internal/site/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const includeGoFile = `package main
+
+import "fmt"
+
+// Server 服务
+type Server struct {
+	Addr string
+}
+
+// Run 启动服务
+func (s *Server) Run() error {
+	// snippet:start listen
+	if s.Addr == "" {
+		// snippet:start default
+		s.Addr = ":8000"
+		// snippet:end default
+	}
+	// snippet:end listen
+	return nil
+}
+
+func main() {
+	fmt.Println("hello")
+}
+`
+
+type testRecorder map[string][]string
+
+func (r testRecorder) RecordFile(path string, file string) {
+	r[path] = append(r[path], file)
+}
+
+func testIncludeSet(t *testing.T) (*ShortcodeSet, *core.Context) {
+	t.Helper()
+
+	conf := core.DefaultConfig()
+	conf.Set("markups._default.style", "none")
+	ctx, err := core.NewContext(conf)
+	require.NoError(t, err)
+
+	set := &ShortcodeSet{
+		ctx: ctx,
+		tpls: map[string]template.Template{
+			includeName: &includeShortcode{
+				ctx: ctx,
+				rootFS: fstest.MapFS{
+					"examples/main.go": &fstest.MapFile{Data: []byte(includeGoFile)},
+				},
+				contentFS: fstest.MapFS{
+					"posts/hello/code.py": &fstest.MapFile{Data: []byte("a = 1\nb = 2\n")},
+				},
+			},
+		},
+	}
+	return set, ctx
+}
+
+func includePage() *content.Page {
+	return &content.Page{
+		Node: &content.Node{
+			File: &content.File{Path: "posts/hello/index.md", Dir: "posts/hello"},
+		},
+	}
+}
+
+func TestIncludeShortcode(t *testing.T) {
+	set, ctx := testIncludeSet(t)
+	deps := make(testRecorder)
+	set.tpls[includeName].(*includeShortcode).deps = deps
+
+	page := includePage()
+	render := func(s string) string {
+		return set.Render("posts/hello/index.md", s, map[string]any{"page": page})
+	}
+
+	assert.Equal(t, "<pre><code class=\"language-py\">a = 1\nb = 2\n</code></pre>", render(`<shortcode include file="code.py" />`))
+	assert.Equal(t, "<pre><code class=\"language-go\">import &#34;fmt&#34;\n</code></pre>", render(`<shortcode include file="/examples/main.go" lines="3" />`))
+
+	result := render(`<shortcode include file="/examples/main.go" region="listen" />`)
+	assert.Equal(t, "<pre><code class=\"language-go\">if s.Addr == &#34;&#34; {\n\ts.Addr = &#34;:8000&#34;\n}\n</code></pre>", result)
+
+	result = render(`<shortcode include file="/examples/main.go" symbol="Server.Run" />`)
+	assert.Contains(t, result, "// Run 启动服务\nfunc (s *Server) Run() error {")
+	assert.Contains(t, result, "\treturn nil\n}\n</code>")
+
+	result = render(`<shortcode include file="/examples/main.go" symbol="Server" title="server.go" />`)
+	assert.Contains(t, result, "<figcaption>server.go</figcaption>")
+	assert.Contains(t, result, "// Server 服务\ntype Server struct {\n\tAddr string\n}\n</code>")
+
+	assert.Equal(t, []string{"posts/hello/code.py", "/examples/main.go"}, slices.Compact(deps["posts/hello/index.md"]))
+	assert.Empty(t, page.Includes)
+	assert.Empty(t, ctx.Reporter.Errors())
+}
+
+func TestIncludeShortcodeLineNumbers(t *testing.T) {
+	set, _ := testIncludeSet(t)
+	set.ctx.Config.Set("markups._default.style", "monokai")
+	set.ctx.Config.Set("markups._default.show_line_numbers", true)
+	set.ctx.Config.Set("markups._default.highlight_mode", "classes")
+
+	result := set.Render("posts/hello/index.md", `<shortcode include file="/examples/main.go" lines="10-12" />`, map[string]any{"page": includePage()})
+	assert.Contains(t, result, `<span class="ln">10</span>`)
+	assert.NotContains(t, result, `<span class="ln">1</span>`)
+}
+
+func TestIncludeShortcodeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		code string
+		err  string
+	}{
+		{`<shortcode include file="missing.go" />`, "file does not exist"},
+		{`<shortcode include file="/examples/main.go" region="missing" />`, `region "missing" not found`},
+		{`<shortcode include file="/examples/main.go" symbol="Missing" />`, `symbol "Missing" not found`},
+		{`<shortcode include file="/examples/main.go" lines="100-" />`, "out of range"},
+		{`<shortcode include file="code.py" symbol="main" />`, "only supported for go files"},
+		{`<shortcode include file="../../../secret" />`, "invalid include file"},
+	} {
+		set, ctx := testIncludeSet(t)
+
+		code := tc.code
+		assert.Equal(t, code, set.Render("posts/hello/index.md", code, map[string]any{"page": includePage()}))
+
+		errs := ctx.Reporter.Errors()
+		require.Len(t, errs, 1, code)
+		assert.Equal(t, "posts/hello/index.md", errs[0].Path)
+		assert.Contains(t, errs[0].Err.Error(), tc.err)
+	}
+}
+
+func TestDedentLines(t *testing.T) {
+	assert.Equal(t, []string{"a", "", "  b"}, dedentLines([]string{"    a", "", "      b"}))
+	assert.Equal(t, []string{"a", "b"}, dedentLines([]string{"a", "b"}))
+}
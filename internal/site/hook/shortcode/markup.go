@@ -0,0 +1,125 @@
+package shortcode
+
+import (
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/content/parser"
+	"github.com/honmaple/snow/internal/site/template"
+	"github.com/honmaple/snow/internal/utils"
+	"github.com/spf13/cast"
+	"golang.org/x/net/html"
+)
+
+// 渲染模版使用的属性, 不需要传入attributes
+var renderHookAttrs = map[string][]string{
+	parser.RenderHookLink:      {"href", "title"},
+	parser.RenderHookImage:     {"src", "alt", "title"},
+	parser.RenderHookHeading:   {"id"},
+	parser.RenderHookCodeBlock: {"data-lang", "data-info"},
+}
+
+func tokenAttr(attrs []html.Attribute, key string) string {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func (h *ShortcodeSet) loadRenderHooks() map[string]template.Template {
+	results := make(map[string]template.Template)
+
+	subFS, err := h.ctx.GetFS(core.MountTemplates, true, true)
+	if err != nil {
+		return results
+	}
+	for name, file := range parser.RenderHookTemplates(h.ctx) {
+		buf, err := fs.ReadFile(subFS, file)
+		if err != nil {
+			continue
+		}
+		tpl, err := h.tplset.FromBytes(buf)
+		if err != nil {
+			h.ctx.Logger.Warnf("compile tpl %s err: %s", file, err.Error())
+			h.ctx.Reporter.Error(&core.Error{Op: "parse render hook", Err: err, Path: file})
+			continue
+		}
+		results[name] = tpl
+	}
+	return results
+}
+
+// resolveRenderHook 查找markdown解析器标记的元素对应的渲染模版
+func (h *ShortcodeSet) resolveRenderHook(token html.Token) (*shortcodeToken, bool) {
+	kind := tokenAttr(token.Attr, parser.RenderHookAttr)
+	if kind == "" {
+		return nil, false
+	}
+
+	name := parser.RenderHookName(kind, tokenAttr(token.Attr, "data-lang"))
+	tpl, ok := h.hooks[name]
+	if !ok {
+		name = parser.RenderHookName(kind, "")
+		if tpl, ok = h.hooks[name]; !ok {
+			return nil, false
+		}
+	}
+	return &shortcodeToken{tpl: tpl, name: name, hook: kind, tag: token.Data, attr: token.Attr}, true
+}
+
+func (h *ShortcodeSet) executeRenderHook(source Source, token *shortcodeToken, body string) (string, error) {
+	attributes := make(Params)
+	for _, attr := range token.attr {
+		if attr.Key == parser.RenderHookAttr {
+			continue
+		}
+		if slices.Contains(renderHookAttrs[token.hook], attr.Key) {
+			continue
+		}
+		attributes[attr.Key] = attr.Val
+	}
+
+	ordinal := 0
+	ordinalKey := "ordinal:" + token.hook
+	if value, ok := source.Get(ordinalKey); ok {
+		ordinal = cast.ToInt(value)
+	}
+	source.Set(ordinalKey, ordinal+1)
+
+	vars := map[string]any{
+		"name":       token.name,
+		"ordinal":    ordinal,
+		"attributes": attributes,
+	}
+	switch token.hook {
+	case parser.RenderHookLink:
+		vars["destination"] = tokenAttr(token.attr, "href")
+		vars["title"] = tokenAttr(token.attr, "title")
+		vars["text"] = body
+		vars["plain_text"] = utils.PlainText(body)
+	case parser.RenderHookImage:
+		alt := tokenAttr(token.attr, "alt")
+		vars["destination"] = tokenAttr(token.attr, "src")
+		vars["title"] = tokenAttr(token.attr, "title")
+		vars["text"] = alt
+		vars["plain_text"] = alt
+	case parser.RenderHookHeading:
+		vars["level"] = cast.ToInt(strings.TrimPrefix(token.tag, "h"))
+		vars["anchor"] = tokenAttr(token.attr, "id")
+		vars["text"] = body
+		vars["plain_text"] = utils.PlainText(body)
+	case parser.RenderHookCodeBlock:
+		inner := strings.TrimSuffix(strings.TrimPrefix(body, "<code>"), "</code>")
+		vars["lang"] = tokenAttr(token.attr, "data-lang")
+		vars["info"] = tokenAttr(token.attr, "data-info")
+		vars["inner"] = html.UnescapeString(inner)
+	}
+	for k, v := range source.Context() {
+		vars[k] = v
+	}
+	return token.tpl.Execute(vars)
+}
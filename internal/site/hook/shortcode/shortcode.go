@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/hook"
 	"github.com/honmaple/snow/internal/site/template"
 	"github.com/honmaple/snow/internal/utils"
 	"github.com/spf13/cast"
@@ -18,6 +19,8 @@ type ShortcodeSet struct {
 	ctx    *core.Context
 	tpls   map[string]template.Template
 	tplset template.TemplateSet
+	// templates/_markup中的渲染模版
+	hooks map[string]template.Template
 }
 
 type (
@@ -25,6 +28,9 @@ type (
 		tpl  template.Template
 		name string
 		attr []html.Attribute
+		// 渲染模版的类型和元素标签
+		hook string
+		tag  string
 	}
 	shortcodeFrame struct {
 		tag      string
@@ -55,6 +61,10 @@ func (h *ShortcodeSet) warnf(format string, args ...any) {
 }
 
 func (h *ShortcodeSet) resolveShortcode(source Source, token html.Token) (*shortcodeToken, bool) {
+	if newToken, ok := h.resolveRenderHook(token); ok {
+		return newToken, true
+	}
+
 	name := token.Data
 	if name == "shortcode" {
 		name = shortcodeName(token)
@@ -72,6 +82,9 @@ func (h *ShortcodeSet) resolveShortcode(source Source, token html.Token) (*short
 }
 
 func (h *ShortcodeSet) executeShortcode(source Source, token *shortcodeToken, body string) (string, error) {
+	if token.hook != "" {
+		return h.executeRenderHook(source, token, body)
+	}
 	params := make(Params)
 	for _, attr := range token.attr {
 		if attr.Key == "_name" || (attr.Key == token.name && attr.Val == "") {
@@ -179,7 +192,7 @@ func (h *ShortcodeSet) render(source Source) (string, error) {
 }
 
 func (h *ShortcodeSet) Render(id string, content string, context map[string]any) string {
-	if len(h.tpls) == 0 {
+	if len(h.tpls) == 0 && len(h.hooks) == 0 {
 		return content
 	}
 	s := &source{id: id, content: content, context: context}
@@ -187,7 +200,7 @@ func (h *ShortcodeSet) Render(id string, content string, context map[string]any)
 }
 
 func (h *ShortcodeSet) RenderSource(source Source) string {
-	if len(h.tpls) == 0 {
+	if len(h.tpls) == 0 && len(h.hooks) == 0 {
 		return source.Content()
 	}
 	result, err := h.render(source)
@@ -239,6 +252,7 @@ func (h *ShortcodeSet) Load() (map[string]template.Template, error) {
 			tpl, err := h.tplset.FromBytes(buf)
 			if err != nil {
 				h.ctx.Logger.Warnf("compile tpl %s err: %s", tplFile, err.Error())
+				h.ctx.Reporter.Error(&core.Error{Op: "parse shortcode", Err: err, Path: tplFile})
 				continue
 			}
 			results[basename] = tpl
@@ -247,7 +261,7 @@ func (h *ShortcodeSet) Load() (map[string]template.Template, error) {
 	return results, nil
 }
 
-func NewShortcodeSet(ctx *core.Context, tplset template.TemplateSet) (*ShortcodeSet, error) {
+func NewShortcodeSet(ctx *core.Context, tplset template.TemplateSet, deps hook.DependencyRecorder) (*ShortcodeSet, error) {
 	h := &ShortcodeSet{
 		ctx:    ctx,
 		tpls:   make(map[string]template.Template),
@@ -257,6 +271,11 @@ func NewShortcodeSet(ctx *core.Context, tplset template.TemplateSet) (*Shortcode
 	if err != nil {
 		return nil, err
 	}
+	// 同名的模版优先
+	if _, ok := tpls[includeName]; !ok {
+		tpls[includeName] = newIncludeShortcode(ctx, deps)
+	}
 	h.tpls = tpls
+	h.hooks = h.loadRenderHooks()
 	return h, nil
 }
@@ -407,3 +407,37 @@ func TestHandleSectionRendersSummaryAndContent(t *testing.T) {
 	assert.Equal(t, `ok`, section.Summary)
 	assert.Equal(t, `<p>ok</p>`, section.Content)
 }
+
+func TestRenderMarkupHooks(t *testing.T) {
+	set := testShortcodeSet(nil)
+	set.hooks = map[string]template.Template{
+		"render-link": &testTemplate{execute: func(vars map[string]any) (string, error) {
+			assert.Equal(t, Params{"class": "x"}, vars["attributes"])
+			assert.NotNil(t, vars["page"])
+			return fmt.Sprintf(`<a href="%s" title="%s">%s|%s|%d</a>`, vars["destination"], vars["title"], vars["text"], vars["plain_text"], vars["ordinal"]), nil
+		}},
+		"render-image": &testTemplate{execute: func(vars map[string]any) (string, error) {
+			return fmt.Sprintf(`<img loading="lazy" src="%s" alt="%s">`, vars["destination"], vars["text"]), nil
+		}},
+		"render-heading": &testTemplate{execute: func(vars map[string]any) (string, error) {
+			return fmt.Sprintf(`<h%d id="%s">%s</h%[1]d>`, vars["level"], vars["anchor"], vars["plain_text"]), nil
+		}},
+		"render-codeblock-mermaid": &testTemplate{execute: func(vars map[string]any) (string, error) {
+			return fmt.Sprintf(`<div class="mermaid" data-info="%s">%s</div>`, vars["info"], vars["inner"]), nil
+		}},
+	}
+
+	result := set.Render("content/test.md", `<h2 id="hello" data-render-hook="heading">Hello <em>World</em></h2>`+
+		`<p><a href="/a/" title="t" class="x" data-render-hook="link"><em>a</em></a><a href="/b/" class="x" data-render-hook="link">b</a>`+
+		`<img src="cat.png" alt="cat" data-render-hook="image"></p>`+
+		`<pre data-render-hook="codeblock" data-lang="mermaid" data-info="{x=1}"><code>graph &lt;TD&gt;</code></pre>`+
+		`<pre data-render-hook="codeblock" data-lang="go"><code>go</code></pre>`, map[string]any{
+		"page": testPage(),
+	})
+
+	assert.Equal(t, `<h2 id="hello">Hello World</h2>`+
+		`<p><a href="/a/" title="t"><em>a</em>|a|0</a><a href="/b/" title="">b|b|1</a>`+
+		`<img loading="lazy" src="cat.png" alt="cat"></p>`+
+		`<div class="mermaid" data-info="{x=1}">graph <TD></div>`+
+		`<pre data-render-hook="codeblock" data-lang="go"><code>go</code></pre>`, result)
+}
@@ -0,0 +1,315 @@
+package sitemap
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	stdpath "path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/content"
+	"github.com/honmaple/snow/internal/site/hook"
+	"github.com/spf13/cast"
+)
+
+const (
+	xmlnsSitemap = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	xmlnsXHTML   = "http://www.w3.org/1999/xhtml"
+	// 单个sitemap文件最多包含50000个URL
+	defaultMaxURLs = 50000
+)
+
+type (
+	Option struct {
+		Path       string  `json:"path"`
+		MaxURLs    int     `json:"max_urls"`
+		Changefreq string  `json:"changefreq"`
+		Priority   float64 `json:"priority"`
+		Taxonomies *bool   `json:"taxonomies"`
+	}
+	SitemapHook struct {
+		hook.HookImpl
+		ctx    *core.Context
+		opt    Option
+		mu     sync.Mutex
+		stores map[string]hook.ContentStore
+	}
+
+	URLSet struct {
+		XMLName xml.Name `xml:"urlset"`
+		Xmlns   string   `xml:"xmlns,attr"`
+		XHTML   string   `xml:"xmlns:xhtml,attr,omitempty"`
+		URLs    []*URL   `xml:"url"`
+	}
+	URL struct {
+		Loc        string  `xml:"loc"`
+		Lastmod    string  `xml:"lastmod,omitempty"`
+		Changefreq string  `xml:"changefreq,omitempty"`
+		Priority   string  `xml:"priority,omitempty"`
+		Links      []*Link `xml:"xhtml:link"`
+
+		// 相同key不同语言的URL互为翻译
+		key  string
+		lang string
+	}
+	Link struct {
+		Rel      string `xml:"rel,attr"`
+		Hreflang string `xml:"hreflang,attr"`
+		Href     string `xml:"href,attr"`
+	}
+	Index struct {
+		XMLName  xml.Name        `xml:"sitemapindex"`
+		Xmlns    string          `xml:"xmlns,attr"`
+		Sitemaps []*IndexSitemap `xml:"sitemap"`
+	}
+	IndexSitemap struct {
+		Loc     string `xml:"loc"`
+		Lastmod string `xml:"lastmod,omitempty"`
+	}
+)
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func lastModified(pages content.Pages) time.Time {
+	var t time.Time
+	for _, page := range pages {
+		if page.Modified.After(t) {
+			t = page.Modified
+		}
+	}
+	return t
+}
+
+// isEnabled 判断front matter是否设置了sitemap: false
+func isEnabled(fm *content.FrontMatter) bool {
+	v := fm.Get("sitemap")
+	if v == nil {
+		return true
+	}
+	if b, err := cast.ToBoolE(v); err == nil {
+		return b
+	}
+	return true
+}
+
+// lookup 依次从front matter中查找sitemap.{key}, 页面优先, 然后是所属section及上级section
+func (h *SitemapHook) lookup(key string, fms ...*content.FrontMatter) string {
+	for _, fm := range fms {
+		if v := fm.Get("sitemap." + key); v != nil {
+			return cast.ToString(v)
+		}
+	}
+	switch key {
+	case "changefreq":
+		return h.opt.Changefreq
+	case "priority":
+		if h.opt.Priority > 0 {
+			return strconv.FormatFloat(h.opt.Priority, 'f', -1, 64)
+		}
+	}
+	return ""
+}
+
+func sectionFrontMatters(section *content.Section) []*content.FrontMatter {
+	fms := make([]*content.FrontMatter, 0)
+	for current := section; current != nil; current = current.Parent {
+		fms = append(fms, current.FrontMatter)
+	}
+	return fms
+}
+
+func (h *SitemapHook) newURL(loc string, lastmod time.Time, fms []*content.FrontMatter) *URL {
+	return &URL{
+		Loc:        loc,
+		Lastmod:    formatTime(lastmod),
+		Changefreq: h.lookup("changefreq", fms...),
+		Priority:   h.lookup("priority", fms...),
+	}
+}
+
+func (h *SitemapHook) collect(store hook.ContentStore, lang string) []*URL {
+	urls := make([]*URL, 0)
+
+	for _, section := range store.Sections(lang) {
+		if !isEnabled(section.FrontMatter) {
+			continue
+		}
+		url := h.newURL(section.Permalink, lastModified(section.AllPages()), sectionFrontMatters(section))
+		url.key = "section:" + section.TranslationKey
+		url.lang = lang
+		urls = append(urls, url)
+	}
+
+	for _, page := range store.Pages(lang) {
+		if !isEnabled(page.FrontMatter) {
+			continue
+		}
+		url := h.newURL(page.Permalink, page.Modified, append([]*content.FrontMatter{page.FrontMatter}, sectionFrontMatters(page.Section)...))
+		url.key = "page:" + page.TranslationKey
+		url.lang = lang
+		urls = append(urls, url)
+	}
+
+	if h.opt.Taxonomies != nil && !*h.opt.Taxonomies {
+		return urls
+	}
+
+	var walk func(content.TaxonomyTerms)
+	walk = func(terms content.TaxonomyTerms) {
+		for _, term := range terms {
+			url := h.newURL(term.Permalink, lastModified(term.Pages), nil)
+			url.key = fmt.Sprintf("term:%s:%s", term.Taxonomy.Name, term.GetFullName())
+			url.lang = lang
+			urls = append(urls, url)
+
+			walk(term.Children)
+		}
+	}
+	for _, taxonomy := range store.Taxonomies(lang) {
+		url := h.newURL(taxonomy.Permalink, time.Time{}, nil)
+		url.key = "taxonomy:" + taxonomy.Name
+		url.lang = lang
+		urls = append(urls, url)
+
+		walk(taxonomy.Terms)
+	}
+	return urls
+}
+
+// addAlternates 为存在多个语言版本的URL添加xhtml:link, 包括自身
+func addAlternates(urls []*URL) {
+	groups := make(map[string][]*URL)
+	for _, url := range urls {
+		groups[url.key] = append(groups[url.key], url)
+	}
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		links := make([]*Link, 0, len(group))
+		for _, url := range group {
+			links = append(links, &Link{Rel: "alternate", Hreflang: url.lang, Href: url.Loc})
+		}
+		slices.SortFunc(links, func(a, b *Link) int {
+			return strings.Compare(a.Hreflang, b.Hreflang)
+		})
+		for _, url := range group {
+			url.Links = links
+		}
+	}
+}
+
+func (h *SitemapHook) write(ctx context.Context, writer core.Writer, path string, v any) error {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return &core.Error{Op: "write sitemap", Err: err, Path: path}
+	}
+	buf.WriteString("\n")
+
+	if err := writer.WriteFile(ctx, path, &buf); err != nil {
+		return &core.Error{Op: "write sitemap", Err: err, Path: path}
+	}
+	return nil
+}
+
+func newURLSet(urls []*URL) *URLSet {
+	set := &URLSet{Xmlns: xmlnsSitemap, URLs: urls}
+	for _, url := range urls {
+		if len(url.Links) > 0 {
+			set.XHTML = xmlnsXHTML
+			break
+		}
+	}
+	return set
+}
+
+func (h *SitemapHook) AfterBuild(ctx context.Context, writer core.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	urls := make([]*URL, 0)
+	for _, lang := range h.ctx.GetAllLanguages() {
+		if store, ok := h.stores[lang]; ok {
+			urls = append(urls, h.collect(store, lang)...)
+		}
+	}
+	addAlternates(urls)
+
+	slices.SortStableFunc(urls, func(a, b *URL) int {
+		return strings.Compare(a.Loc, b.Loc)
+	})
+
+	path := "/" + strings.TrimPrefix(h.opt.Path, "/")
+	if len(urls) <= h.opt.MaxURLs {
+		h.ctx.Logger.Debugf("write sitemap %s with %d urls", path, len(urls))
+		return h.write(ctx, writer, path, newURLSet(urls))
+	}
+
+	// 超过最大数量时拆分为多个sitemap文件, 并生成sitemap index
+	ext := stdpath.Ext(path)
+	index := &Index{Xmlns: xmlnsSitemap}
+	for i := 0; i*h.opt.MaxURLs < len(urls); i++ {
+		chunk := urls[i*h.opt.MaxURLs : min((i+1)*h.opt.MaxURLs, len(urls))]
+
+		var lastmod time.Time
+		for _, url := range chunk {
+			if t, err := time.Parse(time.RFC3339, url.Lastmod); err == nil && t.After(lastmod) {
+				lastmod = t
+			}
+		}
+
+		file := fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), i+1, ext)
+		if err := h.write(ctx, writer, file, newURLSet(chunk)); err != nil {
+			return err
+		}
+		index.Sitemaps = append(index.Sitemaps, &IndexSitemap{
+			Loc:     h.ctx.GetURL(file),
+			Lastmod: formatTime(lastmod),
+		})
+	}
+	h.ctx.Logger.Debugf("write sitemap index %s with %d sitemaps", path, len(index.Sitemaps))
+	return h.write(ctx, writer, path, index)
+}
+
+func (h *SitemapHook) HandleContent(store hook.ContentStore, lang string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stores[lang] = store
+}
+
+func New(ctx *core.Context) (hook.Hook, error) {
+	var opt Option
+	if err := hook.Unmarshal(ctx.Config.Get("hooks.sitemap.option"), &opt); err != nil {
+		return nil, err
+	}
+	if opt.Path == "" {
+		opt.Path = "sitemap.xml"
+	}
+	if opt.MaxURLs <= 0 || opt.MaxURLs > defaultMaxURLs {
+		opt.MaxURLs = defaultMaxURLs
+	}
+	return &SitemapHook{
+		ctx:    ctx,
+		opt:    opt,
+		stores: make(map[string]hook.ContentStore),
+	}, nil
+}
+
+func init() {
+	hook.Register("sitemap", New)
+}
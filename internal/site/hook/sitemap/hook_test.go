@@ -0,0 +1,156 @@
+package sitemap
+
+import (
+	"context"
+	"io"
+	stdpath "path"
+	"testing"
+	"time"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/content"
+	"github.com/honmaple/snow/internal/writer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testContentStore struct {
+	pages    map[string]content.Pages
+	sections map[string]content.Sections
+}
+
+func (s testContentStore) Pages(lang string) content.Pages {
+	return s.pages[lang]
+}
+
+func (s testContentStore) HiddenPages(string) content.Pages {
+	return nil
+}
+
+func (s testContentStore) Sections(lang string) content.Sections {
+	return s.sections[lang]
+}
+
+func (s testContentStore) Taxonomies(string) content.Taxonomies {
+	return nil
+}
+
+func testNode(path string, lang string, fm map[string]any) *content.Node {
+	file := &content.File{Path: path, Dir: "posts", BaseName: "hello"}
+	if path == "posts/_index.md" {
+		file.BaseName = "_index"
+	}
+	return &content.Node{
+		File:           file,
+		Lang:           lang,
+		FrontMatter:    content.NewFrontMatter(fm),
+		TranslationKey: stdpath.Join(file.Dir, file.BaseName),
+	}
+}
+
+func readFile(t *testing.T, w *writer.MemoryWriter, file string) string {
+	t.Helper()
+
+	f, err := w.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+
+	b, err := io.ReadAll(f)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func newTestHook(t *testing.T, opt map[string]any) *SitemapHook {
+	t.Helper()
+
+	conf := core.DefaultConfig()
+	conf.Set("base_url", "https://example.com")
+	conf.Set("languages.zh", map[string]any{})
+	conf.Set("hooks.sitemap.option", opt)
+	ctx, err := core.NewContext(conf)
+	require.NoError(t, err)
+
+	h, err := New(ctx)
+	require.NoError(t, err)
+	return h.(*SitemapHook)
+}
+
+func TestSitemapHookWritesAlternates(t *testing.T) {
+	h := newTestHook(t, map[string]any{"changefreq": "monthly"})
+
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	section := &content.Section{
+		Node:      testNode("posts/_index.md", "en", map[string]any{"sitemap": map[string]any{"priority": 0.8}}),
+		Permalink: "https://example.com/posts/",
+	}
+	en := &content.Page{
+		Node:      testNode("posts/hello.md", "en", nil),
+		Permalink: "https://example.com/posts/hello/",
+		Modified:  modified,
+		Section:   section,
+	}
+	zh := &content.Page{
+		Node:      testNode("posts/hello.zh.md", "zh", map[string]any{"sitemap": map[string]any{"changefreq": "weekly"}}),
+		Permalink: "https://example.com/zh/posts/hello/",
+		Modified:  modified,
+	}
+	hidden := &content.Page{
+		Node:      testNode("posts/secret.md", "en", map[string]any{"sitemap": false}),
+		Permalink: "https://example.com/posts/secret/",
+	}
+	section.Pages = content.Pages{en, hidden}
+
+	store := testContentStore{
+		pages: map[string]content.Pages{
+			"en": {en, hidden},
+			"zh": {zh},
+		},
+		sections: map[string]content.Sections{
+			"en": {section},
+		},
+	}
+	h.HandleContent(store, "en")
+	h.HandleContent(store, "zh")
+
+	w := writer.NewMemoryWriter()
+	require.NoError(t, h.AfterBuild(context.Background(), w))
+
+	result := readFile(t, w, "/sitemap.xml")
+	assert.Contains(t, result, `xmlns:xhtml="http://www.w3.org/1999/xhtml"`)
+	assert.Contains(t, result, `<loc>https://example.com/posts/hello/</loc>
+    <lastmod>2024-01-02T03:04:05Z</lastmod>
+    <changefreq>monthly</changefreq>
+    <priority>0.8</priority>
+    <xhtml:link rel="alternate" hreflang="en" href="https://example.com/posts/hello/"></xhtml:link>
+    <xhtml:link rel="alternate" hreflang="zh" href="https://example.com/zh/posts/hello/"></xhtml:link>`)
+	assert.Contains(t, result, `<loc>https://example.com/zh/posts/hello/</loc>
+    <lastmod>2024-01-02T03:04:05Z</lastmod>
+    <changefreq>weekly</changefreq>`)
+	assert.Contains(t, result, `<loc>https://example.com/posts/</loc>
+    <lastmod>2024-01-02T03:04:05Z</lastmod>`)
+	assert.NotContains(t, result, "secret")
+}
+
+func TestSitemapHookSplitsIndex(t *testing.T) {
+	h := newTestHook(t, map[string]any{"max_urls": 2})
+
+	pages := make(content.Pages, 0)
+	for _, name := range []string{"a", "b", "c"} {
+		pages = append(pages, &content.Page{
+			Node:      testNode("posts/"+name+".md", "en", nil),
+			Permalink: "https://example.com/posts/" + name + "/",
+		})
+	}
+	h.HandleContent(testContentStore{pages: map[string]content.Pages{"en": pages}}, "en")
+
+	w := writer.NewMemoryWriter()
+	require.NoError(t, h.AfterBuild(context.Background(), w))
+
+	index := readFile(t, w, "/sitemap.xml")
+	assert.Contains(t, index, "<sitemapindex")
+	assert.Contains(t, index, "<loc>https://example.com/sitemap-1.xml</loc>")
+	assert.Contains(t, index, "<loc>https://example.com/sitemap-2.xml</loc>")
+
+	assert.Contains(t, readFile(t, w, "/sitemap-1.xml"), "https://example.com/posts/b/")
+	assert.Contains(t, readFile(t, w, "/sitemap-2.xml"), "https://example.com/posts/c/")
+}
@@ -0,0 +1,293 @@
+package linkcheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/honmaple/snow/internal/utils/taskutil"
+)
+
+type (
+	ExternalOption struct {
+		Workers int
+		Timeout time.Duration
+		// 同一个域名两次请求的最小间隔
+		HostInterval time.Duration
+		// 只检查匹配的链接, 为空时检查所有链接
+		Allow []string
+		// 忽略匹配的链接
+		Ignore    []string
+		UserAgent string
+		// 检查结果的缓存文件, 为空时不缓存
+		CacheFile string
+		CacheTTL  time.Duration
+	}
+	ExternalResult struct {
+		URL       string    `json:"url"`
+		Status    int       `json:"status,omitempty"`
+		Error     string    `json:"error,omitempty"`
+		CheckedAt time.Time `json:"checked_at"`
+		// 包含链接的页面
+		Pages []string `json:"pages,omitempty"`
+	}
+	ExternalReport struct {
+		Total  int               `json:"total"`
+		Cached int               `json:"cached"`
+		Dead   []*ExternalResult `json:"dead"`
+	}
+	ExternalChecker struct {
+		opt    ExternalOption
+		client *http.Client
+	}
+	// hostLimiter 限制同一个域名的请求频率, 同一个域名的链接在一个任务中依次检查
+	hostLimiter struct {
+		interval time.Duration
+		next     time.Time
+	}
+)
+
+func (r *ExternalResult) IsDead() bool {
+	return r.Error != "" || r.Status >= 400
+}
+
+func (r *ExternalResult) reason() string {
+	if r.Error != "" {
+		return r.Error
+	}
+	return fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status))
+}
+
+// WriteText 输出文本格式的报告
+func (r *ExternalReport) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%d dead links in %d external links\n", len(r.Dead), r.Total); err != nil {
+		return err
+	}
+	for _, result := range r.Dead {
+		if _, err := fmt.Fprintf(w, "%s (%s)\n", result.URL, result.reason()); err != nil {
+			return err
+		}
+		for _, page := range result.Pages {
+			if _, err := fmt.Fprintf(w, "    %s\n", page); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteJSON 输出JSON格式的报告
+func (r *ExternalReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+func matchURL(patterns []string, u *url.URL) bool {
+	path := u.Host + u.Path
+	for _, pattern := range patterns {
+		if ok, _ := doublestar.Match(pattern, u.Host); ok {
+			return true
+		}
+		if ok, _ := doublestar.Match(pattern, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// skip 检查链接是否在allow和ignore配置中
+func (c *ExternalChecker) skip(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return true
+	}
+	if len(c.opt.Allow) > 0 && !matchURL(c.opt.Allow, u) {
+		return true
+	}
+	return matchURL(c.opt.Ignore, u)
+}
+
+func (l *hostLimiter) wait(ctx context.Context) error {
+	if l.interval <= 0 {
+		return nil
+	}
+	now := time.Now()
+	next := l.next
+	if next.Before(now) {
+		next = now
+	}
+	l.next = next.Add(l.interval)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(next.Sub(now)):
+		return nil
+	}
+}
+
+func (c *ExternalChecker) request(ctx context.Context, limiter *hostLimiter, method string, link string) (int, error) {
+	if err := limiter.wait(ctx); err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	if c.opt.UserAgent != "" {
+		req.Header.Set("User-Agent", c.opt.UserAgent)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func (c *ExternalChecker) check(ctx context.Context, limiter *hostLimiter, link string) *ExternalResult {
+	result := &ExternalResult{
+		URL:       link,
+		CheckedAt: time.Now(),
+	}
+	// 部分网站不支持HEAD请求, 失败时再使用GET请求
+	status, err := c.request(ctx, limiter, http.MethodHead, link)
+	if err != nil || status >= 400 {
+		status, err = c.request(ctx, limiter, http.MethodGet, link)
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	result.Status = status
+	return result
+}
+
+func (c *ExternalChecker) loadCache() map[string]*ExternalResult {
+	cache := make(map[string]*ExternalResult)
+	if c.opt.CacheFile == "" {
+		return cache
+	}
+	buf, err := os.ReadFile(c.opt.CacheFile)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(buf, &cache); err != nil {
+		return make(map[string]*ExternalResult)
+	}
+	return cache
+}
+
+func (c *ExternalChecker) storeCache(cache map[string]*ExternalResult) error {
+	if c.opt.CacheFile == "" {
+		return nil
+	}
+	buf, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.opt.CacheFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.opt.CacheFile, buf, 0644)
+}
+
+// Check 检查站外链接, links为链接和包含链接的页面
+func (c *ExternalChecker) Check(ctx context.Context, links map[string][]string) (*ExternalReport, error) {
+	report := &ExternalReport{
+		Dead: make([]*ExternalResult, 0),
+	}
+
+	var (
+		mu      sync.Mutex
+		now     = time.Now()
+		cache   = c.loadCache()
+		results = make(map[string]*ExternalResult)
+	)
+	// 按照域名分组, 等待请求间隔时只占用当前域名的任务, 不影响其它域名
+	tasks := taskutil.NewPool[[]string](c.opt.Workers, func(hostLinks []string) error {
+		limiter := &hostLimiter{interval: c.opt.HostInterval}
+		for _, link := range hostLinks {
+			result := c.check(ctx, limiter, link)
+
+			mu.Lock()
+			results[link] = result
+			mu.Unlock()
+		}
+		return nil
+	})
+
+	hosts := make(map[string][]string)
+	for link := range links {
+		if c.skip(link) {
+			continue
+		}
+		report.Total++
+
+		if result, ok := cache[link]; ok && now.Sub(result.CheckedAt) < c.opt.CacheTTL {
+			report.Cached++
+			results[link] = result
+			continue
+		}
+		// skip中已经检查过链接的格式
+		u, _ := url.Parse(link)
+		hosts[u.Host] = append(hosts[u.Host], link)
+	}
+	for _, host := range slices.Sorted(maps.Keys(hosts)) {
+		hostLinks := hosts[host]
+		slices.Sort(hostLinks)
+		tasks.Invoke(hostLinks)
+	}
+	tasks.StopAndWait()
+
+	for link, result := range results {
+		// 只缓存有效的链接, 无效的链接下次重新检查
+		if !result.IsDead() {
+			cache[link] = result
+			continue
+		}
+		delete(cache, link)
+
+		dead := *result
+		dead.Pages = links[link]
+		report.Dead = append(report.Dead, &dead)
+	}
+	slices.SortFunc(report.Dead, func(a, b *ExternalResult) int {
+		return strings.Compare(a.URL, b.URL)
+	})
+
+	// 删除过期的缓存
+	for link, result := range cache {
+		if now.Sub(result.CheckedAt) >= c.opt.CacheTTL {
+			delete(cache, link)
+		}
+	}
+	if err := c.storeCache(cache); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+func NewExternalChecker(opt ExternalOption) *ExternalChecker {
+	if opt.Workers <= 0 {
+		opt.Workers = 8
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = 10 * time.Second
+	}
+	return &ExternalChecker{
+		opt:    opt,
+		client: &http.Client{Timeout: opt.Timeout},
+	}
+}
@@ -0,0 +1,104 @@
+package linkcheck
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalChecker(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/ok":
+		case "/head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	links := map[string][]string{
+		server.URL + "/ok":      {"posts/a.md"},
+		server.URL + "/head":    {"posts/a.md"},
+		server.URL + "/missing": {"posts/a.md", "posts/b.md"},
+		server.URL + "/ignored": {"posts/b.md"},
+	}
+	opt := ExternalOption{
+		Ignore:    []string{"*/ignored"},
+		CacheFile: filepath.Join(t.TempDir(), "external.json"),
+		CacheTTL:  time.Hour,
+	}
+
+	report, err := NewExternalChecker(opt).Check(context.TODO(), links)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 0, report.Cached)
+	require.Len(t, report.Dead, 1)
+	assert.Equal(t, server.URL+"/missing", report.Dead[0].URL)
+	assert.Equal(t, http.StatusNotFound, report.Dead[0].Status)
+	assert.Equal(t, []string{"posts/a.md", "posts/b.md"}, report.Dead[0].Pages)
+
+	var b bytes.Buffer
+	require.NoError(t, report.WriteText(&b))
+	assert.Equal(t, "1 dead links in 3 external links\n"+server.URL+"/missing (404 Not Found)\n    posts/a.md\n    posts/b.md\n", b.String())
+
+	// 有效的链接使用缓存, 无效的链接重新检查
+	requests.Store(0)
+	report, err = NewExternalChecker(opt).Check(context.TODO(), links)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Cached)
+	assert.Len(t, report.Dead, 1)
+	assert.Equal(t, int64(2), requests.Load())
+}
+
+func TestExternalCheckerHostInterval(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	links := map[string][]string{
+		server.URL + "/a": nil,
+		server.URL + "/b": nil,
+		server.URL + "/c": nil,
+	}
+	now := time.Now()
+	_, err := NewExternalChecker(ExternalOption{HostInterval: 50 * time.Millisecond}).Check(context.TODO(), links)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(now), 100*time.Millisecond)
+}
+
+func TestExternalCheckerHostQueue(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer slow.Close()
+
+	var checked atomic.Int64
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checked.Store(time.Now().UnixNano())
+	}))
+	defer fast.Close()
+
+	links := map[string][]string{
+		fast.URL + "/a": nil,
+	}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		links[slow.URL+"/"+name] = nil
+	}
+	now := time.Now()
+	_, err := NewExternalChecker(ExternalOption{Workers: 2, HostInterval: 100 * time.Millisecond}).Check(context.TODO(), links)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(now), 300*time.Millisecond)
+	// 同一个域名的等待不会占用其它域名的任务
+	assert.Less(t, time.Duration(checked.Load()-now.UnixNano()), 100*time.Millisecond)
+}
@@ -0,0 +1,282 @@
+package linkcheck
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"maps"
+	"net/url"
+	stdpath "path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/honmaple/snow/internal/core"
+	"golang.org/x/net/html"
+)
+
+// 需要检查的元素属性
+var linkAttrs = map[string]string{
+	"a":      "href",
+	"area":   "href",
+	"link":   "href",
+	"img":    "src",
+	"script": "src",
+	"iframe": "src",
+	"source": "src",
+	"video":  "src",
+	"audio":  "src",
+}
+
+type (
+	// Recorder 记录写入的所有文件, HTML文件会保存内容用于检查链接
+	Recorder struct {
+		core.Writer
+
+		mu    sync.Mutex
+		files map[string][]byte
+	}
+	// Broken 无效的链接
+	Broken struct {
+		// 链接所在的文件
+		File   string
+		URL    string
+		Reason string
+	}
+)
+
+func outputFile(path string) string {
+	return stdpath.Join("/", path)
+}
+
+func isHTML(path string) bool {
+	ext := stdpath.Ext(path)
+	return ext == ".html" || ext == ".htm"
+}
+
+func (r *Recorder) WriteFile(ctx context.Context, path string, src io.Reader) error {
+	var buf []byte
+	if isHTML(path) {
+		b, err := io.ReadAll(src)
+		if err != nil {
+			return err
+		}
+		buf = b
+		src = bytes.NewReader(b)
+	}
+	if err := r.Writer.WriteFile(ctx, path, src); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.files[outputFile(path)] = buf
+	r.mu.Unlock()
+	return nil
+}
+
+func NewRecorder(w core.Writer) *Recorder {
+	return &Recorder{
+		Writer: w,
+		files:  make(map[string][]byte),
+	}
+}
+
+type (
+	document struct {
+		ids   map[string]bool
+		links []string
+	}
+	Checker struct {
+		base  *url.URL
+		files map[string][]byte
+		docs  map[string]*document
+	}
+)
+
+func parseDocument(buf []byte) *document {
+	doc := &document{
+		ids:   make(map[string]bool),
+		links: make([]string, 0),
+	}
+	node, err := html.Parse(bytes.NewReader(buf))
+	if err != nil {
+		return doc
+	}
+
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.ElementNode {
+			attr := linkAttrs[node.Data]
+			for _, a := range node.Attr {
+				switch {
+				case a.Key == "id":
+					doc.ids[a.Val] = true
+				case a.Key == "name" && node.Data == "a":
+					doc.ids[a.Val] = true
+				case a.Key == attr:
+					doc.links = append(doc.links, strings.TrimSpace(a.Val))
+				}
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(node)
+	return doc
+}
+
+func (c *Checker) document(file string) *document {
+	if doc, ok := c.docs[file]; ok {
+		return doc
+	}
+	doc := parseDocument(c.files[file])
+	c.docs[file] = doc
+	return doc
+}
+
+// pageURL 返回文件对应的访问地址, index.html使用目录地址
+func (c *Checker) pageURL(file string) *url.URL {
+	path := file
+	if stdpath.Base(path) == "index.html" {
+		path = strings.TrimSuffix(path, "index.html")
+	}
+	return c.base.ResolveReference(&url.URL{Path: strings.TrimPrefix(path, "/")})
+}
+
+// resolve 站内链接返回对应的文件地址, 站外链接返回false
+func (c *Checker) resolve(file string, link string) (*url.URL, bool) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, false
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return nil, false
+	}
+	target := c.pageURL(file).ResolveReference(u)
+	if target.Host != c.base.Host {
+		return nil, false
+	}
+	return target, true
+}
+
+// targetFile 查找链接指向的文件, 不存在时返回空
+func (c *Checker) targetFile(target *url.URL) string {
+	basePath := c.base.Path
+	if !strings.HasPrefix(target.Path+"/", basePath) {
+		return ""
+	}
+	path := "/" + strings.TrimPrefix(target.Path, basePath)
+
+	candidates := []string{stdpath.Clean(path)}
+	if strings.HasSuffix(path, "/") {
+		candidates = []string{stdpath.Join(path, "index.html")}
+	} else if stdpath.Ext(path) == "" {
+		candidates = append(candidates, stdpath.Join(path, "index.html"))
+	}
+	for _, candidate := range candidates {
+		if _, ok := c.files[candidate]; ok {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func (c *Checker) checkLink(file string, link string) (*Broken, bool) {
+	if link == "" {
+		return nil, false
+	}
+	target, ok := c.resolve(file, link)
+	if !ok {
+		return nil, false
+	}
+	broken := &Broken{File: file, URL: link}
+
+	targetFile := file
+	if target.Path != c.pageURL(file).Path {
+		targetFile = c.targetFile(target)
+		if targetFile == "" {
+			broken.Reason = "target not found"
+			return broken, true
+		}
+	}
+	if fragment := target.Fragment; fragment != "" && isHTML(targetFile) {
+		if !c.document(targetFile).ids[fragment] {
+			broken.Reason = "anchor #" + fragment + " not found"
+			return broken, true
+		}
+	}
+	return nil, false
+}
+
+// Check 检查所有HTML文件中的站内链接
+func (c *Checker) Check() []*Broken {
+	files := make([]string, 0, len(c.files))
+	for file := range c.files {
+		if isHTML(file) {
+			files = append(files, file)
+		}
+	}
+	slices.Sort(files)
+
+	results := make([]*Broken, 0)
+	for _, file := range files {
+		for _, link := range c.document(file).links {
+			if broken, ok := c.checkLink(file, link); ok {
+				results = append(results, broken)
+			}
+		}
+	}
+	return results
+}
+
+// ExternalLinks 返回所有站外链接和包含链接的文件
+func (c *Checker) ExternalLinks() map[string][]string {
+	links := make(map[string][]string)
+	for file := range c.files {
+		if !isHTML(file) {
+			continue
+		}
+		for _, link := range c.document(file).links {
+			u, err := url.Parse(link)
+			if err != nil || u.Host == "" || u.Host == c.base.Host {
+				continue
+			}
+			switch u.Scheme {
+			case "":
+				u.Scheme = c.base.Scheme
+			case "http", "https":
+			default:
+				continue
+			}
+			u.Fragment = ""
+
+			key := u.String()
+			if !slices.Contains(links[key], file) {
+				links[key] = append(links[key], file)
+			}
+		}
+	}
+	for _, files := range links {
+		slices.Sort(files)
+	}
+	return links
+}
+
+func NewChecker(baseURL string, r *Recorder) (*Checker, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path = base.Path + "/"
+	}
+	r.mu.Lock()
+	files := maps.Clone(r.files)
+	r.mu.Unlock()
+
+	return &Checker{
+		base:  base,
+		files: files,
+		docs:  make(map[string]*document),
+	}, nil
+}
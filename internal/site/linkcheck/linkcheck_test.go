@@ -0,0 +1,55 @@
+package linkcheck
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/honmaple/snow/internal/writer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker(t *testing.T) {
+	files := map[string]string{
+		"posts/a/index.html": `<h2 id="intro">Intro</h2>
+<a href="../b/">b</a>
+<a href="/posts/b/#usage">usage</a>
+<a href="/blog/posts/b/#missing">missing anchor</a>
+<a href="#intro">self</a>
+<a href="#nothing">self missing</a>
+<a href="https://example.com/blog/posts/c/">missing page</a>
+<a href="https://other.com/posts/c/">external</a>
+<a href="mailto:a@example.com">mail</a>
+<img src="../../images/logo.png">
+<img src="/blog/images/missing.png">`,
+		"posts/b/index.html": `<a name="usage"></a>`,
+		"images/logo.png":    "",
+	}
+
+	r := NewRecorder(writer.NewMemoryWriter())
+	for file, content := range files {
+		require.NoError(t, r.WriteFile(context.TODO(), file, strings.NewReader(content)))
+	}
+
+	checker, err := NewChecker("https://example.com/blog", r)
+	require.NoError(t, err)
+
+	results := checker.Check()
+	reasons := make([]string, 0, len(results))
+	for _, broken := range results {
+		assert.Equal(t, "/posts/a/index.html", broken.File)
+		reasons = append(reasons, broken.URL+": "+broken.Reason)
+	}
+	assert.Equal(t, []string{
+		"/posts/b/#usage: target not found",
+		"/blog/posts/b/#missing: anchor #missing not found",
+		"#nothing: anchor #nothing not found",
+		"https://example.com/blog/posts/c/: target not found",
+		"/blog/images/missing.png: target not found",
+	}, reasons)
+
+	assert.Equal(t, map[string][]string{
+		"https://other.com/posts/c/": {"/posts/a/index.html"},
+	}, checker.ExternalLinks())
+}
@@ -0,0 +1,98 @@
+package site
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/linkcheck"
+	"github.com/honmaple/snow/internal/writer"
+)
+
+// outputSources 返回输出文件对应的内容文件
+func (site *Site) outputSources() map[string]string {
+	sources := make(map[string]string)
+	for _, output := range site.outputs {
+		if _, ok := sources[output.File]; !ok {
+			sources[output.File] = output.Source
+		}
+	}
+	return sources
+}
+
+func sourceOf(sources map[string]string, file string) string {
+	if source, ok := sources[file]; ok {
+		return source
+	}
+	return file
+}
+
+// checkLinks 检查构建输出的HTML文件中的站内链接和锚点, 按内容文件报告无效的链接
+func (site *Site) checkLinks(recorder *linkcheck.Recorder) error {
+	checker, err := linkcheck.NewChecker(site.ctx.GetBaseURL(), recorder)
+	if err != nil {
+		return err
+	}
+
+	sources := site.outputSources()
+
+	results := checker.Check()
+	for _, broken := range results {
+		err := &core.Error{
+			Op:   "check links",
+			Err:  fmt.Errorf("%s: %s", broken.URL, broken.Reason),
+			Path: sourceOf(sources, broken.File),
+		}
+		site.ctx.Logger.Error(err.Error())
+		site.ctx.Reporter.Error(err)
+	}
+	if len(results) > 0 {
+		site.ctx.Logger.Infof("Found %d broken links", len(results))
+	}
+	return nil
+}
+
+// CheckExternal 在内存中构建站点, 检查输出的HTML文件中的站外链接
+func (site *Site) CheckExternal(ctx context.Context) (*linkcheck.ExternalReport, error) {
+	recorder := linkcheck.NewRecorder(writer.NewNullWriter())
+	if err := site.build(ctx, recorder); err != nil {
+		return nil, err
+	}
+
+	checker, err := linkcheck.NewChecker(site.ctx.GetBaseURL(), recorder)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := site.outputSources()
+
+	links := make(map[string][]string)
+	for link, files := range checker.ExternalLinks() {
+		pages := make([]string, 0, len(files))
+		for _, file := range files {
+			if source := sourceOf(sources, file); !slices.Contains(pages, source) {
+				pages = append(pages, source)
+			}
+		}
+		slices.Sort(pages)
+		links[link] = pages
+	}
+
+	conf := site.ctx.Config
+	opt := linkcheck.ExternalOption{
+		Workers:      conf.GetInt("external_links.workers"),
+		Timeout:      conf.GetDuration("external_links.timeout"),
+		HostInterval: conf.GetDuration("external_links.host_interval"),
+		Allow:        conf.GetStringSlice("external_links.allow"),
+		Ignore:       conf.GetStringSlice("external_links.ignore"),
+		UserAgent:    conf.GetString("external_links.user_agent"),
+		CacheTTL:     conf.GetDuration("external_links.cache_ttl"),
+	}
+	if dir := conf.GetString("cache_dir"); dir != "" {
+		opt.CacheFile = filepath.Join(dir, "links", "external.json")
+	}
+	site.ctx.Logger.Infof("Checking %d external links...", len(links))
+	return linkcheck.NewExternalChecker(opt).Check(ctx, links)
+}
@@ -0,0 +1,85 @@
+package site
+
+import (
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/content"
+)
+
+// collectOutputs 收集所有会写入的文件, 包括静态文件
+func (site *Site) collectOutputs(processor *content.Processor, store *ContentStore) (content.Outputs, error) {
+	outputs := make(content.Outputs, 0)
+	if err := site.walkStatic(func(_ fs.FS, path string) error {
+		outputs = append(outputs, &content.Output{
+			File:   content.OutputFile(path),
+			Kind:   "static",
+			Source: path,
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, lang := range site.ctx.GetAllLanguages() {
+		for _, section := range store.Sections(lang) {
+			outputs = append(outputs, processor.SectionOutputs(section)...)
+		}
+		for _, page := range store.Pages(lang) {
+			outputs = append(outputs, processor.PageOutputs(page)...)
+		}
+		for _, page := range store.HiddenPages(lang) {
+			outputs = append(outputs, processor.PageOutputs(page)...)
+		}
+		for _, taxonomy := range store.Taxonomies(lang) {
+			outputs = append(outputs, processor.TaxonomyOutputs(taxonomy)...)
+		}
+	}
+	return outputs, nil
+}
+
+// checkOutputs 在渲染前检查是否有多个来源写入同一个文件, 并发渲染时只有最后写入的有效
+func (site *Site) checkOutputs(outputs content.Outputs) error {
+	files := make(map[string][]string)
+	for _, output := range outputs {
+		// 多语言页面共享的bundle资源会重复写入相同的文件, 不算冲突
+		source := fmt.Sprintf("%s %s", output.Kind, output.Source)
+		if !slices.Contains(files[output.File], source) {
+			files[output.File] = append(files[output.File], source)
+		}
+	}
+
+	errs := make([]*core.Error, 0)
+	for file, sources := range files {
+		if len(sources) < 2 {
+			continue
+		}
+		slices.Sort(sources)
+		errs = append(errs, &core.Error{
+			Op:   "output collision",
+			Err:  fmt.Errorf("written by %s", strings.Join(sources, ", ")),
+			Path: file,
+		})
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	slices.SortFunc(errs, func(a, b *core.Error) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	if site.ctx.Config.GetString("output_collision") == "warn" {
+		for _, err := range errs {
+			site.ctx.Logger.Warnf("%s", err.Error())
+			site.ctx.Reporter.Warn(err)
+		}
+		return nil
+	}
+	for _, err := range errs {
+		site.ctx.Logger.Error(err.Error())
+	}
+	return &core.BuildError{Errors: errs}
+}
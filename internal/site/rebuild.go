@@ -0,0 +1,359 @@
+package site
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"maps"
+	stdpath "path"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/content"
+	"github.com/honmaple/snow/internal/site/content/parser"
+)
+
+// ErrRebuildAll 表示无法确定受影响的内容, 需要重新构建整个站点
+var ErrRebuildAll = errors.New("rebuild all content")
+
+type (
+	// recordWriter 记录写入和删除的文件, 删除时使用没有经过hook处理的writer
+	recordWriter struct {
+		core.Writer
+		remover core.Writer
+		mu      sync.Mutex
+		paths   []string
+	}
+	removableWriter interface {
+		Remove(string) error
+	}
+	// parsedResults 保存上一次构建的解析结果, 开发服务器中只重新解析修改的文件以及依赖于修改文件的内容
+	parsedResults struct {
+		mu    sync.Mutex
+		items map[string]*parser.Result
+	}
+	// resultParser 优先使用上一次构建的解析结果
+	resultParser struct {
+		parser.Parser
+		results *parsedResults
+		reused  atomic.Int64
+	}
+)
+
+func (w *recordWriter) WriteFile(ctx context.Context, file string, r io.Reader) error {
+	w.mu.Lock()
+	w.paths = append(w.paths, dependencyOutput(file))
+	w.mu.Unlock()
+	return w.Writer.WriteFile(ctx, file, r)
+}
+
+func (w *recordWriter) Remove(file string) error {
+	if rw, ok := w.remover.(removableWriter); ok {
+		if err := rw.Remove(file); err != nil {
+			return err
+		}
+	}
+	w.mu.Lock()
+	w.paths = append(w.paths, file)
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *recordWriter) Paths() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	paths := slices.Clone(w.paths)
+	slices.Sort(paths)
+	return slices.Compact(paths)
+}
+
+func (r *parsedResults) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items = make(map[string]*parser.Result)
+}
+
+// Invalidate 删除需要重新解析的结果并返回对应的文件: 修改的文件, 引用了修改文件的内容,
+// 以及修改的_index文件所在目录中的内容
+func (r *parsedResults) Invalidate(files map[string]bool) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dirs := make([]string, 0)
+	for file := range files {
+		if strings.HasPrefix(stdpath.Base(file), "_index.") {
+			dirs = append(dirs, stdpath.Dir(file))
+		}
+	}
+	invalid := func(path string, result *parser.Result) bool {
+		if files[path] {
+			return true
+		}
+		for _, file := range result.Includes {
+			if files[file] {
+				return true
+			}
+		}
+		for _, dir := range dirs {
+			if dir == "." || strings.HasPrefix(path, dir+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	paths := make([]string, 0)
+	for path, result := range r.items {
+		if invalid(path, result) {
+			delete(r.items, path)
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func (r *parsedResults) load(path string) (*parser.Result, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result, ok := r.items[path]
+	return result, ok
+}
+
+func (r *parsedResults) store(path string, result *parser.Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.items == nil {
+		r.items = make(map[string]*parser.Result)
+	}
+	r.items[path] = result
+}
+
+func (p *resultParser) Parse(fsys fs.FS, file string) (*parser.Result, error) {
+	return p.ParseDefaults(fsys, file, nil)
+}
+
+// ParseDefaults 返回解析结果的副本, 避免修改保存的front matter, cascade变化时上级_index会使结果失效
+func (p *resultParser) ParseDefaults(fsys fs.FS, file string, defaults map[string]any) (*parser.Result, error) {
+	result, ok := p.results.load(file)
+	if ok {
+		p.reused.Add(1)
+	} else {
+		var (
+			r   *parser.Result
+			err error
+		)
+		if dp, ok := p.Parser.(parser.DefaultsParser); ok {
+			r, err = dp.ParseDefaults(fsys, file, defaults)
+		} else {
+			r, err = p.Parser.Parse(fsys, file)
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.results.store(file, r)
+		result = r
+	}
+	clone := *result
+	clone.FrontMatter = maps.Clone(result.FrontMatter)
+	return &clone, nil
+}
+
+func (p *resultParser) FrontMatterKeys(file string) []string {
+	if kp, ok := p.Parser.(parser.FrontMatterKeysParser); ok {
+		return kp.FrontMatterKeys(file)
+	}
+	return nil
+}
+
+// Reused 返回使用上一次解析结果的数量
+func (p *resultParser) Reused() int64 {
+	return p.reused.Load()
+}
+
+func (site *Site) contentKeys(store *ContentStore) map[string]bool {
+	keys := make(map[string]bool)
+
+	var walk func(content.TaxonomyTerms)
+	walk = func(terms content.TaxonomyTerms) {
+		for _, term := range terms {
+			keys[dependencyKey(term)] = true
+			walk(term.Children)
+		}
+	}
+	for _, lang := range site.ctx.GetAllLanguages() {
+		for _, section := range store.Sections(lang) {
+			keys[dependencyKey(section)] = true
+		}
+		for _, page := range store.Pages(lang) {
+			keys[dependencyKey(page)] = true
+		}
+		for _, page := range store.HiddenPages(lang) {
+			keys[dependencyKey(page)] = true
+		}
+		for _, taxonomy := range store.Taxonomies(lang) {
+			keys[dependencyKey(taxonomy)] = true
+			walk(taxonomy.Terms)
+		}
+	}
+	return keys
+}
+
+// changedCollections 返回受修改的内容影响的内容列表, 增加或者删除page时sections也会变化
+func (site *Site) changedCollections(prev *ContentStore, store *ContentStore, files map[string]bool) map[string]bool {
+	collections := make(map[string]bool)
+	for _, lang := range site.ctx.GetAllLanguages() {
+		pages := make(map[string]int)
+		for _, s := range []*ContentStore{prev, store} {
+			for _, section := range s.Sections(lang) {
+				if section.File != nil && files[section.File.Path] {
+					collections[collectionKey("sections", lang)] = true
+				}
+			}
+			for name, list := range map[string]content.Pages{"pages": s.Pages(lang), "hidden_pages": s.HiddenPages(lang)} {
+				for _, page := range list {
+					if files[page.File.Path] {
+						collections[collectionKey(name, lang)] = true
+						pages[page.File.Path]++
+					}
+				}
+			}
+
+			var walk func(content.TaxonomyTerms)
+			walk = func(terms content.TaxonomyTerms) {
+				for _, term := range terms {
+					for _, page := range term.Pages {
+						if files[page.File.Path] {
+							collections[collectionKey("taxonomies", lang)] = true
+						}
+					}
+					walk(term.Children)
+				}
+			}
+			for _, taxonomy := range s.Taxonomies(lang) {
+				walk(taxonomy.Terms)
+			}
+		}
+		for _, count := range pages {
+			if count == 1 {
+				collections[collectionKey("sections", lang)] = true
+			}
+		}
+	}
+	return collections
+}
+
+// RebuildContent 只重新解析修改的文件和依赖于修改文件的内容, 只渲染依赖于变化内容的page, section和taxonomy, 返回写入或删除的文件路径
+func (site *Site) RebuildContent(ctx context.Context, w core.Writer, files ...string) ([]string, error) {
+	site.ctx.Reporter.Reset()
+	if site.store == nil {
+		return nil, ErrRebuildAll
+	}
+
+	writer, err := site.hook.HandleWriter(w)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(map[string]bool)
+	for _, file := range files {
+		changed[file] = true
+	}
+	// 解析时hook读取了修改的文件, 例如include shortcode引用的文件
+	for _, path := range site.deps.IncludedBy(changed) {
+		changed[path] = true
+	}
+	for _, path := range site.results.Invalidate(changed) {
+		changed[path] = true
+	}
+
+	processor, store, err := site.parseContent()
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := site.collectOutputs(processor, store)
+	if err != nil {
+		return nil, err
+	}
+	if err := site.checkOutputs(outputs); err != nil {
+		return nil, err
+	}
+	if errs := site.ctx.Reporter.Fatals(); len(errs) > 0 {
+		return nil, &core.BuildError{Errors: errs}
+	}
+	collections := site.changedCollections(site.store, store, changed)
+
+	rw := &recordWriter{Writer: writer, remover: w}
+	// 删除已经不存在的内容
+	keys := site.contentKeys(store)
+	for _, key := range site.deps.Keys() {
+		if keys[key] {
+			continue
+		}
+		for _, output := range site.deps.Remove(key) {
+			if err := rw.Remove(output); err != nil {
+				site.ctx.Logger.Warnf("remove %s err: %s", output, err.Error())
+			}
+		}
+	}
+
+	site.store = store
+	site.outputs = outputs
+	site.processor = processor
+
+	filter := func(arg any) bool {
+		key := dependencyKey(arg)
+		if !site.deps.Contains(key) || site.deps.DependsOnFiles(key, changed, collections) {
+			return true
+		}
+		for _, file := range dependencyFiles(arg) {
+			if changed[file] {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, lang := range site.ctx.GetAllLanguages() {
+		site.renderContent(processor, store, lang, rw, filter)
+	}
+	return rw.Paths(), nil
+}
+
+// RebuildTemplate 重新加载模版, 只渲染直接使用了变化模版的内容, 返回写入的文件路径
+func (site *Site) RebuildTemplate(ctx context.Context, w core.Writer, names ...string) ([]string, error) {
+	site.ctx.Reporter.Reset()
+
+	tplset, err := site.newTemplateSet()
+	if err != nil {
+		return nil, err
+	}
+	site.tplset = tplset
+
+	if site.store == nil {
+		return nil, ErrRebuildAll
+	}
+	// base.html等被继承或引用的模版无法确定影响范围
+	keys, ok := site.deps.FindByTemplates(names)
+	if !ok {
+		return nil, ErrRebuildAll
+	}
+
+	writer, err := site.hook.HandleWriter(w)
+	if err != nil {
+		return nil, err
+	}
+
+	rw := &recordWriter{Writer: writer, remover: w}
+	for _, lang := range site.ctx.GetAllLanguages() {
+		site.renderContent(site.processor, site.store, lang, rw, func(arg any) bool {
+			return keys[dependencyKey(arg)]
+		})
+	}
+	return rw.Paths(), nil
+}
@@ -0,0 +1,261 @@
+package site
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/writer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/honmaple/snow/internal/site/content/parser/markdown"
+	_ "github.com/honmaple/snow/internal/site/content/parser/orgmode"
+)
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func newRebuildTestSite(t *testing.T) (*Site, *writer.MemoryWriter) {
+	t.Helper()
+
+	root := t.TempDir()
+	t.Chdir(root)
+
+	writeTestFile(t, "content/posts/_index.md", "---\ntitle: Posts\n---\n")
+	writeTestFile(t, "content/posts/a.md", "---\ntitle: A\ndate: 2024-01-03\n---\na\n")
+	writeTestFile(t, "content/posts/b.md", "---\ntitle: B\ndate: 2024-01-02\n---\nb\n")
+	writeTestFile(t, "content/posts/c.md", "---\ntitle: C\ndate: 2024-01-01\n---\nc\n")
+	writeTestFile(t, "templates/index.html", "{% for page in pages %}{{ page.Title }}{% endfor %}")
+	writeTestFile(t, "templates/section.html", "{% for page in section.Pages %}{{ page.Title }}{% endfor %}")
+	writeTestFile(t, "templates/page.html", "{{ page.Title }}")
+
+	conf := core.DefaultConfig()
+	for _, name := range []string{"assets", "encrypt", "links", "shortcode"} {
+		conf.Set("hooks."+name+".enabled", false)
+	}
+	ctx, err := core.NewContext(conf)
+	require.NoError(t, err)
+
+	s, err := New(ctx)
+	require.NoError(t, err)
+
+	w := writer.NewMemoryWriter()
+	require.NoError(t, s.BuildContent(context.TODO(), w))
+	return s, w
+}
+
+func TestRebuildContentOnlyRendersDependents(t *testing.T) {
+	s, w := newRebuildTestSite(t)
+
+	writeTestFile(t, "content/posts/a.md", "---\ntitle: AA\ndate: 2024-01-03\n---\na\n")
+
+	paths, err := s.RebuildContent(context.TODO(), w, "posts/a.md")
+	require.NoError(t, err)
+
+	assert.Contains(t, paths, "/posts/a/index.html")
+	assert.Contains(t, paths, "/posts/b/index.html")
+	assert.Contains(t, paths, "/posts/index.html")
+	assert.Contains(t, paths, "/index.html")
+	assert.NotContains(t, paths, "/posts/c/index.html")
+
+	f, err := w.Open("/posts/a/index.html")
+	require.NoError(t, err)
+	defer f.Close()
+
+	buf := make([]byte, 16)
+	n, _ := f.Read(buf)
+	assert.Equal(t, "AA", string(buf[:n]))
+}
+
+func TestRebuildContentRendersCollectionDependents(t *testing.T) {
+	s, w := newRebuildTestSite(t)
+
+	writeTestFile(t, "templates/page.html", "{{ page.Title }}:{{ sections|length }}{% if page.Title == \"D\" %}:{{ get_page(\"posts/a.md\").Title }}{% endif %}")
+	writeTestFile(t, "content/posts/d.md", "---\ntitle: D\ndate: 2023-12-31\n---\nd\n")
+	s, err := New(s.ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.BuildContent(context.TODO(), w))
+
+	// 修改page不会影响sections, 只渲染读取了pages或者get_page的内容
+	writeTestFile(t, "content/posts/a.md", "---\ntitle: AA\ndate: 2024-01-03\n---\na\n")
+	paths, err := s.RebuildContent(context.TODO(), w, "posts/a.md")
+	require.NoError(t, err)
+	assert.Contains(t, paths, "/index.html")
+	assert.Contains(t, paths, "/posts/d/index.html")
+	assert.NotContains(t, paths, "/posts/c/index.html")
+
+	for path, expected := range map[string]string{
+		"/index.html":         "AABCD",
+		"/posts/d/index.html": "D:2:AA",
+	} {
+		f, err := w.Open(path)
+		require.NoError(t, err)
+
+		buf, err := io.ReadAll(f)
+		f.Close()
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(buf))
+	}
+
+	// 增加section后所有读取了sections的内容都需要重新渲染
+	writeTestFile(t, "content/about/_index.md", "---\ntitle: About\n---\n")
+	paths, err = s.RebuildContent(context.TODO(), w, "about/_index.md")
+	require.NoError(t, err)
+	assert.Contains(t, paths, "/posts/a/index.html")
+	assert.Contains(t, paths, "/posts/c/index.html")
+}
+
+func TestRebuildContentOnlyParsesChangedFiles(t *testing.T) {
+	s, w := newRebuildTestSite(t)
+
+	// 没有通知修改的文件使用上一次的解析结果
+	writeTestFile(t, "content/posts/b.md", "---\ntitle: BB\ndate: 2024-01-02\n---\nb\n")
+	writeTestFile(t, "content/posts/a.md", "---\ntitle: AA\ndate: 2024-01-03\n---\na\n")
+	_, err := s.RebuildContent(context.TODO(), w, "posts/a.md")
+	require.NoError(t, err)
+
+	lang := s.ctx.GetDefaultLanguage()
+	assert.Equal(t, "AA", s.store.GetPage("posts/a.md", lang).Title)
+	assert.Equal(t, "B", s.store.GetPage("posts/b.md", lang).Title)
+
+	// 修改_index时重新解析section中的内容
+	writeTestFile(t, "content/posts/_index.md", "---\ntitle: Posts\n---\n")
+	_, err = s.RebuildContent(context.TODO(), w, "posts/_index.md")
+	require.NoError(t, err)
+	assert.Equal(t, "BB", s.store.GetPage("posts/b.md", lang).Title)
+}
+
+func TestRebuildContentRemovesStaleOutputs(t *testing.T) {
+	s, w := newRebuildTestSite(t)
+
+	writeTestFile(t, "content/posts/_index.md", "---\ntitle: Posts\npaginate: 1\n---\n")
+	require.NoError(t, s.BuildContent(context.TODO(), w))
+	_, err := w.Open("/posts/page/3/index.html")
+	require.NoError(t, err)
+
+	require.NoError(t, os.Remove("content/posts/c.md"))
+	paths, err := s.RebuildContent(context.TODO(), w, "posts/c.md")
+	require.NoError(t, err)
+	assert.Contains(t, paths, "/posts/page/3/index.html")
+
+	_, err = w.Open("/posts/page/3/index.html")
+	assert.True(t, os.IsNotExist(err))
+	_, err = w.Open("/posts/page/2/index.html")
+	assert.NoError(t, err)
+}
+
+func TestRebuildContentRendersIncludes(t *testing.T) {
+	s, w := newRebuildTestSite(t)
+
+	writeTestFile(t, "content/posts/_setup.org", "#+TITLE: Setup\n")
+	writeTestFile(t, "content/posts/d.org", "#+SETUPFILE: \"_setup.org\"\n#+DATE: 2023-12-01\n\nd\n")
+	require.NoError(t, s.BuildContent(context.TODO(), w))
+	assert.True(t, s.IsContentDependency("posts/_setup.org"))
+
+	writeTestFile(t, "content/posts/_setup.org", "#+TITLE: Changed\n")
+	paths, err := s.RebuildContent(context.TODO(), w, "posts/_setup.org")
+	require.NoError(t, err)
+	assert.Contains(t, paths, "/posts/d/index.html")
+	// 上一篇的标题变化
+	assert.Contains(t, paths, "/posts/c/index.html")
+	assert.NotContains(t, paths, "/posts/a/index.html")
+
+	f, err := w.Open("/posts/d/index.html")
+	require.NoError(t, err)
+	defer f.Close()
+
+	buf := make([]byte, 16)
+	n, _ := f.Read(buf)
+	assert.Equal(t, "Changed", string(buf[:n]))
+}
+
+func TestRebuildContentRendersExternalIncludes(t *testing.T) {
+	s, w := newRebuildTestSite(t)
+
+	writeTestFile(t, "templates/page.html", "{{ page.Content|safe }}")
+	writeTestFile(t, "examples/main.go", "package main\n")
+	writeTestFile(t, "content/posts/d.org", "#+TITLE: D\n#+INCLUDE: \"/examples/main.go\" src go :highlight no\n")
+
+	s.ctx.Config.Set("hooks.shortcode.enabled", true)
+	s, err := New(s.ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.BuildContent(context.TODO(), w))
+	assert.Equal(t, []string{"/examples/main.go"}, s.ExternalDependencies())
+	assert.True(t, s.IsContentDependency("/examples/main.go"))
+
+	writeTestFile(t, "examples/main.go", "package changed\n")
+	paths, err := s.RebuildContent(context.TODO(), w, "/examples/main.go")
+	require.NoError(t, err)
+	assert.Contains(t, paths, "/posts/d/index.html")
+	assert.NotContains(t, paths, "/posts/c/index.html")
+
+	f, err := w.Open("/posts/d/index.html")
+	require.NoError(t, err)
+	defer f.Close()
+
+	buf, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Contains(t, string(buf), "package changed")
+}
+
+func TestRebuildContentRemovesDeletedPages(t *testing.T) {
+	s, w := newRebuildTestSite(t)
+
+	require.NoError(t, os.Remove("content/posts/c.md"))
+
+	paths, err := s.RebuildContent(context.TODO(), w, "posts/c.md")
+	require.NoError(t, err)
+	assert.Contains(t, paths, "/posts/c/index.html")
+	assert.Contains(t, paths, "/posts/index.html")
+
+	_, err = w.Open("/posts/c/index.html")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRebuildContentOutputCollisions(t *testing.T) {
+	s, w := newRebuildTestSite(t)
+
+	writeTestFile(t, "content/posts/d.md", "---\ntitle: D\nslug: a\n---\nd\n")
+
+	_, err := s.RebuildContent(context.TODO(), w, "posts/d.md")
+
+	var buildErr *core.BuildError
+	require.ErrorAs(t, err, &buildErr)
+	require.Len(t, buildErr.Errors, 1)
+	assert.Equal(t, "/posts/a/index.html", buildErr.Errors[0].Path)
+
+	f, err := w.Open("/posts/a/index.html")
+	require.NoError(t, err)
+	defer f.Close()
+
+	buf := make([]byte, 16)
+	n, _ := f.Read(buf)
+	assert.Equal(t, "A", string(buf[:n]))
+}
+
+func TestRebuildTemplateOnlyRendersUsers(t *testing.T) {
+	s, w := newRebuildTestSite(t)
+
+	writeTestFile(t, "templates/page.html", "<p>{{ page.Title }}</p>")
+
+	paths, err := s.RebuildTemplate(context.TODO(), w, "page.html")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"/posts/a/index.html",
+		"/posts/b/index.html",
+		"/posts/c/index.html",
+	}, paths)
+
+	writeTestFile(t, "templates/_partials/base.html", "")
+
+	_, err = s.RebuildTemplate(context.TODO(), w, "_partials/base.html")
+	assert.ErrorIs(t, err, ErrRebuildAll)
+}
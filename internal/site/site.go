@@ -4,16 +4,28 @@ import (
 	"context"
 
 	"github.com/honmaple/snow/internal/core"
+	"github.com/honmaple/snow/internal/site/content"
 	"github.com/honmaple/snow/internal/site/hook"
+	"github.com/honmaple/snow/internal/site/linkcheck"
 	"github.com/honmaple/snow/internal/site/template"
 )
 
 type (
 	Site struct {
-		ctx           *core.Context
-		hook          hook.Hook
-		tplset        template.TemplateSet
-		includeDrafts bool
+		ctx            *core.Context
+		hook           hook.Hook
+		tplset         template.TemplateSet
+		includeDrafts  bool
+		includeFuture  bool
+		includeExpired bool
+		useCache       bool
+
+		// 最近一次构建的内容, 用于开发服务器的增量构建
+		deps      *Dependencies
+		results   *parsedResults
+		store     *ContentStore
+		processor *content.Processor
+		outputs   content.Outputs
 	}
 	SiteOption func(*Site)
 )
@@ -27,6 +39,50 @@ func (site *Site) newTemplateSet() (template.TemplateSet, error) {
 }
 
 func (site *Site) Build(ctx context.Context, w core.Writer) error {
+	site.ctx.Reporter.Reset()
+
+	if err := site.build(ctx, w); err != nil {
+		if !site.ctx.Config.GetBool("strict") {
+			return err
+		}
+		site.ctx.Reporter.Error(err)
+	}
+	return site.Check()
+}
+
+// Check 严格模式下汇总构建过程中出现的错误, 如果开启了strict_warnings警告也会作为错误
+func (site *Site) Check() error {
+	if !site.ctx.Config.GetBool("strict") {
+		return nil
+	}
+	errs := site.ctx.Reporter.Errors()
+	if site.ctx.Config.GetBool("strict_warnings") {
+		errs = append(errs, site.ctx.Reporter.Warnings()...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &core.BuildError{Errors: errs}
+}
+
+// CheckContent 只解析内容, 检查front matter等错误, 不渲染页面
+func (site *Site) CheckContent() error {
+	site.ctx.Reporter.Reset()
+
+	if _, _, err := site.parseContent(); err != nil {
+		site.ctx.Reporter.Error(err)
+	}
+	return site.Check()
+}
+
+func (site *Site) build(ctx context.Context, w core.Writer) error {
+	// 严格模式下记录写入的文件, 构建完成后检查站内链接
+	var recorder *linkcheck.Recorder
+	if site.ctx.Config.GetBool("strict") {
+		recorder = linkcheck.NewRecorder(w)
+		w = recorder
+	}
+
 	writer, err := site.hook.HandleWriter(w)
 	if err != nil {
 		return err
@@ -41,7 +97,13 @@ func (site *Site) Build(ctx context.Context, w core.Writer) error {
 	if err := site.BuildContent(ctx, writer); err != nil {
 		return err
 	}
-	return site.hook.AfterBuild(ctx, writer)
+	if err := site.hook.AfterBuild(ctx, writer); err != nil {
+		return err
+	}
+	if recorder != nil {
+		return site.checkLinks(recorder)
+	}
+	return nil
 }
 
 func IncludeDrafts(b bool) SiteOption {
@@ -50,9 +112,29 @@ func IncludeDrafts(b bool) SiteOption {
 	}
 }
 
+func IncludeFuture(b bool) SiteOption {
+	return func(site *Site) {
+		site.includeFuture = b
+	}
+}
+
+func IncludeExpired(b bool) SiteOption {
+	return func(site *Site) {
+		site.includeExpired = b
+	}
+}
+
+func UseCache(b bool) SiteOption {
+	return func(site *Site) {
+		site.useCache = b
+	}
+}
+
 func New(ctx *core.Context, opts ...SiteOption) (*Site, error) {
 	site := &Site{
-		ctx: ctx,
+		ctx:     ctx,
+		deps:    &Dependencies{},
+		results: &parsedResults{},
 	}
 	for _, opt := range opts {
 		opt(site)
@@ -63,6 +145,7 @@ func New(ctx *core.Context, opts ...SiteOption) (*Site, error) {
 		return nil, err
 	}
 	site.hook = h
+	site.hook.HandleDependencies(site.deps)
 
 	tplset, err := site.newTemplateSet()
 	if err != nil {
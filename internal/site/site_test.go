@@ -0,0 +1,316 @@
+package site
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/honmaple/snow/internal/core"
+	_ "github.com/honmaple/snow/internal/site/hook/encrypt"
+	_ "github.com/honmaple/snow/internal/site/hook/links"
+	_ "github.com/honmaple/snow/internal/site/hook/search"
+	_ "github.com/honmaple/snow/internal/site/hook/shortcode"
+	_ "github.com/honmaple/snow/internal/site/hook/sitemap"
+	"github.com/honmaple/snow/internal/writer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildStrict(t *testing.T) {
+	s, _ := newRebuildTestSite(t)
+
+	writeTestFile(t, "templates/page.html", "{% if page.Title %}")
+	writeTestFile(t, "content/posts/d.md", "---\ntitle: [\n---\nd\n")
+
+	tplset, err := s.newTemplateSet()
+	require.NoError(t, err)
+	s.tplset = tplset
+
+	// 非严格模式只输出错误日志
+	require.NoError(t, s.Build(context.TODO(), writer.NewMemoryWriter()))
+
+	s.ctx.Config.Set("strict", true)
+	err = s.Build(context.TODO(), writer.NewMemoryWriter())
+
+	var buildErr *core.BuildError
+	require.ErrorAs(t, err, &buildErr)
+
+	ops := make(map[string]string)
+	for _, e := range buildErr.Errors {
+		ops[e.Op] = e.Path
+	}
+	assert.Equal(t, "page.html", ops["parse tpl"])
+	assert.Equal(t, "posts/d.md", ops["parse content"])
+}
+
+func TestBuildIncludeErrors(t *testing.T) {
+	s, _ := newRebuildTestSite(t)
+
+	writeTestFile(t, "templates/page.html", "{{ page.Content|safe }}")
+	writeTestFile(t, "content/posts/d.org", "#+TITLE: D\n#+INCLUDE: \"/missing.go\" src go\n")
+
+	s.ctx.Config.Set("hooks.shortcode.enabled", true)
+	s, err := New(s.ctx)
+	require.NoError(t, err)
+
+	// 引用的文件不存在时非严格模式下也会构建失败
+	w := writer.NewMemoryWriter()
+	err = s.Build(context.TODO(), w)
+
+	var buildErr *core.BuildError
+	require.ErrorAs(t, err, &buildErr)
+	require.Len(t, buildErr.Errors, 1)
+	assert.Equal(t, "include code", buildErr.Errors[0].Op)
+	assert.Equal(t, "posts/d.org", buildErr.Errors[0].Path)
+
+	_, err = w.Open("/posts/d/index.html")
+	assert.Error(t, err)
+
+	// org-mode直接展开的#+INCLUDE同样会使构建失败
+	writeTestFile(t, "content/posts/d.org", "#+TITLE: D\n#+INCLUDE: \"_missing.org\"\n")
+
+	err = s.Build(context.TODO(), writer.NewMemoryWriter())
+	require.ErrorAs(t, err, &buildErr)
+	require.Len(t, buildErr.Errors, 1)
+	assert.Equal(t, "parse content", buildErr.Errors[0].Op)
+	assert.Equal(t, "posts/d.org", buildErr.Errors[0].Path)
+}
+
+func TestBuildStrictWarnings(t *testing.T) {
+	s, _ := newRebuildTestSite(t)
+
+	writeTestFile(t, "templates/alias.html", "{{ page.Path }}")
+	writeTestFile(t, "content/posts/a.md", "---\ntitle: A\naliases: [\"a//b\"]\n---\na\n")
+
+	s.ctx.Config.Set("strict", true)
+	require.NoError(t, s.Build(context.TODO(), writer.NewMemoryWriter()))
+	assert.Len(t, s.ctx.Reporter.Warnings(), 1)
+
+	s.ctx.Config.Set("strict_warnings", true)
+	err := s.Build(context.TODO(), writer.NewMemoryWriter())
+
+	var buildErr *core.BuildError
+	require.ErrorAs(t, err, &buildErr)
+	assert.Equal(t, "render alias", buildErr.Errors[0].Op)
+}
+
+func TestCheckContentSchema(t *testing.T) {
+	s, _ := newRebuildTestSite(t)
+
+	for _, name := range []string{"encrypt", "search", "sitemap"} {
+		s.ctx.Config.Set("hooks."+name+".enabled", true)
+	}
+	s.ctx.Config.Set("taxonomies.tags.weight", 1)
+	s.ctx.Config.Set("front_matter.publish_date", []string{"publish_date", "pubdate"})
+	s.ctx.Config.Set("schemas.posts", map[string]any{
+		"unknown": "error",
+		"fields": map[string]any{
+			"status": map[string]any{"type": "enum", "values": []any{"draft", "published"}},
+		},
+		"section": map[string]any{"unknown": "error"},
+	})
+	writeTestFile(t, "content/posts/a.md", "---\ntitle: A\nsitemap: false\nsearch: false\npassword: x\nauthors: [snow]\ntags: [go]\npubdate: 2024-01-01\n---\na\n")
+	writeTestFile(t, "content/posts/b.md", "+++\ntitle = \"B\"\ndate = 2024-01-02\nstatus = \"archived\"\n+++\nb\n")
+	writeTestFile(t, "content/posts/_index.md", "---\ntitle: Posts\nextra: 1\n---\n")
+
+	s.ctx.Config.Set("strict", true)
+	s, err := New(s.ctx)
+	require.NoError(t, err)
+
+	err = s.CheckContent()
+
+	var buildErr *core.BuildError
+	require.ErrorAs(t, err, &buildErr)
+	require.Len(t, buildErr.Errors, 2)
+
+	errs := make(map[string]string)
+	for _, e := range buildErr.Errors {
+		errs[e.Path] = e.Err.Error()
+	}
+	assert.Equal(t, "unknown keys: extra", errs["posts/_index.md"])
+	assert.Equal(t, "status: expected one of [draft published], got archived", errs["posts/b.md"])
+
+	// 检查失败的page和section仍然会被解析
+	_, store, err := s.parseContent()
+	require.NoError(t, err)
+	assert.NotNil(t, store.GetPage("posts/b.md", "en"))
+	assert.NotNil(t, store.GetSection("posts", "en").File)
+}
+
+func TestBuildSkipsFutureAndExpired(t *testing.T) {
+	s, _ := newRebuildTestSite(t)
+
+	writeTestFile(t, "content/posts/future.md", "---\ntitle: Future\npublish_date: 2999-01-01\n---\n")
+	writeTestFile(t, "content/posts/expired.md", "---\ntitle: Expired\nexpiry_date: 2000-01-01\n---\n")
+	writeTestFile(t, "content/posts/custom.md", "---\ntitle: Custom\nunpublish: 2000-01-01\n---\n")
+
+	titles := func() []string {
+		_, store, err := s.parseContent()
+		require.NoError(t, err)
+
+		results := make([]string, 0)
+		for _, page := range store.Pages(s.ctx.GetDefaultLanguage()) {
+			results = append(results, page.Title)
+		}
+		return results
+	}
+	assert.ElementsMatch(t, []string{"A", "B", "C", "Custom"}, titles())
+
+	s.ctx.Config.Set("front_matter.expiry_date", []string{"expiry_date", "unpublish"})
+	assert.ElementsMatch(t, []string{"A", "B", "C"}, titles())
+
+	s.includeFuture = true
+	s.includeExpired = true
+	assert.ElementsMatch(t, []string{"A", "B", "C", "Future", "Expired", "Custom"}, titles())
+}
+
+func TestLinkTranslations(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	writeTestFile(t, "content/posts/_index.md", "---\ntitle: Posts\n---\n")
+	writeTestFile(t, "content/posts/_index.fr.md", "---\ntitle: Articles\n---\n")
+	writeTestFile(t, "content/posts/a.md", "---\ntitle: A\n---\n")
+	writeTestFile(t, "content/posts/a.fr.md", "---\ntitle: A fr\n---\n")
+	writeTestFile(t, "content/posts/b.md", "---\ntitle: B\n---\n")
+	writeTestFile(t, "content/posts/c.md", "---\ntitle: C\ntranslation_key: c\n---\n")
+	writeTestFile(t, "content/posts/other.de.md", "---\ntitle: C de\ntranslation_key: c\n---\n")
+
+	conf := core.DefaultConfig()
+	conf.Set("languages.fr", map[string]any{})
+	conf.Set("languages.de", map[string]any{})
+	for _, name := range []string{"assets", "encrypt", "links", "shortcode"} {
+		conf.Set("hooks."+name+".enabled", false)
+	}
+	ctx, err := core.NewContext(conf)
+	require.NoError(t, err)
+
+	s, err := New(ctx)
+	require.NoError(t, err)
+
+	_, store, err := s.parseContent()
+	require.NoError(t, err)
+
+	a := store.GetPage("posts/a.md", "en")
+	require.NotNil(t, a)
+	assert.Len(t, a.Translations, 1)
+	assert.Equal(t, "A fr", a.Translations["fr"].Title)
+	assert.Equal(t, "A", store.GetPage("posts/a.fr.md", "fr").Translations["en"].Title)
+
+	assert.Empty(t, store.GetPage("posts/b.md", "en").Translations)
+	assert.Equal(t, "C de", store.GetPage("posts/c.md", "en").Translations["de"].Title)
+
+	section := store.GetSection("posts", "fr")
+	require.NotNil(t, section)
+	assert.Equal(t, "Posts", section.Translations["en"].Title)
+	assert.NotContains(t, section.Translations, "de")
+
+	ctx.Config.Set("translation_fallback", "default")
+	_, store, err = s.parseContent()
+	require.NoError(t, err)
+
+	fr := store.GetPage("posts/a.fr.md", "fr")
+	assert.Equal(t, "A", fr.Translations["de"].Title)
+	assert.Equal(t, "A", fr.Translations["en"].Title)
+	assert.Empty(t, store.GetPage("posts/b.md", "en").Translations)
+}
+
+func TestBuildOutputCollisions(t *testing.T) {
+	s, _ := newRebuildTestSite(t)
+
+	writeTestFile(t, "content/posts/d.md", "---\ntitle: D\nslug: a\n---\nd\n")
+	writeTestFile(t, "content/posts/e.md", "---\ntitle: E\naliases: [\"/posts/b/index.html\"]\n---\ne\n")
+	writeTestFile(t, "static/posts/c/index.html", "c")
+
+	err := s.BuildContent(context.TODO(), writer.NewMemoryWriter())
+
+	var buildErr *core.BuildError
+	require.ErrorAs(t, err, &buildErr)
+	require.Len(t, buildErr.Errors, 3)
+	assert.Equal(t, "/posts/a/index.html", buildErr.Errors[0].Path)
+	assert.EqualError(t, buildErr.Errors[0].Err, "written by page posts/a.md, page posts/d.md")
+	assert.Equal(t, "/posts/b/index.html", buildErr.Errors[1].Path)
+	assert.EqualError(t, buildErr.Errors[1].Err, "written by alias posts/e.md, page posts/b.md")
+	assert.Equal(t, "/posts/c/index.html", buildErr.Errors[2].Path)
+	assert.EqualError(t, buildErr.Errors[2].Err, "written by page posts/c.md, static posts/c/index.html")
+
+	// 降级为警告时继续渲染
+	s.ctx.Config.Set("output_collision", "warn")
+	require.NoError(t, s.BuildContent(context.TODO(), writer.NewMemoryWriter()))
+	assert.Len(t, s.ctx.Reporter.Warnings(), 3)
+}
+
+func TestBuildStrictLinks(t *testing.T) {
+	s, _ := newRebuildTestSite(t)
+
+	writeTestFile(t, "templates/page.html", "{{ page.Content|safe }}")
+	writeTestFile(t, "content/posts/a.md", "---\ntitle: A\n---\n## Usage\n\n[b](/posts/b/) [missing](/posts/missing/) [anchor](/posts/b/#usage)\n")
+	writeTestFile(t, "content/posts/b.md", "---\ntitle: B\n---\n## Usage\n")
+
+	tplset, err := s.newTemplateSet()
+	require.NoError(t, err)
+	s.tplset = tplset
+
+	s.ctx.Config.Set("strict", true)
+	err = s.Build(context.TODO(), writer.NewMemoryWriter())
+
+	var buildErr *core.BuildError
+	require.ErrorAs(t, err, &buildErr)
+	require.Len(t, buildErr.Errors, 1)
+	assert.Equal(t, "check links", buildErr.Errors[0].Op)
+	assert.Equal(t, "posts/a.md", buildErr.Errors[0].Path)
+	assert.EqualError(t, buildErr.Errors[0].Err, "/posts/missing/: target not found")
+}
+
+func TestGraphWriteDOT(t *testing.T) {
+	g := &Graph{
+		Nodes: []*GraphNode{
+			{ID: "posts/_index.md", Kind: "section", Title: "Posts"},
+			{ID: "posts/a.md", Kind: "page", Title: `A "1"`},
+		},
+		Edges: []*GraphEdge{
+			{Source: "posts/_index.md", Target: "posts/a.md"},
+		},
+	}
+
+	var b strings.Builder
+	require.NoError(t, g.WriteDOT(&b))
+	assert.Equal(t, `digraph snow {
+  "posts/_index.md" [label="Posts", shape=box];
+  "posts/a.md" [label="A \"1\"", shape=ellipse];
+  "posts/_index.md" -> "posts/a.md";
+}
+`, b.String())
+}
+
+func TestBuildMarkdownRenderHooks(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	writeTestFile(t, "content/posts/a.md", "---\ntitle: A\n---\n## Usage\n\n[b](b.md)\n\n```mermaid\ngraph TD\n```\n")
+	writeTestFile(t, "content/posts/b.md", "---\ntitle: B\n---\nb\n")
+	writeTestFile(t, "templates/page.html", "{{ page.Content|safe }}")
+	writeTestFile(t, "templates/_markup/render-link.html", `<a href="{{ destination }}" data-page="{{ page.Title }}">{{ text|safe }}</a>`)
+	writeTestFile(t, "templates/_markup/render-heading.html", `<h{{ level }} id="{{ anchor }}">#{{ plain_text }}</h{{ level }}>`)
+	writeTestFile(t, "templates/_markup/render-codeblock-mermaid.html", `<div class="mermaid">{{ inner }}</div>`)
+
+	conf := core.DefaultConfig()
+	conf.Set("hooks.assets.enabled", false)
+	conf.Set("hooks.encrypt.enabled", false)
+	ctx, err := core.NewContext(conf)
+	require.NoError(t, err)
+	s, err := New(ctx)
+	require.NoError(t, err)
+
+	w := writer.NewMemoryWriter()
+	require.NoError(t, s.Build(context.TODO(), w))
+
+	f, err := w.Open("/posts/a/index.html")
+	require.NoError(t, err)
+	defer f.Close()
+	buf, err := io.ReadAll(f)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(buf), `<h2 id="usage">#Usage</h2>`)
+	assert.Contains(t, string(buf), `<a href="/posts/b/" data-page="A">b</a>`)
+	assert.Contains(t, string(buf), `<div class="mermaid">graph TD`)
+}
@@ -19,6 +19,7 @@ func (site *Site) IsIgnoredStatic(path string, isDir bool) bool {
 		matched, err := doublestar.Match(pattern, matchPath)
 		if err != nil {
 			site.ctx.Logger.Warnf("The pattern %s match %s err: %s", pattern, path, err)
+			site.ctx.Reporter.Warn(&core.Error{Op: "match ignored_static", Err: err, Path: pattern})
 			continue
 		}
 		if matched {
@@ -28,16 +29,13 @@ func (site *Site) IsIgnoredStatic(path string, isDir bool) bool {
 	return false
 }
 
-func (site *Site) BuildStatic(ctx context.Context, writer core.Writer) error {
-	site.ctx.Logger.Infof("Copying static...")
-
-	now := time.Now()
-
+// walkStatic 遍历需要复制的静态文件, 忽略ignored_static中的文件
+func (site *Site) walkStatic(fn func(fs.FS, string) error) error {
 	staticFS, err := site.ctx.GetFS(core.MountStatic, true, true)
 	if err != nil {
 		return err
 	}
-	if err := fs.WalkDir(staticFS, ".", func(path string, info fs.DirEntry, err error) error {
+	return fs.WalkDir(staticFS, ".", func(path string, info fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -51,6 +49,16 @@ func (site *Site) BuildStatic(ctx context.Context, writer core.Writer) error {
 		if info.IsDir() {
 			return nil
 		}
+		return fn(staticFS, path)
+	})
+}
+
+func (site *Site) BuildStatic(ctx context.Context, writer core.Writer) error {
+	site.ctx.Logger.Infof("Copying static...")
+
+	now := time.Now()
+
+	if err := site.walkStatic(func(staticFS fs.FS, path string) error {
 		src, err := staticFS.Open(path)
 		if err != nil {
 			return err
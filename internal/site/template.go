@@ -7,28 +7,105 @@ import (
 
 type ContentTemplate struct {
 	lang  string
+	deps  *Dependencies
 	store *ContentStore
 	template.Template
 }
 
 func (t *ContentTemplate) Execute(vars map[string]any) (string, error) {
+	key := ""
+	if t.deps != nil {
+		if path, ok := vars["current_path"].(string); ok {
+			key = dependencyKeyFromVars(vars)
+			t.deps.Record(key, dependencyOutput(path), t.Name())
+		}
+	}
+	// 记录模版读取的内容列表和内容, 只有对应的内容修改后才需要重新渲染
+	collection := func(name string, args []string) {
+		if t.deps != nil {
+			t.deps.RecordCollection(key, collectionKey(name, t.getLang(args)))
+		}
+	}
+	source := func(files ...string) {
+		if t.deps != nil {
+			t.deps.RecordSource(key, files...)
+		}
+	}
 	commonVars := map[string]any{
-		"pages":                 t.GetPages(),
-		"hidden_pages":          t.GetHiddenPages(),
-		"sections":              t.GetSections(),
-		"taxonomies":            t.GetTaxonomies(),
-		"get_pages":             t.GetPages,
-		"get_hidden_pages":      t.GetHiddenPages,
-		"get_sections":          t.GetSections,
-		"get_taxonomies":        t.GetTaxonomies,
-		"get_page":              t.GetPage,
-		"get_page_url":          t.GetPageURL,
-		"get_section":           t.GetSection,
-		"get_section_url":       t.GetSectionURL,
-		"get_taxonomy":          t.GetTaxonomy,
-		"get_taxonomy_url":      t.GetTaxonomyURL,
-		"get_taxonomy_term":     t.GetTaxonomyTerm,
-		"get_taxonomy_term_url": t.GetTaxonomyTermURL,
+		"pages":        func() content.Pages { collection("pages", nil); return t.GetPages() },
+		"hidden_pages": func() content.Pages { collection("hidden_pages", nil); return t.GetHiddenPages() },
+		"sections":     func() content.Sections { collection("sections", nil); return t.GetSections() },
+		"taxonomies":   func() content.Taxonomies { collection("taxonomies", nil); return t.GetTaxonomies() },
+		"get_pages": func(args ...string) content.Pages {
+			collection("pages", args)
+			return t.GetPages(args...)
+		},
+		"get_hidden_pages": func(args ...string) content.Pages {
+			collection("hidden_pages", args)
+			return t.GetHiddenPages(args...)
+		},
+		"get_sections": func(args ...string) content.Sections {
+			collection("sections", args)
+			return t.GetSections(args...)
+		},
+		"get_taxonomies": func(args ...string) content.Taxonomies {
+			collection("taxonomies", args)
+			return t.GetTaxonomies(args...)
+		},
+		"get_page": func(path string, args ...string) *content.Page {
+			page := t.GetPage(path, args...)
+			if page == nil {
+				// 不存在的page创建后需要重新渲染
+				collection("pages", args)
+			} else {
+				source(page.File.Path)
+			}
+			return page
+		},
+		"get_page_url": func(path string, args ...string) string {
+			page := t.GetPage(path, args...)
+			if page == nil {
+				collection("pages", args)
+				return ""
+			}
+			source(page.File.Path)
+			return page.Permalink
+		},
+		"get_section": func(path string, args ...string) *content.Section {
+			section := t.GetSection(path, args...)
+			if section == nil {
+				collection("sections", args)
+			} else {
+				// section中的pages等内容
+				source(dependencyFiles(section)...)
+			}
+			return section
+		},
+		"get_section_url": func(path string, args ...string) string {
+			section := t.GetSection(path, args...)
+			if section == nil {
+				collection("sections", args)
+				return ""
+			}
+			source(section.File.Path)
+			return section.Permalink
+		},
+		"get_taxonomy": func(name string, args ...string) *content.Taxonomy {
+			collection("taxonomies", args)
+			return t.GetTaxonomy(name, args...)
+		},
+		"get_taxonomy_url": func(name string, args ...string) string {
+			collection("taxonomies", args)
+			return t.GetTaxonomyURL(name, args...)
+		},
+		"get_taxonomy_term": func(taxonomyName string, name string, args ...string) *content.TaxonomyTerm {
+			collection("taxonomies", args)
+			return t.GetTaxonomyTerm(taxonomyName, name, args...)
+		},
+		"get_taxonomy_term_url": func(taxonomyName string, name string, args ...string) string {
+			collection("taxonomies", args)
+			return t.GetTaxonomyTermURL(taxonomyName, name, args...)
+		},
 	}
 	for k, v := range commonVars {
 		if _, ok := vars[k]; !ok {
@@ -38,6 +115,13 @@ func (t *ContentTemplate) Execute(vars map[string]any) (string, error) {
 	return t.Template.Execute(vars)
 }
 
+func (t *ContentTemplate) getLang(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return t.lang
+}
+
 func (t *ContentTemplate) GetSections(args ...string) content.Sections {
 	lang := t.lang
 	if len(args) > 0 {
@@ -136,12 +220,13 @@ func (t *ContentTemplate) GetTaxonomyTermURL(taxonomyName string, name string, a
 
 type ContentTemplateSet struct {
 	lang  string
+	deps  *Dependencies
 	store *ContentStore
 	template.TemplateSet
 }
 
 func (set *ContentTemplateSet) newTemplate(tpl template.Template) template.Template {
-	return &ContentTemplate{Template: tpl, lang: set.lang, store: set.store}
+	return &ContentTemplate{Template: tpl, lang: set.lang, deps: set.deps, store: set.store}
 }
 
 func (set *ContentTemplateSet) Lookup(names ...string) template.Template {
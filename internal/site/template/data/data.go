@@ -93,7 +93,8 @@ func (d *Data) Load(path string, format string) any {
 		result, err = d.loadFromFile(path, format)
 	}
 	if err != nil {
-		d.ctx.Logger.Warn("load data %s err: %s", path, err.Error())
+		d.ctx.Logger.Warnf("load data %s err: %s", path, err.Error())
+		d.ctx.Reporter.Warn(&core.Error{Op: "load data", Err: err, Path: path})
 		return nil
 	}
 	return result
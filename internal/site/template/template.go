@@ -74,6 +74,7 @@ func (set *templateSet) Lookup(names ...string) Template {
 		template, err := set.fromFile(name)
 		if err != nil {
 			set.ctx.Logger.Warnf("parse template %s err: %s", name, err.Error())
+			set.ctx.Reporter.Error(&core.Error{Op: "parse tpl", Err: err, Path: name})
 			continue
 		}
 		if template == nil {
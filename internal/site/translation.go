@@ -0,0 +1,65 @@
+package site
+
+import (
+	"github.com/honmaple/snow/internal/site/content"
+)
+
+// linkTranslations 按照TranslationKey关联不同语言的page和section,
+// translation_fallback为default时缺失的语言使用默认语言的版本
+func (site *Site) linkTranslations(store *ContentStore) {
+	langs := site.ctx.GetAllLanguages()
+	defaultLang := site.ctx.GetDefaultLanguage()
+	fallback := site.ctx.Config.GetString("translation_fallback") == "default"
+
+	pages := make(map[string]map[string]*content.Page)
+	for _, lang := range langs {
+		for _, list := range []content.Pages{store.Pages(lang), store.HiddenPages(lang)} {
+			for _, page := range list {
+				if _, ok := pages[page.TranslationKey]; !ok {
+					pages[page.TranslationKey] = make(map[string]*content.Page)
+				}
+				pages[page.TranslationKey][page.Lang] = page
+			}
+		}
+	}
+	for _, group := range pages {
+		for lang, page := range group {
+			page.Translations = make(map[string]*content.Page)
+			for _, other := range langs {
+				if other == lang {
+					continue
+				}
+				if result, ok := group[other]; ok {
+					page.Translations[other] = result
+				} else if result, ok := group[defaultLang]; ok && fallback && lang != defaultLang {
+					page.Translations[other] = result
+				}
+			}
+		}
+	}
+
+	sections := make(map[string]map[string]*content.Section)
+	for _, lang := range langs {
+		for _, section := range store.Sections(lang) {
+			if _, ok := sections[section.TranslationKey]; !ok {
+				sections[section.TranslationKey] = make(map[string]*content.Section)
+			}
+			sections[section.TranslationKey][section.Lang] = section
+		}
+	}
+	for _, group := range sections {
+		for lang, section := range group {
+			section.Translations = make(map[string]*content.Section)
+			for _, other := range langs {
+				if other == lang {
+					continue
+				}
+				if result, ok := group[other]; ok {
+					section.Translations[other] = result
+				} else if result, ok := group[defaultLang]; ok && fallback && lang != defaultLang {
+					section.Translations[other] = result
+				}
+			}
+		}
+	}
+}
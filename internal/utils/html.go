@@ -1,5 +1,11 @@
 package utils
 
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
 func IsHTMLVoidElement(tag string) bool {
 	switch tag {
 	case "area", "base", "br", "col", "embed", "hr", "img", "input", "link", "meta", "param", "source", "track", "wbr":
@@ -7,3 +13,48 @@ func IsHTMLVoidElement(tag string) bool {
 	}
 	return false
 }
+
+func isHTMLInlineElement(tag string) bool {
+	switch tag {
+	case "a", "abbr", "b", "bdi", "bdo", "cite", "code", "data", "del", "dfn", "em", "i", "ins", "kbd", "mark", "q", "s", "samp", "small", "span", "strong", "sub", "sup", "time", "u", "var":
+		return true
+	}
+	return false
+}
+
+// PlainText 提取HTML中的文本, 忽略script和style, 块级元素之间使用空格分隔, 连续的空白合并为一个空格
+func PlainText(content string) string {
+	var (
+		b    strings.Builder
+		skip string
+	)
+
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tt := tokenizer.Next()
+		switch tt {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(b.String()), " ")
+		case html.TextToken:
+			if skip == "" {
+				b.Write(tokenizer.Text())
+			}
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+			if skip != "" {
+				if tt == html.EndTagToken && tag == skip {
+					skip = ""
+				}
+				continue
+			}
+			if tt == html.StartTagToken && (tag == "script" || tag == "style") {
+				skip = tag
+				continue
+			}
+			if !isHTMLInlineElement(tag) {
+				b.WriteString(" ")
+			}
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlainText(t *testing.T) {
+	assert.Equal(t, "Hello World", PlainText(`Hello <em>World</em>`))
+	assert.Equal(t, "foobar", PlainText(`foo<code>bar</code>`))
+	assert.Equal(t, "Tom & Jerry next", PlainText(`<p>Tom &amp; Jerry</p><p>next</p>`))
+	assert.Equal(t, "a b", PlainText("<p>a<br>b</p><script>var x</script><style>p {}</style>"))
+}
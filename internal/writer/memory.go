@@ -27,6 +27,13 @@ func (m *MemoryWriter) Reset() {
 	})
 }
 
+func (m *MemoryWriter) Remove(file string) error {
+	if !strings.HasPrefix(file, "/") {
+		file = "/" + file
+	}
+	return m.fs.Remove(file)
+}
+
 func (m *MemoryWriter) Open(file string) (fs.File, error) {
 	return m.fs.Open(file)
 }